@@ -19,6 +19,7 @@ package abi
 import (
 	"bytes"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"log"
 	"math/big"
@@ -56,7 +57,7 @@ const jsondata2 = `
 ]`
 
 func TestReader(t *testing.T) {
-	Uint256, _ := NewType("uint256")
+	Uint256, _ := NewType("uint256", nil)
 	exp := ABI{
 		Methods: map[string]Method{
 			"balance": {
@@ -177,7 +178,7 @@ func TestTestSlice(t *testing.T) {
 }
 
 func TestMethodSignature(t *testing.T) {
-	String, _ := NewType("string")
+	String, _ := NewType("string", nil)
 	m := Method{"foo", false, []Argument{{"bar", String, false}, {"baz", String, false}}, nil}
 	exp := "foo(string,string)"
 	if m.Sig() != exp {
@@ -189,7 +190,7 @@ func TestMethodSignature(t *testing.T) {
 		t.Errorf("expected ids to match %x != %x", m.Id(), idexp)
 	}
 
-	uintt, _ := NewType("uint256")
+	uintt, _ := NewType("uint256", nil)
 	m = Method{"foo", false, []Argument{{"bar", uintt, false}}, nil}
 	exp = "foo(uint256)"
 	if m.Sig() != exp {
@@ -567,8 +568,8 @@ func TestBareEvents(t *testing.T) {
 	{ "type" : "event", "name" : "args", "inputs" : [{ "indexed":false, "name":"arg0", "type":"uint256" }, { "indexed":true, "name":"arg1", "type":"address" }] }
 	]`
 
-	arg0, _ := NewType("uint256")
-	arg1, _ := NewType("address")
+	arg0, _ := NewType("uint256", nil)
+	arg1, _ := NewType("address", nil)
 
 	expectedEvents := map[string]struct {
 		Anonymous bool
@@ -696,3 +697,157 @@ func TestABI_MethodById(t *testing.T) {
 	}
 
 }
+
+func TestUnpackRevert(t *testing.T) {
+	t.Parallel()
+
+	var cases = []struct {
+		input     string
+		expect    string
+		expectErr error
+	}{
+		{"", "", errors.New("invalid data for unpacking")},
+		{"08c379a1", "", errors.New("invalid data for unpacking")},
+		{"08c379a00000000000000000000000000000000000000000000000000000000000000020000000000000000000000000000000000000000000000000000000000000000d72657665727420726561736f6e00000000000000000000000000000000000000", "revert reason", nil},
+	}
+	for index, c := range cases {
+		t.Run(fmt.Sprintf("case %d", index), func(t *testing.T) {
+			got, err := UnpackRevert(common.Hex2Bytes(c.input))
+			if c.expectErr != nil {
+				if err == nil {
+					t.Fatalf("Expected non-nil error")
+				}
+				if err.Error() != c.expectErr.Error() {
+					t.Fatalf("Expected error mismatch, want %v, got %v", c.expectErr, err)
+				}
+				return
+			}
+			if c.expect != got {
+				t.Fatalf("Output mismatch, want %v, got %v", c.expect, got)
+			}
+		})
+	}
+}
+
+const tupleJSON = `[
+	{ "type": "function", "name": "static", "inputs": [ { "name": "s", "type": "tuple", "components": [ { "name": "a", "type": "uint256" }, { "name": "b", "type": "bool" } ] }, { "name": "c", "type": "uint256" } ] },
+	{ "type": "function", "name": "dynamic", "inputs": [ { "name": "s", "type": "tuple", "components": [ { "name": "a", "type": "uint256" }, { "name": "b", "type": "string" } ] }, { "name": "c", "type": "uint256" } ] },
+	{ "type": "function", "name": "list", "inputs": [ { "name": "s", "type": "tuple[]", "components": [ { "name": "text", "type": "string" } ] } ] }
+]`
+
+// Tests that the signatures and selectors of methods taking multi-dimensional
+// tuple arrays expand the tuples.
+func TestTupleArraySelector(t *testing.T) {
+	const definition = `[
+	{ "type": "function", "name": "slices", "inputs": [ { "name": "s", "type": "tuple[][]", "components": [ { "name": "a", "type": "uint256" }, { "name": "b", "type": "bool" } ] } ] },
+	{ "type": "function", "name": "mixed", "inputs": [ { "name": "s", "type": "tuple[2][]", "components": [ { "name": "a", "type": "address" } ] } ] },
+	{ "type": "function", "name": "nested", "inputs": [ { "name": "s", "type": "tuple[][3]", "components": [ { "name": "a", "type": "tuple[]", "components": [ { "name": "b", "type": "bytes32" } ] } ] } ] }
+]`
+	abi, err := JSON(strings.NewReader(definition))
+	if err != nil {
+		t.Fatalf("failed to parse ABI: %v", err)
+	}
+	for name, want := range map[string]string{
+		"slices": "slices((uint256,bool)[][])",
+		"mixed":  "mixed((address)[2][])",
+		"nested": "nested(((bytes32)[])[][3])",
+	} {
+		method := abi.Methods[name]
+		if sig := method.Sig(); sig != want {
+			t.Errorf("%s: signature mismatch: have %s, want %s", name, sig, want)
+		}
+		if id := method.Id(); !bytes.Equal(id, crypto.Keccak256([]byte(want))[:4]) {
+			t.Errorf("%s: selector mismatch: have %x, want %x", name, id, crypto.Keccak256([]byte(want))[:4])
+		}
+	}
+}
+
+// Tests that tuples are packed and unpacked according to the ABI v2 encoding.
+func TestPackUnpackTuple(t *testing.T) {
+	abi, err := JSON(strings.NewReader(tupleJSON))
+	if err != nil {
+		t.Fatalf("failed to parse ABI: %v", err)
+	}
+	word := func(n int) string { return common.Bytes2Hex(common.LeftPadBytes(big.NewInt(int64(n)).Bytes(), 32)) }
+	text := func(s string) string { return common.Bytes2Hex(common.RightPadBytes([]byte(s), 32)) }
+
+	type staticTuple struct {
+		A *big.Int
+		B bool
+	}
+	type dynamicTuple struct {
+		A *big.Int
+		B string
+	}
+	type textTuple struct {
+		Text string
+	}
+	tests := []struct {
+		method string
+		sig    string
+		args   []interface{}
+		enc    string
+		out    interface{} // pointer to the struct to unpack the inputs into
+	}{
+		{
+			method: "static",
+			sig:    "static((uint256,bool),uint256)",
+			args:   []interface{}{staticTuple{big.NewInt(1), true}, big.NewInt(2)},
+			enc:    word(1) + word(1) + word(2),
+			out: &struct {
+				S staticTuple
+				C *big.Int
+			}{},
+		},
+		{
+			method: "dynamic",
+			sig:    "dynamic((uint256,string),uint256)",
+			args:   []interface{}{dynamicTuple{big.NewInt(1), "hello"}, big.NewInt(2)},
+			enc:    word(0x40) + word(2) + word(1) + word(0x40) + word(5) + text("hello"),
+			out: &struct {
+				S dynamicTuple
+				C *big.Int
+			}{},
+		},
+		{
+			method: "list",
+			sig:    "list((string)[])",
+			args:   []interface{}{[]textTuple{{"foo"}, {"bar"}}},
+			enc: word(0x20) + word(2) + word(0x40) + word(0xa0) +
+				word(0x20) + word(3) + text("foo") +
+				word(0x20) + word(3) + text("bar"),
+			out: new([]textTuple),
+		},
+	}
+	for _, tt := range tests {
+		method := abi.Methods[tt.method]
+		if sig := method.Sig(); sig != tt.sig {
+			t.Errorf("%s: signature mismatch: have %s, want %s", tt.method, sig, tt.sig)
+		}
+		packed, err := abi.Pack(tt.method, tt.args...)
+		if err != nil {
+			t.Errorf("%s: failed to pack: %v", tt.method, err)
+			continue
+		}
+		if enc := common.Bytes2Hex(packed[4:]); enc != tt.enc {
+			t.Errorf("%s: encoding mismatch:\nhave %s\nwant %s", tt.method, enc, tt.enc)
+			continue
+		}
+		if err := method.Inputs.Unpack(tt.out, packed[4:]); err != nil {
+			t.Errorf("%s: failed to unpack: %v", tt.method, err)
+			continue
+		}
+		var have []interface{}
+		if len(tt.args) == 1 {
+			have = []interface{}{reflect.ValueOf(tt.out).Elem().Interface()}
+		} else {
+			value := reflect.ValueOf(tt.out).Elem()
+			for i := 0; i < value.NumField(); i++ {
+				have = append(have, value.Field(i).Interface())
+			}
+		}
+		if !reflect.DeepEqual(have, tt.args) {
+			t.Errorf("%s: unpacked value mismatch: have %v, want %v", tt.method, have, tt.args)
+		}
+	}
+}
@@ -33,19 +33,24 @@ type Argument struct {
 
 type Arguments []Argument
 
+// ArgumentMarshaling is the JSON representation of an argument, with the fields
+// of tuple types described by its components.
+type ArgumentMarshaling struct {
+	Name       string
+	Type       string
+	Components []ArgumentMarshaling
+	Indexed    bool
+}
+
 // UnmarshalJSON implements json.Unmarshaler interface
 func (argument *Argument) UnmarshalJSON(data []byte) error {
-	var extarg struct {
-		Name    string
-		Type    string
-		Indexed bool
-	}
+	var extarg ArgumentMarshaling
 	err := json.Unmarshal(data, &extarg)
 	if err != nil {
 		return fmt.Errorf("argument json err: %v", err)
 	}
 
-	argument.Type, err = NewType(extarg.Type)
+	argument.Type, err = NewType(extarg.Type, extarg.Components)
 	if err != nil {
 		return err
 	}
@@ -127,10 +132,10 @@ func (arguments Arguments) unpackTuple(v interface{}, output []byte) error {
 			return err
 		}
 
-		if arg.Type.T == ArrayTy {
-			// combined index ('i' + 'j') need to be adjusted only by size of array, thus
-			// we need to decrement 'j' because 'i' was incremented
-			j += arg.Type.Size - 1
+		if (arg.Type.T == ArrayTy || arg.Type.T == TupleTy) && !isDynamicType(arg.Type) {
+			// combined index ('i' + 'j') need to be adjusted only by size of the static
+			// array or tuple, thus we need to decrement 'j' because 'i' was incremented
+			j += getTypeSize(arg.Type)/32 - 1
 		}
 
 		reflectValue := reflect.ValueOf(marshalledValue)
@@ -165,6 +170,33 @@ func (arguments Arguments) unpackTuple(v interface{}, output []byte) error {
 	return nil
 }
 
+// UnpackValues unpacks the hexdata according to the non-indexed arguments without
+// requiring a destination, returning the decoded values in argument order.
+func (arguments Arguments) UnpackValues(data []byte) ([]interface{}, error) {
+	var (
+		values = make([]interface{}, 0, arguments.LengthNonIndexed())
+		offset = 0
+	)
+	for _, arg := range arguments {
+		if arg.Indexed {
+			continue
+		}
+		value, err := toGoType(offset, arg.Type, data)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		// Static arrays and tuples are encoded inline, dynamic ones by offset
+		if (arg.Type.T == ArrayTy || arg.Type.T == TupleTy) && !isDynamicType(arg.Type) {
+			offset += getTypeSize(arg.Type)
+		} else {
+			offset += 32
+		}
+	}
+	return values, nil
+}
+
 // unpackAtomic unpacks ( hexdata -> go ) a single value
 func (arguments Arguments) unpackAtomic(v interface{}, output []byte) error {
 	// make sure the passed value is arguments pointer
@@ -201,11 +233,7 @@ func (arguments Arguments) Pack(args ...interface{}) ([]byte, error) {
 	// input offset is the bytes offset for packed output
 	inputOffset := 0
 	for _, abiArg := range abiArgs {
-		if abiArg.Type.T == ArrayTy {
-			inputOffset += 32 * abiArg.Type.Size
-		} else {
-			inputOffset += 32
-		}
+		inputOffset += getTypeSize(abiArg.Type)
 	}
 
 	var ret []byte
@@ -217,8 +245,8 @@ func (arguments Arguments) Pack(args ...interface{}) ([]byte, error) {
 			return nil, err
 		}
 
-		// check for a slice type (string, bytes, slice)
-		if input.Type.requiresLengthPrefix() {
+		// check for a dynamic type (string, bytes, slice or containing them)
+		if isDynamicType(input.Type) {
 			// calculate the offset
 			offset := inputOffset + len(variableInput)
 			// set the offset
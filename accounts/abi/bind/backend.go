@@ -110,3 +110,10 @@ type ContractBackend interface {
 	ContractTransactor
 	ContractFilterer
 }
+
+// ContractDeployBackend defines the methods needed to deploy a contract and to
+// wait until the deployment is mined.
+type ContractDeployBackend interface {
+	ContractBackend
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
@@ -44,6 +44,9 @@ import (
 // This nil assignment ensures compile time that SimulatedBackend implements bind.ContractBackend.
 var _ bind.ContractBackend = (*SimulatedBackend)(nil)
 
+// This nil assignment ensures compile time that SimulatedBackend implements ethereum.OverrideContractCaller.
+var _ ethereum.OverrideContractCaller = (*SimulatedBackend)(nil)
+
 var errBlockNumberUnsupported = errors.New("SimulatedBackend cannot access blocks other than the latest block")
 var errGasEstimationFailed = errors.New("gas required exceeds allowance or always failing transaction")
 
@@ -187,6 +190,26 @@ func (b *SimulatedBackend) CallContract(ctx context.Context, call ethereum.CallM
 	return rval, err
 }
 
+// CallContractWithOverride executes a contract call like CallContract, with the
+// given accounts replaced in a copy of the state.
+func (b *SimulatedBackend) CallContractWithOverride(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int, overrides ethereum.StateOverride) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if blockNumber != nil && blockNumber.Cmp(b.blockchain.CurrentBlock().Number()) != 0 {
+		return nil, errBlockNumberUnsupported
+	}
+	state, err := b.blockchain.State()
+	if err != nil {
+		return nil, err
+	}
+	if err := core.ApplyStateOverride(state, overrides); err != nil {
+		return nil, err
+	}
+	rval, _, _, err := b.callContract(ctx, call, b.blockchain.CurrentBlock(), state)
+	return rval, err
+}
+
 // PendingCallContract executes a contract call on the pending state.
 func (b *SimulatedBackend) PendingCallContract(ctx context.Context, call ethereum.CallMsg) ([]byte, error) {
 	b.mu.Lock()
@@ -218,6 +241,25 @@ func (b *SimulatedBackend) EstimateGas(ctx context.Context, call ethereum.CallMs
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
+	return b.estimateGas(ctx, call, b.pendingState)
+}
+
+// EstimateGasWithOverride executes the requested code like EstimateGas, with the
+// given accounts replaced in a copy of the pending state.
+func (b *SimulatedBackend) EstimateGasWithOverride(ctx context.Context, call ethereum.CallMsg, overrides ethereum.StateOverride) (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state := b.pendingState.Copy()
+	if err := core.ApplyStateOverride(state, overrides); err != nil {
+		return 0, err
+	}
+	return b.estimateGas(ctx, call, state)
+}
+
+// estimateGas binary searches the gas requirement of a call against the pending
+// block, executing it on the given state. The state is left unmodified.
+func (b *SimulatedBackend) estimateGas(ctx context.Context, call ethereum.CallMsg, statedb *state.StateDB) (uint64, error) {
 	// Determine the lowest and highest possible gas limits to binary search in between
 	var (
 		lo  uint64 = params.TxGas - 1
@@ -235,9 +277,9 @@ func (b *SimulatedBackend) EstimateGas(ctx context.Context, call ethereum.CallMs
 	executable := func(gas uint64) bool {
 		call.Gas = gas
 
-		snapshot := b.pendingState.Snapshot()
-		_, _, failed, err := b.callContract(ctx, call, b.pendingBlock, b.pendingState)
-		b.pendingState.RevertToSnapshot(snapshot)
+		snapshot := statedb.Snapshot()
+		_, _, failed, err := b.callContract(ctx, call, b.pendingBlock, statedb)
+		statedb.RevertToSnapshot(snapshot)
 
 		if err != nil || failed {
 			return false
@@ -438,6 +480,12 @@ func (fb *filterBackend) SubscribeTxPreEvent(ch chan<- core.TxPreEvent) event.Su
 		return nil
 	})
 }
+func (fb *filterBackend) SubscribeTxDropEvent(ch chan<- core.TxDropEvent) event.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	})
+}
 func (fb *filterBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription {
 	return fb.bc.SubscribeChainEvent(ch)
 }
@@ -0,0 +1,90 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package backends
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// Tests that calls and gas estimations see the overridden accounts, without
+// the overrides leaking into the simulated chain.
+func TestCallWithOverride(t *testing.T) {
+	contract := common.HexToAddress("0x0c")
+	slot := common.BigToHash(big.NewInt(1))
+
+	sim := NewSimulatedBackend(core.GenesisAlloc{
+		contract: {Balance: new(big.Int), Code: []byte{byte(vm.STOP)}, Storage: map[common.Hash]common.Hash{slot: {1}}},
+	})
+	// Code returning the value of storage slot 0x01
+	code := []byte{
+		byte(vm.PUSH1), 1, byte(vm.SLOAD),
+		byte(vm.PUSH1), 0, byte(vm.MSTORE),
+		byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN),
+	}
+	call := ethereum.CallMsg{To: &contract}
+
+	tests := []struct {
+		override ethereum.OverrideAccount
+		want     common.Hash
+		fail     bool
+	}{
+		{override: ethereum.OverrideAccount{Code: code}, want: common.Hash{1}},
+		{override: ethereum.OverrideAccount{Code: code, StateDiff: map[common.Hash]common.Hash{slot: {2}}}, want: common.Hash{2}},
+		{override: ethereum.OverrideAccount{Code: code, State: map[common.Hash]common.Hash{{2}: {2}}}, want: common.Hash{}},
+		{override: ethereum.OverrideAccount{State: map[common.Hash]common.Hash{}, StateDiff: map[common.Hash]common.Hash{}}, fail: true},
+	}
+	for i, tt := range tests {
+		overrides := ethereum.StateOverride{contract: tt.override}
+
+		res, err := sim.CallContractWithOverride(context.Background(), call, nil, overrides)
+		if tt.fail {
+			if err == nil {
+				t.Errorf("test %d: expected failure", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("test %d: call failed: %v", i, err)
+		}
+		if common.BytesToHash(res) != tt.want {
+			t.Errorf("test %d: result mismatch: have %x, want %x", i, res, tt.want)
+		}
+		if _, err := sim.EstimateGasWithOverride(context.Background(), call, overrides); err != nil {
+			t.Errorf("test %d: gas estimation failed: %v", i, err)
+		}
+	}
+	// Ensure the overrides didn't modify the simulated chain
+	if code, _ := sim.CodeAt(context.Background(), contract, nil); len(code) != 1 {
+		t.Errorf("code leaked from override: %x", code)
+	}
+	if code, _ := sim.PendingCodeAt(context.Background(), contract); len(code) != 1 {
+		t.Errorf("pending code leaked from override: %x", code)
+	}
+	if value, _ := sim.StorageAt(context.Background(), contract, slot, nil); common.BytesToHash(value) != (common.Hash{1}) {
+		t.Errorf("storage leaked from override: %x", value)
+	}
+	if gas, _ := sim.EstimateGasWithOverride(context.Background(), ethereum.CallMsg{To: &contract, Value: big.NewInt(0)}, nil); gas != 21000 {
+		t.Errorf("gas estimate mismatch without overrides: have %d, want %d", gas, 21000)
+	}
+}
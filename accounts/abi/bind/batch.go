@@ -0,0 +1,137 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// BatchCaller defines the methods needed to execute a batch of contract calls
+// in a single round trip. It is implemented by rpc.Client.
+type BatchCaller interface {
+	// BatchCallContext sends all given requests as a single batch and waits for
+	// the server to return a response for all of them.
+	BatchCallContext(ctx context.Context, b []rpc.BatchElem) error
+}
+
+// CallBatch is a collection of read-only contract calls, possibly to different
+// contracts, which are executed together in a single JSON-RPC batch request.
+type CallBatch struct {
+	calls []*batchedCall
+}
+
+// batchedCall is a contract call queued into a batch, along with the destination
+// of its unpacked results.
+type batchedCall struct {
+	contract *BoundContract
+	method   string
+	result   interface{}
+	args     map[string]interface{}
+	block    string
+}
+
+// NewCallBatch creates an empty batch of contract calls.
+func NewCallBatch() *CallBatch {
+	return new(CallBatch)
+}
+
+// Len returns the number of calls queued into the batch.
+func (b *CallBatch) Len() int {
+	return len(b.calls)
+}
+
+// Execute sends all the queued calls to the backend in a single batch request,
+// unpacking the results of each into the destination given when queueing them.
+// The batch is emptied afterwards, even on failure.
+//
+// If any of the calls fails, the error of the first one is returned, but the
+// results of all the successful ones are still unpacked.
+func (b *CallBatch) Execute(ctx context.Context, caller BatchCaller) error {
+	calls := b.calls
+	b.calls = nil
+
+	if len(calls) == 0 {
+		return nil
+	}
+	var (
+		outputs = make([]hexutil.Bytes, len(calls))
+		elems   = make([]rpc.BatchElem, len(calls))
+	)
+	for i, call := range calls {
+		elems[i] = rpc.BatchElem{
+			Method: "eth_call",
+			Args:   []interface{}{call.args, call.block},
+			Result: &outputs[i],
+		}
+	}
+	if err := caller.BatchCallContext(ensureContext(ctx), elems); err != nil {
+		return err
+	}
+	var failure error
+	for i, call := range calls {
+		err := elems[i].Error
+		if err == nil && len(outputs[i]) == 0 && len(call.contract.abi.Methods[call.method].Outputs) > 0 {
+			// Batched calls can't check the code, assume an empty result means none
+			err = ErrNoCode
+		}
+		if err == nil {
+			err = call.contract.abi.Unpack(call.result, call.method, outputs[i])
+		}
+		if err != nil && failure == nil {
+			failure = fmt.Errorf("%s: %v", call.method, err)
+		}
+	}
+	return failure
+}
+
+// BatchCall queues the (constant) contract method with params as input values
+// into the given batch. The result is only unpacked into the output parameter
+// once the batch is executed.
+func (c *BoundContract) BatchCall(batch *CallBatch, opts *CallOpts, result interface{}, method string, params ...interface{}) error {
+	// Don't crash on a lazy user
+	if opts == nil {
+		opts = new(CallOpts)
+	}
+	input, err := c.abi.Pack(method, params...)
+	if err != nil {
+		return err
+	}
+	args := map[string]interface{}{
+		"to":   c.address,
+		"data": hexutil.Bytes(input),
+	}
+	if opts.From != (common.Address{}) {
+		args["from"] = opts.From
+	}
+	block := "latest"
+	if opts.Pending {
+		block = "pending"
+	}
+	batch.calls = append(batch.calls, &batchedCall{
+		contract: c,
+		method:   method,
+		result:   result,
+		args:     args,
+		block:    block,
+	})
+	return nil
+}
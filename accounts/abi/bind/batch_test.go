@@ -0,0 +1,153 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const batchTestABI = `[
+	{"constant":true,"inputs":[{"name":"owner","type":"address"}],"name":"balance","outputs":[{"name":"","type":"uint256"}],"type":"function"},
+	{"constant":true,"inputs":[],"name":"pair","outputs":[{"name":"a","type":"uint256"},{"name":"b","type":"string"}],"type":"function"},
+	{"constant":true,"inputs":[],"name":"fail","outputs":[{"name":"","type":"uint256"}],"type":"function"}
+]`
+
+// CallArgs is the eth_call request accepted by the test service, exported for
+// the RPC server.
+type CallArgs struct {
+	From *common.Address `json:"from"`
+	To   common.Address  `json:"to"`
+	Data hexutil.Bytes   `json:"data"`
+}
+
+// EthService is a minimal eth namespace executing calls against canned results
+// and counting the requests served.
+type EthService struct {
+	abi     abi.ABI
+	code    common.Address // Only address with a contract deployed
+	pending int            // Number of calls on the pending state
+	calls   int
+}
+
+func (s *EthService) Call(args CallArgs, block string) (hexutil.Bytes, error) {
+	s.calls++
+	if block == "pending" {
+		s.pending++
+	}
+	if args.To != s.code {
+		return nil, nil
+	}
+	switch {
+	case bytes.HasPrefix(args.Data, s.abi.Methods["balance"].Id()):
+		owner := new(big.Int).SetBytes(args.Data[4:])
+		return s.abi.Methods["balance"].Outputs.Pack(owner.Mul(owner, big.NewInt(2)))
+	case bytes.HasPrefix(args.Data, s.abi.Methods["pair"].Id()):
+		return s.abi.Methods["pair"].Outputs.Pack(big.NewInt(1), "one")
+	}
+	return nil, errors.New("execution reverted")
+}
+
+// Tests that batched calls are executed in a single request and their results
+// unpacked into the requested destinations.
+func TestCallBatch(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(batchTestABI))
+	if err != nil {
+		t.Fatalf("failed to parse ABI: %v", err)
+	}
+	service := &EthService{abi: parsed, code: common.Address{0xc0}}
+
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", service); err != nil {
+		t.Fatalf("failed to register service: %v", err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	var (
+		contract = NewBoundContract(service.code, parsed, nil, nil, nil)
+		batch    = NewCallBatch()
+
+		balance = new(*big.Int)
+		pending = new(*big.Int)
+		pair    = new(struct {
+			A *big.Int
+			B string
+		})
+	)
+	if err := contract.BatchCall(batch, nil, balance, "balance", common.Address{0x01}); err != nil {
+		t.Fatalf("failed to queue balance call: %v", err)
+	}
+	if err := contract.BatchCall(batch, &CallOpts{Pending: true}, pending, "balance", common.Address{0x02}); err != nil {
+		t.Fatalf("failed to queue pending balance call: %v", err)
+	}
+	if err := contract.BatchCall(batch, nil, pair, "pair"); err != nil {
+		t.Fatalf("failed to queue pair call: %v", err)
+	}
+	if err := contract.BatchCall(batch, nil, balance, "balance"); err == nil {
+		t.Fatalf("call with missing arguments queued")
+	}
+	if batch.Len() != 3 {
+		t.Fatalf("batch length mismatch: have %d, want 3", batch.Len())
+	}
+	if err := batch.Execute(context.Background(), client); err != nil {
+		t.Fatalf("failed to execute batch: %v", err)
+	}
+	if batch.Len() != 0 {
+		t.Errorf("batch not emptied after execution: %d calls left", batch.Len())
+	}
+	if service.calls != 3 || service.pending != 1 {
+		t.Errorf("served calls mismatch: have %d/%d, want 3/1", service.calls, service.pending)
+	}
+	want := new(big.Int).Lsh(big.NewInt(1), 153)
+	if (*balance).Cmp(want) != 0 {
+		t.Errorf("balance mismatch: have %v, want %v", *balance, want)
+	}
+	if (*pending).Cmp(new(big.Int).Lsh(want, 1)) != 0 {
+		t.Errorf("pending balance mismatch: have %v, want %v", *pending, new(big.Int).Lsh(want, 1))
+	}
+	if pair.A.Cmp(big.NewInt(1)) != 0 || pair.B != "one" {
+		t.Errorf("pair mismatch: have %v/%s, want 1/one", pair.A, pair.B)
+	}
+	// Ensure failures are reported without preventing the other results
+	missing := NewBoundContract(common.Address{0xff}, parsed, nil, nil, nil)
+
+	*balance = nil
+	contract.BatchCall(batch, nil, new(*big.Int), "fail")
+	missing.BatchCall(batch, nil, new(*big.Int), "pair")
+	contract.BatchCall(batch, nil, balance, "balance", common.Address{0x01})
+
+	if err := batch.Execute(context.Background(), client); err == nil || !strings.Contains(err.Error(), "execution reverted") {
+		t.Errorf("error mismatch: have %v, want execution reverted", err)
+	}
+	if *balance == nil || (*balance).Cmp(want) != 0 {
+		t.Errorf("balance mismatch after failure: have %v, want %v", *balance, want)
+	}
+	missing.BatchCall(batch, nil, new(*big.Int), "pair")
+	if err := batch.Execute(context.Background(), client); err == nil || !strings.Contains(err.Error(), ErrNoCode.Error()) {
+		t.Errorf("error mismatch: have %v, want %v", err, ErrNoCode)
+	}
+}
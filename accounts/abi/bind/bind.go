@@ -24,6 +24,7 @@ import (
 	"bytes"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 	"text/template"
 	"unicode"
@@ -39,15 +40,26 @@ const (
 	LangGo Lang = iota
 	LangJava
 	LangObjC
+	LangTypeScript
+	LangJSONSchema
 )
 
 // Bind generates a Go wrapper around a contract ABI. This wrapper isn't meant
 // to be used as is in client code, but rather as an intermediate struct which
 // enforces compile time type safety and naming convention opposed to having to
 // manually maintain hard coded strings that break on runtime.
-func Bind(types []string, abis []string, bytecodes []string, pkg string, lang Lang) (string, error) {
+//
+// If batch is set, a batched caller is generated for each contract, queueing its
+// calls into a bind.CallBatch to be executed in a single round trip.
+func Bind(types []string, abis []string, bytecodes []string, pkg string, lang Lang, batch bool) (string, error) {
+	if batch && lang != LangGo {
+		return "", fmt.Errorf("batched callers are only supported in Go bindings")
+	}
 	// Process each individual contract requested binding
-	contracts := make(map[string]*tmplContract)
+	var (
+		contracts = make(map[string]*tmplContract)
+		structs   = make(map[string]*tmplStruct)
+	)
 
 	for i := 0; i < len(types); i++ {
 		// Parse the actual ABI to generate the binding for
@@ -126,20 +138,53 @@ func Bind(types []string, abis []string, bytecodes []string, pkg string, lang La
 			Transacts:   transacts,
 			Events:      events,
 		}
+		// Generate the structs of the tuples in a deterministic order, so the
+		// names assigned to them are stable between runs
+		for _, args := range contractArguments(evmABI) {
+			for _, arg := range args {
+				if !hasTuple(arg.Type) {
+					continue
+				}
+				switch lang {
+				case LangGo, LangTypeScript:
+					bindType[lang](arg.Type, structs)
+				case LangJSONSchema:
+					// Tuples are inlined into the schema of the events
+				default:
+					return "", fmt.Errorf("%s: tuple type %s is only supported in Go and TypeScript bindings", types[i], arg.Type)
+				}
+			}
+		}
 	}
 	// Generate the contract template data content and render it
 	data := &tmplData{
 		Package:   pkg,
 		Contracts: contracts,
+		Structs:   structs,
+		Batch:     batch,
+	}
+	// The JSON schema is assembled directly, not through a template
+	if lang == LangJSONSchema {
+		return bindJSONSchema(data)
 	}
 	buffer := new(bytes.Buffer)
 
 	funcs := map[string]interface{}{
-		"bindtype":      bindType[lang],
-		"bindtopictype": bindTopicType[lang],
-		"namedtype":     namedType[lang],
-		"capitalise":    capitalise,
-		"decapitalise":  decapitalise,
+		"bindtype": func(kind abi.Type) string {
+			return bindType[lang](kind, structs)
+		},
+		"bindtopictype": func(kind abi.Type) string {
+			return bindTopicType[lang](kind, structs)
+		},
+		"namedtype":    namedType[lang],
+		"capitalise":   capitalise,
+		"decapitalise": decapitalise,
+		"abitype":      abiTypeString,
+		"fieldname":    fieldName,
+		"hexprefix":    hexPrefix,
+		"unindexed":    unindexed,
+		"topiccount":   topicCount,
+		"tsdecode":     decodeExprTypeScript,
 	}
 	tmpl := template.Must(template.New("").Funcs(funcs).Parse(tmplSource[lang]))
 	if err := tmpl.Execute(buffer, data); err != nil {
@@ -153,21 +198,67 @@ func Bind(types []string, abis []string, bytecodes []string, pkg string, lang La
 		}
 		return string(code), nil
 	}
+	// For TypeScript bindings drop the empty lines left behind by the template
+	if lang == LangTypeScript {
+		return strings.TrimSpace(regexp.MustCompile(`\n{3,}`).ReplaceAllString(buffer.String(), "\n\n")) + "\n", nil
+	}
 	// For all others just return as is for now
 	return buffer.String(), nil
 }
 
 // bindType is a set of type binders that convert Solidity types to some supported
-// programming language types.
-var bindType = map[Lang]func(kind abi.Type) string{
-	LangGo:   bindTypeGo,
-	LangJava: bindTypeJava,
+// programming language types. Tuples are converted to structs, which are added
+// to the given set keyed by their definition.
+var bindType = map[Lang]func(kind abi.Type, structs map[string]*tmplStruct) string{
+	LangGo:         bindTypeGo,
+	LangJava:       func(kind abi.Type, structs map[string]*tmplStruct) string { return bindTypeJava(kind) },
+	LangTypeScript: bindTypeTypeScript,
 }
 
-// bindTypeGo converts a Solidity type to a Go one. Since there is no clear mapping
-// from all Solidity types to Go ones (e.g. uint17), those that cannot be exactly
-// mapped will use an upscaled type (e.g. *big.Int).
-func bindTypeGo(kind abi.Type) string {
+// bindTypeGo converts a Solidity type to a Go one, generating a struct for each
+// distinct tuple and recursing into the elements of arrays and slices.
+func bindTypeGo(kind abi.Type, structs map[string]*tmplStruct) string {
+	switch kind.T {
+	case abi.TupleTy:
+		return bindStructTypeGo(kind, structs)
+	case abi.ArrayTy:
+		return fmt.Sprintf("[%d]", kind.Size) + bindTypeGo(*kind.Elem, structs)
+	case abi.SliceTy:
+		return "[]" + bindTypeGo(*kind.Elem, structs)
+	default:
+		return bindBasicTypeGo(kind)
+	}
+}
+
+// bindStructTypeGo converts a Solidity tuple to a Go struct, reusing previously
+// generated structs with the same fields.
+func bindStructTypeGo(kind abi.Type, structs map[string]*tmplStruct) string {
+	var (
+		fields []*tmplField
+		defs   []string
+	)
+	for i, elem := range kind.TupleElems {
+		field := &tmplField{
+			Type:    bindTypeGo(*elem, structs),
+			Name:    capitalise(kind.TupleRawNames[i]),
+			SolKind: *elem,
+		}
+		fields = append(fields, field)
+		defs = append(defs, field.Name+" "+field.Type)
+	}
+	id := "struct{" + strings.Join(defs, ";") + "}"
+	if s, exist := structs[id]; exist {
+		return s.Name
+	}
+	name := fmt.Sprintf("Struct%d", len(structs))
+	structs[id] = &tmplStruct{Name: name, Fields: fields}
+	return name
+}
+
+// bindBasicTypeGo converts an elementary Solidity type to a Go one. Since there
+// is no clear mapping from all Solidity types to Go ones (e.g. uint17), those that
+// cannot be exactly mapped will use an upscaled type (e.g. *big.Int).
+func bindBasicTypeGo(kind abi.Type) string {
 	stringKind := kind.String()
 
 	switch {
@@ -279,18 +370,64 @@ func bindTypeJava(kind abi.Type) string {
 	}
 }
 
+// bindTypeTypeScript converts a Solidity type to a TypeScript one, generating an
+// interface for each distinct tuple. Integers which may not fit into a number are
+// converted to BigNumberish, byte arrays and addresses to hex strings.
+func bindTypeTypeScript(kind abi.Type, structs map[string]*tmplStruct) string {
+	switch kind.T {
+	case abi.TupleTy:
+		var (
+			fields []*tmplField
+			defs   []string
+		)
+		for i, elem := range kind.TupleElems {
+			field := &tmplField{
+				Type:    bindTypeTypeScript(*elem, structs),
+				Name:    fieldName(kind.TupleRawNames[i], i),
+				SolKind: *elem,
+			}
+			fields = append(fields, field)
+			defs = append(defs, field.Name+": "+field.Type)
+		}
+		id := "{" + strings.Join(defs, "; ") + "}"
+		if s, exist := structs[id]; exist {
+			return s.Name
+		}
+		name := fmt.Sprintf("Struct%d", len(structs))
+		structs[id] = &tmplStruct{Name: name, Fields: fields}
+		return name
+
+	case abi.ArrayTy, abi.SliceTy:
+		return bindTypeTypeScript(*kind.Elem, structs) + "[]"
+
+	case abi.IntTy, abi.UintTy:
+		if kind.Size <= 32 {
+			return "number"
+		}
+		return "BigNumberish"
+
+	case abi.BoolTy:
+		return "boolean"
+
+	default:
+		return "string"
+	}
+}
+
 // bindTopicType is a set of type binders that convert Solidity types to some
 // supported programming language topic types.
-var bindTopicType = map[Lang]func(kind abi.Type) string{
-	LangGo:   bindTopicTypeGo,
-	LangJava: bindTopicTypeJava,
+var bindTopicType = map[Lang]func(kind abi.Type, structs map[string]*tmplStruct) string{
+	LangGo:         bindTopicTypeGo,
+	LangJava:       func(kind abi.Type, structs map[string]*tmplStruct) string { return bindTopicTypeJava(kind) },
+	LangTypeScript: bindTopicTypeTypeScript,
 }
 
 // bindTypeGo converts a Solidity topic type to a Go one. It is almost the same
-// funcionality as for simple types, but dynamic types get converted to hashes.
-func bindTopicTypeGo(kind abi.Type) string {
-	bound := bindTypeGo(kind)
-	if bound == "string" || bound == "[]byte" {
+// funcionality as for simple types, but dynamic types and tuples get converted
+// to hashes.
+func bindTopicTypeGo(kind abi.Type, structs map[string]*tmplStruct) string {
+	bound := bindTypeGo(kind, structs)
+	if bound == "string" || bound == "[]byte" || kind.T == abi.TupleTy {
 		bound = "common.Hash"
 	}
 	return bound
@@ -306,6 +443,15 @@ func bindTopicTypeJava(kind abi.Type) string {
 	return bound
 }
 
+// bindTopicTypeTypeScript converts a Solidity topic type to a TypeScript one. Types
+// which are hashed into the topic are converted to the hash as hex string.
+func bindTopicTypeTypeScript(kind abi.Type, structs map[string]*tmplStruct) string {
+	if hashedTopic(kind) {
+		return "string"
+	}
+	return bindTypeTypeScript(kind, structs)
+}
+
 // namedType is a set of functions that transform language specific types to
 // named versions that my be used inside method names.
 var namedType = map[Lang]func(string, abi.Type) string{
@@ -352,8 +498,10 @@ func namedTypeJava(javaKind string, solKind abi.Type) string {
 // methodNormalizer is a name transformer that modifies Solidity method names to
 // conform to target language naming concentions.
 var methodNormalizer = map[Lang]func(string) string{
-	LangGo:   capitalise,
-	LangJava: decapitalise,
+	LangGo:         capitalise,
+	LangJava:       decapitalise,
+	LangTypeScript: capitalise,
+	LangJSONSchema: capitalise,
 }
 
 // capitalise makes the first character of a string upper case, also removing any
@@ -373,6 +521,42 @@ func decapitalise(input string) string {
 	return strings.ToLower(input[:1]) + input[1:]
 }
 
+// hasTuple reports whether the type is a tuple or an array or slice of tuples.
+func hasTuple(kind abi.Type) bool {
+	switch kind.T {
+	case abi.TupleTy:
+		return true
+	case abi.ArrayTy, abi.SliceTy:
+		return hasTuple(*kind.Elem)
+	default:
+		return false
+	}
+}
+
+// contractArguments returns the argument lists of the constructor, methods and
+// events of a contract, ordered by name.
+func contractArguments(contract abi.ABI) []abi.Arguments {
+	args := []abi.Arguments{contract.Constructor.Inputs}
+
+	methods := make([]string, 0, len(contract.Methods))
+	for name := range contract.Methods {
+		methods = append(methods, name)
+	}
+	sort.Strings(methods)
+	for _, name := range methods {
+		args = append(args, contract.Methods[name].Inputs, contract.Methods[name].Outputs)
+	}
+	events := make([]string, 0, len(contract.Events))
+	for name := range contract.Events {
+		events = append(events, name)
+	}
+	sort.Strings(events)
+	for _, name := range events {
+		args = append(args, contract.Events[name].Inputs)
+	}
+	return args
+}
+
 // structured checks whether a list of ABI data types has enough information to
 // operate through a proper Go struct or if flat returns are needed.
 func structured(args abi.Arguments) bool {
@@ -395,3 +579,88 @@ func structured(args abi.Arguments) bool {
 	}
 	return true
 }
+
+// hashedTopic reports whether an indexed event argument of the given type is
+// stored as the hash of its value in the topic.
+func hashedTopic(kind abi.Type) bool {
+	switch kind.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		return true
+	default:
+		return false
+	}
+}
+
+// abiTypeString returns the canonical type string of a Solidity type as used by
+// ABI encoders, spelling tuples out as tuple(...).
+func abiTypeString(kind abi.Type) string {
+	switch kind.T {
+	case abi.TupleTy:
+		elems := make([]string, len(kind.TupleElems))
+		for i, elem := range kind.TupleElems {
+			elems[i] = abiTypeString(*elem)
+		}
+		return "tuple(" + strings.Join(elems, ",") + ")"
+	case abi.ArrayTy:
+		return fmt.Sprintf("%s[%d]", abiTypeString(*kind.Elem), kind.Size)
+	case abi.SliceTy:
+		return abiTypeString(*kind.Elem) + "[]"
+	default:
+		return kind.String()
+	}
+}
+
+// fieldName returns the name of an argument or tuple field, or a positional one
+// if it is anonymous.
+func fieldName(name string, index int) string {
+	if name == "" {
+		return fmt.Sprintf("arg%d", index)
+	}
+	return name
+}
+
+// hexPrefix ensures a hex string starts with 0x.
+func hexPrefix(input string) string {
+	if strings.HasPrefix(input, "0x") || strings.HasPrefix(input, "0X") {
+		return input
+	}
+	return "0x" + input
+}
+
+// unindexed returns the arguments of an event stored in the log data.
+func unindexed(args abi.Arguments) abi.Arguments {
+	var data abi.Arguments
+	for _, arg := range args {
+		if !arg.Indexed {
+			data = append(data, arg)
+		}
+	}
+	return data
+}
+
+// topicCount returns the number of topics of a log of a non-anonymous event.
+func topicCount(args abi.Arguments) int {
+	return 1 + len(args) - len(unindexed(args))
+}
+
+// decodeExprTypeScript returns the TypeScript expression extracting the value of
+// an event argument from a log, either from its topic or from the unpacked data.
+func decodeExprTypeScript(args abi.Arguments, index int) string {
+	var topic, data int
+	for _, arg := range args[:index] {
+		if arg.Indexed {
+			topic++
+		} else {
+			data++
+		}
+	}
+	arg := args[index]
+	switch {
+	case !arg.Indexed:
+		return fmt.Sprintf("data[%d]", data)
+	case hashedTopic(arg.Type):
+		return fmt.Sprintf("log.topics[%d]", topic+1)
+	default:
+		return fmt.Sprintf("coder.decode([\"%s\"], log.topics[%d])[0]", abiTypeString(arg.Type), topic+1)
+	}
+}
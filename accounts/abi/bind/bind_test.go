@@ -17,6 +17,7 @@
 package bind
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -737,6 +738,139 @@ var bindTests = []struct {
 			}
 		`,
 	},
+	// Tests that tuples are bound to Go structs and can be passed and returned
+	{
+		`Echoer`,
+		`
+			pragma experimental ABIEncoderV2;
+
+			contract Echoer {
+				struct Item {
+					address owner;
+					bool[] flags;
+				}
+				struct Record {
+					uint256 a;
+					string b;
+					Item[] items;
+				}
+				function echo(Record r) public pure returns (Record);
+				function echoItems(Item[] items) public pure returns (Item[]);
+				function pair(Item item, uint256 num) public pure returns (Item item, uint256 num);
+
+				// The methods are implemented by echoing back the call arguments, which
+				// coincide with the encoding of the returned values.
+				function() external {
+					assembly {
+						calldatacopy(0, 4, sub(calldatasize, 4))
+						return(0, sub(calldatasize, 4))
+					}
+				}
+			}
+		`,
+		`600e600c600039600e6000f336600490038060046000376000f3`,
+		`[{"constant":true,"inputs":[{"components":[{"name":"a","type":"uint256"},{"name":"b","type":"string"},{"components":[{"name":"owner","type":"address"},{"name":"flags","type":"bool[]"}],"name":"items","type":"tuple[]"}],"name":"r","type":"tuple"}],"name":"echo","outputs":[{"components":[{"name":"a","type":"uint256"},{"name":"b","type":"string"},{"components":[{"name":"owner","type":"address"},{"name":"flags","type":"bool[]"}],"name":"items","type":"tuple[]"}],"name":"","type":"tuple"}],"type":"function"},{"constant":true,"inputs":[{"components":[{"name":"owner","type":"address"},{"name":"flags","type":"bool[]"}],"name":"items","type":"tuple[]"}],"name":"echoItems","outputs":[{"components":[{"name":"owner","type":"address"},{"name":"flags","type":"bool[]"}],"name":"","type":"tuple[]"}],"type":"function"},{"constant":true,"inputs":[{"components":[{"name":"owner","type":"address"},{"name":"flags","type":"bool[]"}],"name":"item","type":"tuple"},{"name":"num","type":"uint256"}],"name":"pair","outputs":[{"components":[{"name":"owner","type":"address"},{"name":"flags","type":"bool[]"}],"name":"item","type":"tuple"},{"name":"num","type":"uint256"}],"type":"function"}]`,
+		`
+			// Generate a new random account and a funded simulator
+			key, _ := crypto.GenerateKey()
+			auth := bind.NewKeyedTransactor(key)
+			sim := backends.NewSimulatedBackend(core.GenesisAlloc{auth.From: {Balance: big.NewInt(10000000000)}})
+
+			// Deploy an echoer contract and execute a structured call on it
+			_, _, echoer, err := DeployEchoer(auth, sim)
+			if err != nil {
+				t.Fatalf("Failed to deploy echoer contract: %v", err)
+			}
+			sim.Commit()
+
+			items := []Struct0{
+				{Owner: common.Address{1}, Flags: []bool{true, false}},
+				{Owner: common.Address{2}, Flags: []bool{}},
+			}
+			record := Struct1{A: big.NewInt(7), B: "tuple", Items: items}
+
+			if res, err := echoer.Echo(nil, record); err != nil {
+				t.Fatalf("Failed to echo record: %v", err)
+			} else if !reflect.DeepEqual(res, record) {
+				t.Fatalf("Echoed record mismatch: have %+v, want %+v", res, record)
+			}
+			if res, err := echoer.EchoItems(nil, items); err != nil {
+				t.Fatalf("Failed to echo items: %v", err)
+			} else if !reflect.DeepEqual(res, items) {
+				t.Fatalf("Echoed items mismatch: have %+v, want %+v", res, items)
+			}
+			if res, err := echoer.Pair(nil, items[0], big.NewInt(3)); err != nil {
+				t.Fatalf("Failed to echo pair: %v", err)
+			} else if !reflect.DeepEqual(res.Item, items[0]) || res.Num.Cmp(big.NewInt(3)) != 0 {
+				t.Fatalf("Echoed pair mismatch: have %+v, want %+v and 3", res, items[0])
+			}
+		`,
+	},
+	// Tests that contracts can be deployed waiting for the receipt and that calls
+	// can be batched
+	{
+		`Batcher`,
+		`
+			contract Batcher {
+				function getter() constant returns (string, int, bytes32) {
+					return ("Hi", 1, sha3(""));
+				}
+			}
+		`,
+		`606060405260dc8060106000396000f3606060405260e060020a6000350463993a04b78114601a575b005b600060605260c0604052600260809081527f486900000000000000000000000000000000000000000000000000000000000060a05260017fc5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a47060e0829052610100819052606060c0908152600261012081905281906101409060a09080838184600060046012f1505081517fffff000000000000000000000000000000000000000000000000000000000000169091525050604051610160819003945092505050f3`,
+		`[{"constant":true,"inputs":[],"name":"getter","outputs":[{"name":"","type":"string"},{"name":"","type":"int256"},{"name":"","type":"bytes32"}],"type":"function"}]`,
+		`
+			// Generate a new random account and a funded simulator
+			key, _ := crypto.GenerateKey()
+			auth := bind.NewKeyedTransactor(key)
+			sim := backends.NewSimulatedBackend(core.GenesisAlloc{auth.From: {Balance: big.NewInt(10000000000)}})
+
+			// Deploy a batcher contract, mining blocks until the deployment is done
+			done := make(chan struct{})
+			defer close(done)
+			go func() {
+				for {
+					select {
+					case <-done:
+						return
+					case <-time.After(100 * time.Millisecond):
+						sim.Commit()
+					}
+				}
+			}()
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			address, _, batcher, err := DeployBatcherAndWait(ctx, auth, sim)
+			if err != nil {
+				t.Fatalf("Failed to deploy batcher contract: %v", err)
+			}
+			if code, err := sim.CodeAt(ctx, address, nil); err != nil || len(code) == 0 {
+				t.Fatalf("Contract not deployed: %x/%v", code, err)
+			}
+			if str, _, _, err := batcher.Getter(nil); err != nil || str != "Hi" {
+				t.Fatalf("Failed to call deployed contract: %v/%v", str, err)
+			}
+			// Queue a few calls into a batch
+			batch := bind.NewCallBatch()
+			batched, err := NewBatcherBatch(address, batch)
+			if err != nil {
+				t.Fatalf("Failed to bind batched caller: %v", err)
+			}
+			for i := 0; i < 3; i++ {
+				str, num, _, err := batched.Getter(nil)
+				if err != nil {
+					t.Fatalf("Failed to queue call: %v", err)
+				}
+				if *str != "" || *num != nil {
+					t.Fatalf("Results filled before execution: %v/%v", *str, *num)
+				}
+			}
+			if batch.Len() != 3 {
+				t.Fatalf("Batch length mismatch: have %d, want 3", batch.Len())
+			}
+		`,
+	},
 }
 
 // Tests that packages generated by the binder can be successfully compiled and
@@ -770,7 +904,7 @@ func TestBindings(t *testing.T) {
 	// Generate the test suite for all the contracts
 	for i, tt := range bindTests {
 		// Generate the binding and create a Go source file in the workspace
-		bind, err := Bind([]string{tt.name}, []string{tt.abi}, []string{tt.bytecode}, "bindtest", LangGo)
+		bind, err := Bind([]string{tt.name}, []string{tt.abi}, []string{tt.bytecode}, "bindtest", LangGo, true)
 		if err != nil {
 			t.Fatalf("test %d: failed to generate binding: %v", i, err)
 		}
@@ -794,3 +928,82 @@ func TestBindings(t *testing.T) {
 		t.Fatalf("failed to run binding test: %v\n%s", err, out)
 	}
 }
+
+// langTestABI is a contract exercising the type conversions of the TypeScript
+// and JSON schema bindings.
+const langTestABI = `[
+	{"constant":true,"inputs":[{"name":"owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"type":"function"},
+	{"constant":true,"inputs":[],"name":"pair","outputs":[{"name":"a","type":"uint8"},{"name":"b","type":"string"}],"type":"function"},
+	{"constant":false,"inputs":[{"components":[{"name":"owner","type":"address"},{"name":"flags","type":"bool[]"}],"name":"item","type":"tuple"}],"name":"store","outputs":[],"type":"function"},
+	{"inputs":[{"name":"supply","type":"uint256"}],"type":"constructor"},
+	{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"note","type":"string"},{"indexed":false,"name":"","type":"int16"},{"indexed":false,"name":"hash","type":"bytes32"}],"name":"Noted","type":"event"}
+]`
+
+// Tests that the TypeScript bindings contain the expected definitions and
+// encoders.
+func TestBindTypeScript(t *testing.T) {
+	code, err := Bind([]string{"Token"}, []string{langTestABI}, []string{"6060"}, "token", LangTypeScript, false)
+	if err != nil {
+		t.Fatalf("failed to generate binding: %v", err)
+	}
+	for _, want := range []string{
+		"export interface Struct0 {\n  owner: string;\n  flags: boolean[];\n}",
+		`export const TokenBin = "0x6060";`,
+		`export function encodeTokenDeploy(coder: AbiCoder, supply: BigNumberish): string {`,
+		`return "0x70a08231" + coder.encode(["address"], [owner]).slice(2);`,
+		`export function decodeTokenPair(coder: AbiCoder, data: string): { a: number; b: string; } {`,
+		`coder.encode(["tuple(address,bool[])"], [item])`,
+		"export interface TokenNoted {\n  from: string;\n  note: string;\n  arg2: number;\n  hash: string;\n}",
+		`if (log.topics.length !== 3 || log.topics[0].toLowerCase() !== TokenNotedTopic) {`,
+		`from: coder.decode(["address"], log.topics[1])[0],`,
+		`note: log.topics[2],`,
+		`hash: data[1],`,
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("binding missing %q:\n%s", want, code)
+		}
+	}
+	if strings.Contains(code, "\n\n\n") {
+		t.Errorf("binding contains consecutive empty lines")
+	}
+	if _, err := Bind([]string{"Token"}, []string{langTestABI}, []string{""}, "token", LangTypeScript, true); err == nil {
+		t.Errorf("batched TypeScript binding generated")
+	}
+}
+
+// Tests that the JSON schema describes the decoded event payloads.
+func TestBindJSONSchema(t *testing.T) {
+	code, err := Bind([]string{"Token"}, []string{langTestABI}, []string{""}, "token", LangJSONSchema, false)
+	if err != nil {
+		t.Fatalf("failed to generate schema: %v", err)
+	}
+	var schema struct {
+		Definitions map[string]struct {
+			Properties map[string]map[string]interface{} `json:"properties"`
+			Required   []string                          `json:"required"`
+		} `json:"definitions"`
+		OneOf []map[string]string `json:"oneOf"`
+	}
+	if err := json.Unmarshal([]byte(code), &schema); err != nil {
+		t.Fatalf("failed to parse schema: %v\n%s", err, code)
+	}
+	if len(schema.OneOf) != 1 || schema.OneOf[0]["$ref"] != "#/definitions/TokenNoted" {
+		t.Fatalf("event references mismatch: have %v", schema.OneOf)
+	}
+	event := schema.Definitions["TokenNoted"]
+	if have, want := strings.Join(event.Required, ","), "from,note,arg2,hash"; have != want {
+		t.Errorf("required fields mismatch: have %s, want %s", have, want)
+	}
+	tests := map[string]string{
+		"from": `{"pattern":"^0x[0-9a-fA-F]{40}$","type":"string"}`,
+		"note": `{"description":"Keccak256 hash of the indexed string value","pattern":"^0x[0-9a-fA-F]{64}$","type":"string"}`,
+		"arg2": `{"maximum":32767,"minimum":-32768,"type":"integer"}`,
+		"hash": `{"pattern":"^0x[0-9a-fA-F]{64}$","type":"string"}`,
+	}
+	for name, want := range tests {
+		have, _ := json.Marshal(event.Properties[name])
+		if string(have) != want {
+			t.Errorf("%s: schema mismatch: have %s, want %s", name, have, want)
+		}
+	}
+}
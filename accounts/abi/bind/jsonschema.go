@@ -0,0 +1,157 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// jsonSchema is a node of a JSON schema document.
+type jsonSchema map[string]interface{}
+
+// bindJSONSchema generates a JSON schema describing the decoded payloads of the
+// events of the contracts. Each event is a definition named after the contract
+// and the event, and the document accepts any one of them.
+//
+// Addresses, byte arrays and hashes are hex strings with 0x prefix, integers
+// which may not fit into a double are decimal strings.
+func bindJSONSchema(data *tmplData) (string, error) {
+	var (
+		definitions = make(jsonSchema)
+		names       []string
+	)
+	for _, contract := range data.Contracts {
+		for _, event := range contract.Events {
+			var (
+				properties = make(jsonSchema)
+				required   = make([]string, 0, len(event.Original.Inputs))
+			)
+			for i, input := range event.Original.Inputs {
+				name := fieldName(input.Name, i)
+				if input.Indexed && hashedTopic(input.Type) {
+					properties[name] = jsonSchema{
+						"type":        "string",
+						"pattern":     "^0x[0-9a-fA-F]{64}$",
+						"description": fmt.Sprintf("Keccak256 hash of the indexed %s value", input.Type),
+					}
+				} else {
+					properties[name] = jsonSchemaType(input.Type)
+				}
+				required = append(required, name)
+			}
+			name := contract.Type + event.Normalized.Name
+			definitions[name] = jsonSchema{
+				"type":                 "object",
+				"description":          "Solidity: " + event.Original.String(),
+				"properties":           properties,
+				"required":             required,
+				"additionalProperties": false,
+			}
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	events := make([]jsonSchema, len(names))
+	for i, name := range names {
+		events[i] = jsonSchema{"$ref": "#/definitions/" + name}
+	}
+	schema := jsonSchema{
+		"$schema":     "http://json-schema.org/draft-07/schema#",
+		"title":       data.Package,
+		"description": fmt.Sprintf("Decoded event payloads of the %s contracts", data.Package),
+		"definitions": definitions,
+		"oneOf":       events,
+	}
+	blob, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(blob) + "\n", nil
+}
+
+// jsonSchemaType converts a Solidity type to the JSON schema of its decoded value,
+// inlining tuples as nested objects.
+func jsonSchemaType(kind abi.Type) jsonSchema {
+	switch kind.T {
+	case abi.TupleTy:
+		var (
+			properties = make(jsonSchema)
+			required   = make([]string, 0, len(kind.TupleElems))
+		)
+		for i, elem := range kind.TupleElems {
+			name := fieldName(kind.TupleRawNames[i], i)
+			properties[name] = jsonSchemaType(*elem)
+			required = append(required, name)
+		}
+		return jsonSchema{
+			"type":                 "object",
+			"properties":           properties,
+			"required":             required,
+			"additionalProperties": false,
+		}
+
+	case abi.ArrayTy:
+		return jsonSchema{
+			"type":     "array",
+			"items":    jsonSchemaType(*kind.Elem),
+			"minItems": kind.Size,
+			"maxItems": kind.Size,
+		}
+
+	case abi.SliceTy:
+		return jsonSchema{
+			"type":  "array",
+			"items": jsonSchemaType(*kind.Elem),
+		}
+
+	case abi.IntTy, abi.UintTy:
+		if kind.Size > 32 {
+			pattern := "^-?[0-9]+$"
+			if kind.T == abi.UintTy {
+				pattern = "^[0-9]+$"
+			}
+			return jsonSchema{"type": "string", "pattern": pattern, "description": kind.String() + " as decimal string"}
+		}
+		bound := new(big.Int).Lsh(big.NewInt(1), uint(kind.Size))
+		if kind.T == abi.UintTy {
+			return jsonSchema{"type": "integer", "minimum": 0, "maximum": bound.Sub(bound, big.NewInt(1))}
+		}
+		bound.Rsh(bound, 1)
+		return jsonSchema{"type": "integer", "minimum": new(big.Int).Neg(bound), "maximum": bound.Sub(bound, big.NewInt(1))}
+
+	case abi.BoolTy:
+		return jsonSchema{"type": "boolean"}
+
+	case abi.StringTy:
+		return jsonSchema{"type": "string"}
+
+	case abi.AddressTy:
+		return jsonSchema{"type": "string", "pattern": "^0x[0-9a-fA-F]{40}$"}
+
+	case abi.FixedBytesTy:
+		return jsonSchema{"type": "string", "pattern": fmt.Sprintf("^0x[0-9a-fA-F]{%d}$", 2*kind.Size)}
+
+	default:
+		return jsonSchema{"type": "string", "pattern": "^0x([0-9a-fA-F]{2})*$"}
+	}
+}
@@ -22,6 +22,8 @@ import "github.com/ethereum/go-ethereum/accounts/abi"
 type tmplData struct {
 	Package   string                   // Name of the package to place the generated file in
 	Contracts map[string]*tmplContract // List of contracts to generate into this file
+	Structs   map[string]*tmplStruct   // Go structs reflecting the tuples of the contracts
+	Batch     bool                     // Whether to generate batched callers for the contracts
 }
 
 // tmplContract contains the data needed to generate an individual contract binding.
@@ -49,11 +51,26 @@ type tmplEvent struct {
 	Normalized abi.Event // Normalized version of the parsed fields
 }
 
+// tmplField is a field of a generated struct, with its type converted to the
+// binding language.
+type tmplField struct {
+	Type    string   // Field type representation depends on target binding language
+	Name    string   // Field name converted from the raw user-defined field name
+	SolKind abi.Type // Raw abi type information
+}
+
+// tmplStruct is a struct generated to reflect a tuple of the contract ABI.
+type tmplStruct struct {
+	Name   string       // Auto-generated struct name
+	Fields []*tmplField // Struct fields definition depends on the binding language
+}
+
 // tmplSource is language to template mapping containing all the supported
 // programming languages the package can generate to.
 var tmplSource = map[Lang]string{
-	LangGo:   tmplSourceGo,
-	LangJava: tmplSourceJava,
+	LangGo:         tmplSourceGo,
+	LangJava:       tmplSourceJava,
+	LangTypeScript: tmplSourceTypeScript,
 }
 
 // tmplSourceGo is the Go source template use to generate the contract binding
@@ -64,6 +81,14 @@ const tmplSourceGo = `
 
 package {{.Package}}
 
+{{range .Structs}}
+	// {{.Name}} is an auto generated low-level Go binding around a user-defined struct.
+	type {{.Name}} struct {
+	{{range $field := .Fields}}
+	{{$field.Name}} {{$field.Type}}{{end}}
+	}
+{{end}}
+
 {{range $contract := .Contracts}}
 	// {{.Type}}ABI is the input ABI used to generate the binding from.
 	const {{.Type}}ABI = "{{.InputABI}}"
@@ -84,6 +109,19 @@ package {{.Package}}
 		  }
 		  return address, tx, &{{.Type}}{ {{.Type}}Caller: {{.Type}}Caller{contract: contract}, {{.Type}}Transactor: {{.Type}}Transactor{contract: contract}, {{.Type}}Filterer: {{.Type}}Filterer{contract: contract} }, nil
 		}
+
+		// Deploy{{.Type}}AndWait deploys a new Ethereum contract and waits until the deployment
+		// is mined, binding an instance of {{.Type}} to it.
+		func Deploy{{.Type}}AndWait(ctx context.Context, auth *bind.TransactOpts, backend bind.ContractDeployBackend {{range .Constructor.Inputs}}, {{.Name}} {{bindtype .Type}}{{end}}) (common.Address, *types.Transaction, *{{.Type}}, error) {
+		  address, tx, contract, err := Deploy{{.Type}}(auth, backend {{range .Constructor.Inputs}}, {{.Name}}{{end}})
+		  if err != nil {
+		    return common.Address{}, nil, nil, err
+		  }
+		  if _, err := bind.WaitDeployed(ctx, backend, tx); err != nil {
+		    return address, tx, nil, err
+		  }
+		  return address, tx, contract, nil
+		}
 	{{end}}
 
 	// {{.Type}} is an auto generated Go binding around an Ethereum contract.
@@ -130,6 +168,15 @@ package {{.Package}}
 	  TransactOpts bind.TransactOpts    // Transaction auth options to use throughout this session
 	}
 
+	{{if $.Batch}}
+		// {{.Type}}Batch is an auto generated read-only Go binding around an Ethereum contract,
+		// queueing the calls into a batch executed in a single round trip.
+		type {{.Type}}Batch struct {
+		  contract *bind.BoundContract // Generic contract wrapper for the low level calls
+		  batch    *bind.CallBatch     // Batch to queue the calls into
+		}
+	{{end}}
+
 	// {{.Type}}Raw is an auto generated low-level Go binding around an Ethereum contract.
 	type {{.Type}}Raw struct {
 	  Contract *{{.Type}} // Generic contract binding to access the raw methods on
@@ -181,6 +228,19 @@ package {{.Package}}
  	  return &{{.Type}}Filterer{contract: contract}, nil
  	}
 
+	{{if $.Batch}}
+		// New{{.Type}}Batch creates a new batched read-only instance of {{.Type}}, bound to a specific
+		// deployed contract. The calls are queued into the given batch, which may be shared
+		// between contracts.
+		func New{{.Type}}Batch(address common.Address, batch *bind.CallBatch) (*{{.Type}}Batch, error) {
+		  contract, err := bind{{.Type}}(address, nil, nil, nil)
+		  if err != nil {
+		    return nil, err
+		  }
+		  return &{{.Type}}Batch{contract: contract, batch: batch}, nil
+		}
+	{{end}}
+
 	// bind{{.Type}} binds a generic wrapper to an already deployed contract.
 	func bind{{.Type}}(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
 	  parsed, err := abi.JSON(strings.NewReader({{.Type}}ABI))
@@ -261,6 +321,28 @@ package {{.Package}}
 		func (_{{$contract.Type}} *{{$contract.Type}}CallerSession) {{.Normalized.Name}}({{range $i, $_ := .Normalized.Inputs}}{{if ne $i 0}},{{end}} {{.Name}} {{bindtype .Type}} {{end}}) ({{if .Structured}}struct{ {{range .Normalized.Outputs}}{{.Name}} {{bindtype .Type}};{{end}} }, {{else}} {{range .Normalized.Outputs}}{{bindtype .Type}},{{end}} {{end}} error) {
 		  return _{{$contract.Type}}.Contract.{{.Normalized.Name}}(&_{{$contract.Type}}.CallOpts {{range .Normalized.Inputs}}, {{.Name}}{{end}})
 		}
+
+		{{if $.Batch}}
+			// {{.Normalized.Name}} queues a free data retrieval call binding the contract method 0x{{printf "%x" .Original.Id}}
+			// into the batch. The returned values are only filled in once the batch is executed.
+			//
+			// Solidity: {{.Original.String}}
+			func (_{{$contract.Type}} *{{$contract.Type}}Batch) {{.Normalized.Name}}(opts *bind.CallOpts {{range .Normalized.Inputs}}, {{.Name}} {{bindtype .Type}} {{end}}) ({{if .Structured}}*struct{ {{range .Normalized.Outputs}}{{.Name}} {{bindtype .Type}};{{end}} },{{else}}{{range .Normalized.Outputs}}*{{bindtype .Type}},{{end}}{{end}} error) {
+				{{if .Structured}}ret := new(struct{
+					{{range .Normalized.Outputs}}{{.Name}} {{bindtype .Type}}
+					{{end}}
+				}){{else}}var (
+					{{range $i, $_ := .Normalized.Outputs}}ret{{$i}} = new({{bindtype .Type}})
+					{{end}}
+				){{end}}
+				out := {{if .Structured}}ret{{else}}{{if eq (len .Normalized.Outputs) 1}}ret0{{else}}&[]interface{}{
+					{{range $i, $_ := .Normalized.Outputs}}ret{{$i}},
+					{{end}}
+				}{{end}}{{end}}
+				err := _{{$contract.Type}}.contract.BatchCall(_{{$contract.Type}}.batch, opts, out, "{{.Original.Name}}" {{range .Normalized.Inputs}}, {{.Name}}{{end}})
+				return {{if .Structured}}ret,{{else}}{{range $i, $_ := .Normalized.Outputs}}ret{{$i}},{{end}}{{end}} err
+			}
+		{{end}}
 	{{end}}
 
 	{{range .Transacts}}
@@ -520,3 +602,100 @@ import org.ethereum.geth.internal.*;
 	}
 {{end}}
 `
+
+// tmplSourceTypeScript is the TypeScript source template use to generate the
+// contract type definitions and encoders based on.
+const tmplSourceTypeScript = `
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+// AbiCoder is the ABI encoder the bindings delegate the packing and unpacking
+// of values to, e.g. the AbiCoder of ethers.js.
+export interface AbiCoder {
+  encode(types: string[], values: any[]): string;
+  decode(types: string[], data: string): any[];
+}
+
+// BigNumberish is an integer which may not fit into a number, either as a
+// decimal or hex string or as a big number of the used library.
+export type BigNumberish = string | number | { toString(): string };
+
+// Log is a contract log as returned by the JSON-RPC API.
+export interface Log {
+  topics: string[];
+  data: string;
+}
+
+{{range .Structs}}
+// {{.Name}} is an auto generated TypeScript binding around a user-defined struct.
+export interface {{.Name}} {
+{{range .Fields}}  {{.Name}}: {{.Type}};
+{{end}}}
+{{end}}
+
+{{range $contract := .Contracts}}
+// {{.Type}}ABI is the input ABI used to generate the binding from.
+export const {{.Type}}ABI = "{{.InputABI}}";
+
+{{if .InputBin}}
+// {{.Type}}Bin is the compiled bytecode used for deploying new contracts.
+export const {{.Type}}Bin = "{{hexprefix .InputBin}}";
+
+// encode{{.Type}}Deploy packs the bytecode and the constructor arguments of a
+// {{.Type}} contract deployment.
+export function encode{{.Type}}Deploy(coder: AbiCoder{{range $i, $_ := .Constructor.Inputs}}, {{fieldname .Name $i}}: {{bindtype .Type}}{{end}}): string {
+  return {{.Type}}Bin + coder.encode([{{range $i, $_ := .Constructor.Inputs}}{{if $i}}, {{end}}"{{abitype .Type}}"{{end}}], [{{range $i, $_ := .Constructor.Inputs}}{{if $i}}, {{end}}{{fieldname .Name $i}}{{end}}]).slice(2);
+}
+{{end}}
+
+{{range .Calls}}
+// encode{{$contract.Type}}{{.Normalized.Name}} packs a call to the contract method 0x{{printf "%x" .Original.Id}}.
+//
+// Solidity: {{.Original.String}}
+export function encode{{$contract.Type}}{{.Normalized.Name}}(coder: AbiCoder{{range .Normalized.Inputs}}, {{.Name}}: {{bindtype .Type}}{{end}}): string {
+  return "0x{{printf "%x" .Original.Id}}" + coder.encode([{{range $i, $_ := .Normalized.Inputs}}{{if $i}}, {{end}}"{{abitype .Type}}"{{end}}], [{{range $i, $_ := .Normalized.Inputs}}{{if $i}}, {{end}}{{.Name}}{{end}}]).slice(2);
+}
+{{if .Original.Outputs}}
+// decode{{$contract.Type}}{{.Normalized.Name}} unpacks the result of a call to the contract method 0x{{printf "%x" .Original.Id}}.
+//
+// Solidity: {{.Original.String}}
+export function decode{{$contract.Type}}{{.Normalized.Name}}(coder: AbiCoder, data: string): {{if .Structured}}{ {{range .Original.Outputs}}{{.Name}}: {{bindtype .Type}}; {{end}}}{{else if eq (len .Original.Outputs) 1}}{{range .Original.Outputs}}{{bindtype .Type}}{{end}}{{else}}[{{range $i, $_ := .Original.Outputs}}{{if $i}}, {{end}}{{bindtype .Type}}{{end}}]{{end}} {
+  const values = coder.decode([{{range $i, $_ := .Original.Outputs}}{{if $i}}, {{end}}"{{abitype .Type}}"{{end}}], data);
+  return {{if .Structured}}{ {{range $i, $_ := .Original.Outputs}}{{if $i}}, {{end}}{{.Name}}: values[{{$i}}]{{end}} }{{else if eq (len .Original.Outputs) 1}}values[0]{{else}}[{{range $i, $_ := .Original.Outputs}}{{if $i}}, {{end}}values[{{$i}}]{{end}}]{{end}};
+}
+{{end}}
+{{end}}
+
+{{range .Transacts}}
+// encode{{$contract.Type}}{{.Normalized.Name}} packs a transaction to the contract method 0x{{printf "%x" .Original.Id}}.
+//
+// Solidity: {{.Original.String}}
+export function encode{{$contract.Type}}{{.Normalized.Name}}(coder: AbiCoder{{range .Normalized.Inputs}}, {{.Name}}: {{bindtype .Type}}{{end}}): string {
+  return "0x{{printf "%x" .Original.Id}}" + coder.encode([{{range $i, $_ := .Normalized.Inputs}}{{if $i}}, {{end}}"{{abitype .Type}}"{{end}}], [{{range $i, $_ := .Normalized.Inputs}}{{if $i}}, {{end}}{{.Name}}{{end}}]).slice(2);
+}
+{{end}}
+
+{{range $event := .Events}}
+// {{$contract.Type}}{{.Normalized.Name}} represents a {{.Normalized.Name}} event raised by the {{$contract.Type}} contract.
+export interface {{$contract.Type}}{{.Normalized.Name}} {
+{{range $i, $_ := .Normalized.Inputs}}  {{fieldname .Name $i}}: {{if .Indexed}}{{bindtopictype .Type}}{{else}}{{bindtype .Type}}{{end}};
+{{end}}}
+
+// {{$contract.Type}}{{.Normalized.Name}}Topic is the topic of the {{.Normalized.Name}} event.
+//
+// Solidity: {{.Original.String}}
+export const {{$contract.Type}}{{.Normalized.Name}}Topic = "{{.Original.Id.Hex}}";
+
+// decode{{$contract.Type}}{{.Normalized.Name}}Event unpacks a log of the {{.Normalized.Name}} event.
+export function decode{{$contract.Type}}{{.Normalized.Name}}Event(coder: AbiCoder, log: Log): {{$contract.Type}}{{.Normalized.Name}} {
+  if (log.topics.length !== {{topiccount .Normalized.Inputs}} || log.topics[0].toLowerCase() !== {{$contract.Type}}{{.Normalized.Name}}Topic) {
+    throw new Error("log is not a {{.Normalized.Name}} event");
+  }
+  const data = coder.decode([{{range $i, $e := unindexed .Normalized.Inputs}}{{if $i}}, {{end}}"{{abitype .Type}}"{{end}}], log.data);
+  return {
+{{range $i, $_ := .Normalized.Inputs}}    {{fieldname .Name $i}}: {{tsdecode $event.Normalized.Inputs $i}},
+{{end}}  };
+}
+{{end}}
+{{end}}
+`
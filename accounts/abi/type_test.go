@@ -97,7 +97,7 @@ func TestTypeRegexp(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		typ, err := NewType(tt.blob)
+		typ, err := NewType(tt.blob, nil)
 		if err != nil {
 			t.Errorf("type %q: failed to parse type string: %v", tt.blob, err)
 		}
@@ -253,7 +253,7 @@ func TestTypeCheck(t *testing.T) {
 		{"address", [20]byte{}, ""},
 		{"address", common.Address{}, ""},
 	} {
-		typ, err := NewType(test.typ)
+		typ, err := NewType(test.typ, nil)
 		if err != nil && len(test.err) == 0 {
 			t.Fatal("unexpected parse error:", err)
 		} else if err != nil && len(test.err) != 0 {
@@ -278,3 +278,42 @@ func TestTypeCheck(t *testing.T) {
 		}
 	}
 }
+
+// Tests that tuple types are parsed into structs with their canonical signature.
+func TestTupleType(t *testing.T) {
+	components := []ArgumentMarshaling{
+		{Name: "amount", Type: "uint256"},
+		{Name: "_owner", Type: "address"},
+		{Name: "tags", Type: "string[]"},
+		{Name: "inner", Type: "tuple[2]", Components: []ArgumentMarshaling{{Name: "flag", Type: "bool"}}},
+	}
+	typ, err := NewType("tuple[]", components)
+	if err != nil {
+		t.Fatalf("failed to parse tuple type: %v", err)
+	}
+	if typ.T != SliceTy || typ.Elem.T != TupleTy {
+		t.Fatalf("type mismatch: have %d of %d, want slice of tuple", typ.T, typ.Elem.T)
+	}
+	if want := "(uint256,address,string[],(bool)[2])[]"; typ.String() != want {
+		t.Errorf("signature mismatch: have %s, want %s", typ.String(), want)
+	}
+	want := reflect.TypeOf([]struct {
+		Amount *big.Int
+		Owner  common.Address
+		Tags   []string
+		Inner  [2]struct{ Flag bool }
+	}{})
+	if typ.Type != want {
+		t.Errorf("reflect type mismatch: have %v, want %v", typ.Type, want)
+	}
+	if !reflect.DeepEqual(typ.Elem.TupleRawNames, []string{"amount", "_owner", "tags", "inner"}) {
+		t.Errorf("raw names mismatch: have %v", typ.Elem.TupleRawNames)
+	}
+	// Anonymous and colliding fields cannot be mapped to a struct
+	if _, err := NewType("tuple", []ArgumentMarshaling{{Name: "", Type: "uint256"}}); err == nil {
+		t.Errorf("anonymous tuple field accepted")
+	}
+	if _, err := NewType("tuple", []ArgumentMarshaling{{Name: "a", Type: "uint256"}, {Name: "_a", Type: "bool"}}); err == nil {
+		t.Errorf("colliding tuple fields accepted")
+	}
+}
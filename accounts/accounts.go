@@ -128,6 +128,18 @@ type Wallet interface {
 	SignTxWithPassphrase(account Account, passphrase string, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
 }
 
+// TypedDataSigner is an optional interface for wallets able to sign EIP-712
+// typed data with more insight than signing its opaque hash, e.g. hardware
+// wallets displaying the domain and message hashes to the user.
+type TypedDataSigner interface {
+	// SignTypedData requests the wallet to sign the given typed data message,
+	// returning the signature of its EIP-712 digest.
+	//
+	// It looks up the account specified either solely via its address contained within,
+	// or optionally with the aid of any location metadata from the embedded URL field.
+	SignTypedData(account Account, typedData *TypedData) ([]byte, error)
+}
+
 // Backend is a "wallet provider" that may contain a batch of accounts they can
 // sign transactions with and upon request, do so.
 type Backend interface {
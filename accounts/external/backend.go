@@ -0,0 +1,245 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package external implements an account backend delegating the signing to an
+// external signer process over its JSON-RPC API.
+package external
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// URLScheme is the protocol scheme prefixing the URLs of external signers.
+const URLScheme = "extapi"
+
+// ExternalBackend is an account backend with a single wallet, the external
+// signer it is connected to.
+type ExternalBackend struct {
+	signers []accounts.Wallet
+}
+
+// NewExternalBackend connects to the external signer listening on the given
+// endpoint, which may be an IPC path or an HTTP URL.
+func NewExternalBackend(endpoint string) (*ExternalBackend, error) {
+	signer, err := NewExternalSigner(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &ExternalBackend{signers: []accounts.Wallet{signer}}, nil
+}
+
+// Wallets implements accounts.Backend, returning the external signer.
+func (eb *ExternalBackend) Wallets() []accounts.Wallet {
+	return eb.signers
+}
+
+// Subscribe implements accounts.Backend. As the external signer is connected
+// once on startup and never departs, no events are ever fired.
+func (eb *ExternalBackend) Subscribe(sink chan<- accounts.WalletEvent) event.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	})
+}
+
+// ExternalSigner is a wallet whose accounts are held and signed with by an
+// external signer. Every request is approved by the signer's UI, so no
+// passphrases are ever handled by the node.
+type ExternalSigner struct {
+	client   *rpc.Client
+	endpoint string
+
+	cacheMu  sync.RWMutex
+	cache    []accounts.Account // Accounts listed by the signer, nil if not yet fetched
+	cacheErr error              // Last error encountered while listing the accounts
+}
+
+// NewExternalSigner connects to the external signer at the given endpoint.
+func NewExternalSigner(endpoint string) (*ExternalSigner, error) {
+	client, err := rpc.Dial(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to external signer: %v", err)
+	}
+	return newExternalSigner(client, endpoint), nil
+}
+
+// newExternalSigner creates a wallet on top of an already established client.
+func newExternalSigner(client *rpc.Client, endpoint string) *ExternalSigner {
+	return &ExternalSigner{client: client, endpoint: endpoint}
+}
+
+// URL implements accounts.Wallet, returning the endpoint of the signer.
+func (api *ExternalSigner) URL() accounts.URL {
+	return accounts.URL{Scheme: URLScheme, Path: api.endpoint}
+}
+
+// Status implements accounts.Wallet, returning the API version of the signer.
+func (api *ExternalSigner) Status() (string, error) {
+	var version string
+	if err := api.client.Call(&version, "account_version"); err != nil {
+		return "Failed", err
+	}
+	return fmt.Sprintf("Ok, version %s", version), nil
+}
+
+// Open implements accounts.Wallet. The connection is established when creating
+// the wallet, so this method is a noop.
+func (api *ExternalSigner) Open(passphrase string) error {
+	return nil
+}
+
+// Close implements accounts.Wallet. The connection is kept for the lifetime of
+// the node, so this method is a noop.
+func (api *ExternalSigner) Close() error {
+	return nil
+}
+
+// Accounts implements accounts.Wallet, returning the accounts the signer agreed
+// to list. The list is requested only once, as it has to be approved by the
+// signer's UI.
+func (api *ExternalSigner) Accounts() []accounts.Account {
+	api.cacheMu.RLock()
+	cache := api.cache
+	api.cacheMu.RUnlock()
+
+	if cache != nil {
+		return cache
+	}
+	api.cacheMu.Lock()
+	defer api.cacheMu.Unlock()
+
+	if api.cache != nil {
+		return api.cache
+	}
+	var addresses []common.Address
+	if err := api.client.Call(&addresses, "account_list"); err != nil {
+		if api.cacheErr == nil || api.cacheErr.Error() != err.Error() {
+			log.Warn("Failed to list external signer accounts", "err", err)
+		}
+		api.cacheErr = err
+		return nil
+	}
+	api.cache = make([]accounts.Account, 0, len(addresses))
+	for _, addr := range addresses {
+		api.cache = append(api.cache, accounts.Account{
+			Address: addr,
+			URL:     api.URL(),
+		})
+	}
+	api.cacheErr = nil
+	return api.cache
+}
+
+// Contains implements accounts.Wallet, returning whether the signer listed a
+// particular account.
+func (api *ExternalSigner) Contains(account accounts.Account) bool {
+	for _, acc := range api.Accounts() {
+		if acc.Address == account.Address && (account.URL == (accounts.URL{}) || account.URL == api.URL()) {
+			return true
+		}
+	}
+	return false
+}
+
+// Derive implements accounts.Wallet, but is not supported by external signers.
+func (api *ExternalSigner) Derive(path accounts.DerivationPath, pin bool) (accounts.Account, error) {
+	return accounts.Account{}, accounts.ErrNotSupported
+}
+
+// SelfDerive implements accounts.Wallet, but is a noop for external signers as
+// accounts are managed by the signer itself.
+func (api *ExternalSigner) SelfDerive(base accounts.DerivationPath, chain ethereum.ChainStateReader) {
+}
+
+// SignHash implements accounts.Wallet, but is not supported by external signers
+// as they only sign messages they can display to the user, not opaque hashes.
+func (api *ExternalSigner) SignHash(account accounts.Account, hash []byte) ([]byte, error) {
+	return nil, accounts.ErrNotSupported
+}
+
+// sendTxArgs is the transaction accepted by the signer's account_signTransaction.
+type sendTxArgs struct {
+	From     common.Address  `json:"from"`
+	To       *common.Address `json:"to"`
+	Gas      hexutil.Uint64  `json:"gas"`
+	GasPrice hexutil.Big     `json:"gasPrice"`
+	Value    hexutil.Big     `json:"value"`
+	Nonce    hexutil.Uint64  `json:"nonce"`
+	Input    hexutil.Bytes   `json:"input"`
+}
+
+// signTransactionResult is the response of the signer's account_signTransaction.
+type signTransactionResult struct {
+	Raw hexutil.Bytes      `json:"raw"`
+	Tx  *types.Transaction `json:"tx"`
+}
+
+// SignTx implements accounts.Wallet, requesting the signer to sign the given
+// transaction. The signed transaction is verified to originate from the given
+// account on the given chain, as the signer's UI may have modified it.
+func (api *ExternalSigner) SignTx(account accounts.Account, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	args := &sendTxArgs{
+		From:     account.Address,
+		To:       tx.To(),
+		Gas:      hexutil.Uint64(tx.Gas()),
+		GasPrice: hexutil.Big(*tx.GasPrice()),
+		Value:    hexutil.Big(*tx.Value()),
+		Nonce:    hexutil.Uint64(tx.Nonce()),
+		Input:    tx.Data(),
+	}
+	var result signTransactionResult
+	if err := api.client.Call(&result, "account_signTransaction", args); err != nil {
+		return nil, err
+	}
+	if result.Tx == nil {
+		return nil, fmt.Errorf("external signer returned no transaction")
+	}
+	var signer types.Signer = types.HomesteadSigner{}
+	if chainID != nil {
+		signer = types.NewEIP155Signer(chainID)
+	}
+	sender, err := types.Sender(signer, result.Tx)
+	if err != nil {
+		return nil, fmt.Errorf("external signer returned invalid transaction: %v", err)
+	}
+	if sender != account.Address {
+		return nil, fmt.Errorf("external signer returned transaction from %x, want %x", sender, account.Address)
+	}
+	return result.Tx, nil
+}
+
+// SignHashWithPassphrase implements accounts.Wallet, but is not supported by
+// external signers.
+func (api *ExternalSigner) SignHashWithPassphrase(account accounts.Account, passphrase string, hash []byte) ([]byte, error) {
+	return nil, accounts.ErrNotSupported
+}
+
+// SignTxWithPassphrase implements accounts.Wallet, but is not supported by
+// external signers as the passphrase is provided through the signer's UI.
+func (api *ExternalSigner) SignTxWithPassphrase(account accounts.Account, passphrase string, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return nil, accounts.ErrNotSupported
+}
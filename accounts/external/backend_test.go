@@ -0,0 +1,146 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package external
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// SignerService is a minimal external signer signing with a single key, or with
+// a different one if requested to misbehave.
+type SignerService struct {
+	key     *ecdsa.PrivateKey
+	other   *ecdsa.PrivateKey
+	chainID *big.Int
+	deny    bool
+	lists   int
+}
+
+func (s *SignerService) Version() string {
+	return "1.0.0"
+}
+
+func (s *SignerService) List() ([]common.Address, error) {
+	s.lists++
+	return []common.Address{crypto.PubkeyToAddress(s.key.PublicKey)}, nil
+}
+
+// TxArgs is the transaction accepted by the signer, exported for the RPC server.
+type TxArgs sendTxArgs
+
+// TxResult is the signed transaction returned by the signer, exported for the RPC
+// server.
+type TxResult signTransactionResult
+
+func (s *SignerService) SignTransaction(args TxArgs) (*TxResult, error) {
+	if s.deny {
+		return nil, errors.New("request denied")
+	}
+	tx := types.NewTransaction(uint64(args.Nonce), *args.To, args.Value.ToInt(), uint64(args.Gas), args.GasPrice.ToInt(), args.Input)
+
+	key := s.key
+	if s.other != nil {
+		key = s.other
+	}
+	signed, err := types.SignTx(tx, types.NewEIP155Signer(s.chainID), key)
+	if err != nil {
+		return nil, err
+	}
+	raw, _ := rlp.EncodeToBytes(signed)
+	return &TxResult{Raw: raw, Tx: signed}, nil
+}
+
+func newTestExternalSigner(t *testing.T) (*ExternalSigner, *SignerService) {
+	key, _ := crypto.GenerateKey()
+	service := &SignerService{key: key, chainID: big.NewInt(1337)}
+
+	server := rpc.NewServer()
+	if err := server.RegisterName("account", service); err != nil {
+		t.Fatalf("failed to register signer service: %v", err)
+	}
+	return newExternalSigner(rpc.DialInProc(server), "inproc"), service
+}
+
+func TestExternalSignerAccounts(t *testing.T) {
+	wallet, service := newTestExternalSigner(t)
+
+	if status, err := wallet.Status(); err != nil || status != "Ok, version 1.0.0" {
+		t.Fatalf("status mismatch: have %q/%v", status, err)
+	}
+	addr := crypto.PubkeyToAddress(service.key.PublicKey)
+	for i := 0; i < 2; i++ {
+		accs := wallet.Accounts()
+		if len(accs) != 1 || accs[0].Address != addr {
+			t.Fatalf("accounts mismatch: have %v, want [%x]", accs, addr)
+		}
+		if accs[0].URL != (accounts.URL{Scheme: URLScheme, Path: "inproc"}) {
+			t.Fatalf("account URL mismatch: have %v", accs[0].URL)
+		}
+	}
+	if service.lists != 1 {
+		t.Errorf("account listing not cached: have %d requests, want 1", service.lists)
+	}
+	if !wallet.Contains(accounts.Account{Address: addr}) {
+		t.Errorf("listed account not contained")
+	}
+	if wallet.Contains(accounts.Account{Address: common.Address{1}}) {
+		t.Errorf("unlisted account contained")
+	}
+}
+
+func TestExternalSignerSignTx(t *testing.T) {
+	wallet, service := newTestExternalSigner(t)
+
+	account := accounts.Account{Address: crypto.PubkeyToAddress(service.key.PublicKey)}
+	tx := types.NewTransaction(1, common.Address{2}, big.NewInt(100), 21000, big.NewInt(1), []byte{0xca, 0xfe})
+
+	signed, err := wallet.SignTx(account, tx, service.chainID)
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	signer := types.NewEIP155Signer(service.chainID)
+	if signer.Hash(signed) != signer.Hash(tx) {
+		t.Errorf("signed transaction mismatch")
+	}
+	// Ensure transactions signed for a different chain or account are rejected
+	if _, err := wallet.SignTx(account, tx, big.NewInt(1)); err == nil {
+		t.Errorf("transaction for different chain accepted")
+	}
+	service.other, _ = crypto.GenerateKey()
+	if _, err := wallet.SignTx(account, tx, service.chainID); err == nil {
+		t.Errorf("transaction from different account accepted")
+	}
+	// Ensure denials are propagated
+	service.deny = true
+	if _, err := wallet.SignTx(account, tx, service.chainID); err == nil {
+		t.Errorf("denied transaction signed")
+	}
+	// Ensure passphrase based signing is refused
+	if _, err := wallet.SignTxWithPassphrase(account, "", tx, service.chainID); err != accounts.ErrNotSupported {
+		t.Errorf("error mismatch for passphrase signing: have %v, want %v", err, accounts.ErrNotSupported)
+	}
+}
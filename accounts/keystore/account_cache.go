@@ -65,6 +65,7 @@ func (err *AmbiguousAddrError) Error() string {
 // accountCache is a live index of all accounts in the keystore.
 type accountCache struct {
 	keydir   string
+	store    SecretStore // Secret store polled instead of the key directory, if set
 	watcher  *watcher
 	mu       sync.Mutex
 	all      accountsByURL
@@ -85,6 +86,17 @@ func newAccountCache(keydir string) (*accountCache, chan struct{}) {
 	return ac, ac.notify
 }
 
+// newSecretAccountCache creates an account cache over the keys persisted in a
+// secret store. As stores can't notify of changes, they are polled on reloads.
+func newSecretAccountCache(store SecretStore) (*accountCache, chan struct{}) {
+	ac := &accountCache{
+		store:  store,
+		byAddr: make(map[common.Address][]accounts.Account),
+		notify: make(chan struct{}, 1),
+	}
+	return ac, ac.notify
+}
+
 func (ac *accountCache) accounts() []accounts.Account {
 	ac.maybeReload()
 	ac.mu.Lock()
@@ -194,7 +206,7 @@ func (ac *accountCache) find(a accounts.Account) (accounts.Account, error) {
 func (ac *accountCache) maybeReload() {
 	ac.mu.Lock()
 
-	if ac.watcher.running {
+	if ac.watcher != nil && ac.watcher.running {
 		ac.mu.Unlock()
 		return // A watcher is running and will keep the cache up-to-date.
 	}
@@ -208,8 +220,10 @@ func (ac *accountCache) maybeReload() {
 			return // The cache was reloaded recently.
 		}
 	}
-	// No watcher running, start it.
-	ac.watcher.start()
+	// No watcher running, start it if the platform and storage supports it.
+	if ac.watcher != nil {
+		ac.watcher.start()
+	}
 	ac.throttle.Reset(minReloadInterval)
 	ac.mu.Unlock()
 	ac.scanAccounts()
@@ -217,7 +231,9 @@ func (ac *accountCache) maybeReload() {
 
 func (ac *accountCache) close() {
 	ac.mu.Lock()
-	ac.watcher.close()
+	if ac.watcher != nil {
+		ac.watcher.close()
+	}
 	if ac.throttle != nil {
 		ac.throttle.Stop()
 	}
@@ -231,6 +247,9 @@ func (ac *accountCache) close() {
 // scanAccounts checks if any changes have occurred on the filesystem, and
 // updates the account cache accordingly
 func (ac *accountCache) scanAccounts() error {
+	if ac.store != nil {
+		return ac.scanSecrets()
+	}
 	// Scan the entire folder metadata for file changes
 	creates, deletes, updates, err := ac.fileC.scan(ac.keydir)
 	if err != nil {
@@ -296,3 +315,68 @@ func (ac *accountCache) scanAccounts() error {
 	log.Trace("Handled keystore changes", "time", end.Sub(start))
 	return nil
 }
+
+// scanSecrets checks if any keys were added to or removed from the secret store,
+// and updates the account cache accordingly.
+func (ac *accountCache) scanSecrets() error {
+	names, err := ac.store.List()
+	if err != nil {
+		log.Debug("Failed to list keystore secrets", "err", err)
+		return err
+	}
+	// Gather the secrets currently known to the cache
+	ac.mu.Lock()
+	known := make(map[string]bool, len(ac.all))
+	for _, account := range ac.all {
+		known[account.URL.Path] = true
+	}
+	ac.mu.Unlock()
+
+	// Add all the new secrets and drop the ones gone missing
+	var (
+		start   = time.Now()
+		listed  = make(map[string]bool, len(names))
+		changed bool
+		key     struct {
+			Address string `json:"address"`
+		}
+	)
+	for _, name := range names {
+		listed[name] = true
+		if known[name] {
+			continue
+		}
+		secret, err := ac.store.Get(name)
+		if err != nil {
+			log.Trace("Failed to retrieve keystore secret", "name", name, "err", err)
+			continue
+		}
+		key.Address = ""
+		err = json.Unmarshal(secret, &key)
+		addr := common.HexToAddress(key.Address)
+		switch {
+		case err != nil:
+			log.Debug("Failed to decode keystore key", "name", name, "err", err)
+		case (addr == common.Address{}):
+			log.Debug("Failed to decode keystore key", "name", name, "err", "missing or zero address")
+		default:
+			ac.add(accounts.Account{Address: addr, URL: accounts.URL{Scheme: KeyStoreScheme, Path: name}})
+			changed = true
+		}
+	}
+	for name := range known {
+		if !listed[name] {
+			ac.deleteByFile(name)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	select {
+	case ac.notify <- struct{}{}:
+	default:
+	}
+	log.Trace("Handled keystore secret changes", "time", time.Since(start))
+	return nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// hardenedKeyStart is the index of the first hardened child key.
+const hardenedKeyStart = 0x80000000
+
+// errInvalidChildKey is returned if a derived key is outside of the valid range
+// of the curve. The probability of this is lower than 1 in 2^127.
+var errInvalidChildKey = errors.New("invalid derived key")
+
+// extendedKey is a BIP-32 private key along with its chain code.
+type extendedKey struct {
+	key       *big.Int
+	chainCode []byte
+}
+
+// newMasterKey generates the root extended key of an HD wallet from its seed.
+func newMasterKey(seed []byte) (*extendedKey, error) {
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+
+	key := new(big.Int).SetBytes(sum[:32])
+	if key.Sign() == 0 || key.Cmp(crypto.S256().Params().N) >= 0 {
+		return nil, errInvalidChildKey
+	}
+	return &extendedKey{key: key, chainCode: sum[32:]}, nil
+}
+
+// child derives the private child key with the given index, hardened if the
+// index is at least 2^31.
+func (k *extendedKey) child(index uint32) (*extendedKey, error) {
+	var data []byte
+	if index >= hardenedKeyStart {
+		data = append([]byte{0x00}, math.PaddedBigBytes(k.key, 32)...)
+	} else {
+		data = crypto.CompressPubkey(k.publicKey())
+	}
+	data = append(data, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(data[len(data)-4:], index)
+
+	mac := hmac.New(sha512.New, k.chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	n := crypto.S256().Params().N
+	tweak := new(big.Int).SetBytes(sum[:32])
+	if tweak.Cmp(n) >= 0 {
+		return nil, errInvalidChildKey
+	}
+	key := tweak.Add(tweak, k.key)
+	key.Mod(key, n)
+	if key.Sign() == 0 {
+		return nil, errInvalidChildKey
+	}
+	return &extendedKey{key: key, chainCode: sum[32:]}, nil
+}
+
+// publicKey returns the public half of the extended key.
+func (k *extendedKey) publicKey() *ecdsa.PublicKey {
+	x, y := crypto.S256().ScalarBaseMult(math.PaddedBigBytes(k.key, 32))
+	return &ecdsa.PublicKey{Curve: crypto.S256(), X: x, Y: y}
+}
+
+// privateKey returns the extended key as an ECDSA private key.
+func (k *extendedKey) privateKey() *ecdsa.PrivateKey {
+	return &ecdsa.PrivateKey{PublicKey: *k.publicKey(), D: new(big.Int).Set(k.key)}
+}
+
+// deriveKey derives the private key at the given path from the seed of an HD
+// wallet.
+func deriveKey(seed []byte, path accounts.DerivationPath) (*ecdsa.PrivateKey, error) {
+	key, err := newMasterKey(seed)
+	if err != nil {
+		return nil, err
+	}
+	for _, index := range path {
+		if key, err = key.child(index); err != nil {
+			return nil, err
+		}
+	}
+	return key.privateKey(), nil
+}
@@ -0,0 +1,643 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/pborman/uuid"
+)
+
+// HDKeyStoreType is the reflect type of an HD keystore backend.
+var HDKeyStoreType = reflect.TypeOf(&HDKeyStore{})
+
+// ErrDuplicateWallet is returned if a mnemonic is imported whose wallet already
+// exists in the keystore.
+var ErrDuplicateWallet = errors.New("wallet already exists")
+
+// hdKeyDir is the subdirectory of the keystore holding the HD wallet seeds. It is
+// skipped by the plain keystore scanning for key files.
+const hdKeyDir = "hd"
+
+// HDKeyStore manages the HD wallets of a keystore directory, each backed by the
+// encrypted seed of a BIP-39 mnemonic.
+type HDKeyStore struct {
+	keydir  string // Directory holding the HD wallet seed files
+	scryptN int    // Scrypt N parameter used to encrypt new seeds
+	scryptP int    // Scrypt P parameter used to encrypt new seeds
+
+	wallets     []accounts.Wallet       // HD wallets loaded from the seed files
+	updateFeed  event.Feed              // Event feed to notify wallet additions and openings
+	updateScope event.SubscriptionScope // Subscription scope tracking current live listeners
+
+	mu sync.RWMutex
+}
+
+// NewHDKeyStore creates an HD keystore for the given keystore directory, loading
+// all the wallets already present.
+func NewHDKeyStore(keydir string, scryptN, scryptP int) *HDKeyStore {
+	keydir, _ = filepath.Abs(keydir)
+	hks := &HDKeyStore{
+		keydir:  filepath.Join(keydir, hdKeyDir),
+		scryptN: scryptN,
+		scryptP: scryptP,
+	}
+	files, err := ioutil.ReadDir(hks.keydir)
+	if err != nil && !os.IsNotExist(err) {
+		log.Warn("Failed to read HD wallet directory", "path", hks.keydir, "err", err)
+	}
+	for _, fi := range files {
+		if skipKeyFile(fi) {
+			continue
+		}
+		path := filepath.Join(hks.keydir, fi.Name())
+		wallet, err := loadHDWallet(hks, path)
+		if err != nil {
+			log.Warn("Failed to load HD wallet", "path", path, "err", err)
+			continue
+		}
+		hks.wallets = append(hks.wallets, wallet)
+	}
+	return hks
+}
+
+// Wallets implements accounts.Backend, returning all the HD wallets in the
+// keystore directory.
+func (hks *HDKeyStore) Wallets() []accounts.Wallet {
+	hks.mu.RLock()
+	defer hks.mu.RUnlock()
+
+	cpy := make([]accounts.Wallet, len(hks.wallets))
+	copy(cpy, hks.wallets)
+	return cpy
+}
+
+// Subscribe implements accounts.Backend, creating an async subscription to
+// receive notifications on the addition of HD wallets.
+func (hks *HDKeyStore) Subscribe(sink chan<- accounts.WalletEvent) event.Subscription {
+	return hks.updateScope.Track(hks.updateFeed.Subscribe(sink))
+}
+
+// HasAddress reports whether an account with the given address is pinned to any
+// of the HD wallets.
+func (hks *HDKeyStore) HasAddress(addr common.Address) bool {
+	_, err := hks.find(addr)
+	return err == nil
+}
+
+// find retrieves the HD wallet the given address is pinned to.
+func (hks *HDKeyStore) find(addr common.Address) (*hdWallet, error) {
+	hks.mu.RLock()
+	defer hks.mu.RUnlock()
+
+	for _, wallet := range hks.wallets {
+		if wallet.Contains(accounts.Account{Address: addr}) {
+			return wallet.(*hdWallet), nil
+		}
+	}
+	return nil, ErrNoMatch
+}
+
+// Unlock unlocks the HD wallet the given account is pinned to indefinitely. As
+// its accounts are derived from the same seed, all of them are unlocked.
+func (hks *HDKeyStore) Unlock(a accounts.Account, passphrase string) error {
+	return hks.TimedUnlock(a, passphrase, 0)
+}
+
+// TimedUnlock unlocks the HD wallet the given account is pinned to, along with
+// all its accounts, verifying the passphrase even if it's already unlocked. The
+// wallet is locked again after the timeout, a zero timeout keeping it unlocked
+// until Lock is called. Unlocking again replaces the previous timeout.
+func (hks *HDKeyStore) TimedUnlock(a accounts.Account, passphrase string, timeout time.Duration) error {
+	wallet, err := hks.find(a.Address)
+	if err != nil {
+		return err
+	}
+	return wallet.unlock(passphrase, timeout)
+}
+
+// Lock locks the HD wallet the given address is pinned to, wiping its decrypted
+// seed from memory.
+func (hks *HDKeyStore) Lock(addr common.Address) error {
+	wallet, err := hks.find(addr)
+	if err != nil {
+		return err
+	}
+	return wallet.Close()
+}
+
+// NewMnemonic generates a new mnemonic and imports it as an HD wallet, encrypted
+// with the given passphrase.
+func (hks *HDKeyStore) NewMnemonic(passphrase string) (string, accounts.Wallet, error) {
+	mnemonic, err := NewMnemonic(DefaultMnemonicBits)
+	if err != nil {
+		return "", nil, err
+	}
+	wallet, err := hks.ImportMnemonic(mnemonic, passphrase)
+	if err != nil {
+		return "", nil, err
+	}
+	return mnemonic, wallet, nil
+}
+
+// ImportMnemonic stores the seed of the given mnemonic as a new HD wallet,
+// encrypted with the given passphrase. The first account on the default
+// derivation path is pinned to the wallet.
+func (hks *HDKeyStore) ImportMnemonic(mnemonic string, passphrase string) (accounts.Wallet, error) {
+	seed, err := MnemonicToSeed(mnemonic, "")
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(seed)
+
+	key, err := deriveKey(seed, accounts.DefaultBaseDerivationPath)
+	if err != nil {
+		return nil, err
+	}
+	account := crypto.PubkeyToAddress(key.PublicKey)
+	zeroKey(key)
+
+	hks.mu.Lock()
+	for _, wallet := range hks.wallets {
+		if accs := wallet.Accounts(); len(accs) > 0 && accs[0].Address == account {
+			hks.mu.Unlock()
+			return nil, ErrDuplicateWallet
+		}
+	}
+	hks.mu.Unlock()
+
+	cryptoStruct, err := encryptData(seed, passphrase, hks.scryptN, hks.scryptP)
+	if err != nil {
+		return nil, err
+	}
+	id := uuid.NewRandom()
+	path := filepath.Join(hks.keydir, fmt.Sprintf("UTC--%s--%s", toISO8601(time.Now().UTC()), id))
+
+	wallet := &hdWallet{
+		store:  hks,
+		url:    accounts.URL{Scheme: KeyStoreScheme, Path: path},
+		crypto: cryptoStruct,
+		id:     id.String(),
+		paths:  make(map[common.Address]accounts.DerivationPath),
+	}
+	wallet.pin(account, accounts.DefaultBaseDerivationPath)
+	if err := wallet.store.save(wallet); err != nil {
+		return nil, err
+	}
+	hks.mu.Lock()
+	hks.wallets = append(hks.wallets, wallet) // File names are timestamped, the list stays sorted
+	hks.mu.Unlock()
+
+	hks.updateFeed.Send(accounts.WalletEvent{Wallet: wallet, Kind: accounts.WalletArrived})
+	return wallet, nil
+}
+
+// hdAccountJSON is a pinned account of an HD wallet seed file.
+type hdAccountJSON struct {
+	Address common.Address `json:"address"`
+	Path    string         `json:"path"`
+}
+
+// hdWalletJSON is the on disk format of an HD wallet, the encrypted seed along
+// with the accounts pinned to the wallet.
+type hdWalletJSON struct {
+	Crypto   cryptoJSON      `json:"crypto"`
+	Id       string          `json:"id"`
+	Version  int             `json:"version"`
+	Accounts []hdAccountJSON `json:"accounts"`
+}
+
+// save writes the seed file of a wallet, including its current pinned accounts.
+func (hks *HDKeyStore) save(w *hdWallet) error {
+	w.stateLock.RLock()
+	enc := hdWalletJSON{
+		Crypto:   w.crypto,
+		Id:       w.id,
+		Version:  version,
+		Accounts: make([]hdAccountJSON, 0, len(w.accounts)),
+	}
+	for _, account := range w.accounts {
+		enc.Accounts = append(enc.Accounts, hdAccountJSON{
+			Address: account.Address,
+			Path:    w.paths[account.Address].String(),
+		})
+	}
+	w.stateLock.RUnlock()
+
+	content, err := json.Marshal(enc)
+	if err != nil {
+		return err
+	}
+	return writeKeyFile(w.url.Path, content)
+}
+
+// loadHDWallet reads the seed file of an HD wallet. The seed itself stays
+// encrypted until the wallet is opened.
+func loadHDWallet(hks *HDKeyStore, path string) (*hdWallet, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var dec hdWalletJSON
+	if err := json.Unmarshal(content, &dec); err != nil {
+		return nil, err
+	}
+	if dec.Version != version {
+		return nil, fmt.Errorf("version not supported: %v", dec.Version)
+	}
+	wallet := &hdWallet{
+		store:  hks,
+		url:    accounts.URL{Scheme: KeyStoreScheme, Path: path},
+		crypto: dec.Crypto,
+		id:     dec.Id,
+		paths:  make(map[common.Address]accounts.DerivationPath),
+	}
+	for _, account := range dec.Accounts {
+		path, err := accounts.ParseDerivationPath(account.Path)
+		if err != nil {
+			return nil, err
+		}
+		wallet.pin(account.Address, path)
+	}
+	return wallet, nil
+}
+
+// hdWallet implements the accounts.Wallet interface for a keystore HD wallet,
+// deriving its accounts from an encrypted seed.
+type hdWallet struct {
+	store  *HDKeyStore // Keystore where the wallet originates from
+	url    accounts.URL
+	crypto cryptoJSON // Encrypted seed of the wallet
+	id     string     // Random identifier of the seed file
+
+	seed     []byte                                     // Decrypted seed, nil while the wallet is locked
+	unlocks  uint64                                     // Number of unlocks, to expire only the latest one
+	expiry   *time.Timer                                // Timer locking the wallet again, if unlocked with a timeout
+	accounts []accounts.Account                         // Accounts pinned to the wallet
+	paths    map[common.Address]accounts.DerivationPath // Derivation paths of the pinned accounts
+
+	stateLock sync.RWMutex
+}
+
+// pin adds an account to the wallet, unless it's already pinned. It returns
+// whether the account was added.
+func (w *hdWallet) pin(address common.Address, path accounts.DerivationPath) bool {
+	w.stateLock.Lock()
+	defer w.stateLock.Unlock()
+
+	if _, ok := w.paths[address]; ok {
+		return false
+	}
+	w.accounts = append(w.accounts, accounts.Account{
+		Address: address,
+		URL:     accounts.URL{Scheme: w.url.Scheme, Path: fmt.Sprintf("%s/%s", w.url.Path, path)},
+	})
+	w.paths[address] = append(accounts.DerivationPath{}, path...)
+	return true
+}
+
+// URL implements accounts.Wallet, returning the path of the wallet's seed file.
+func (w *hdWallet) URL() accounts.URL {
+	return w.url
+}
+
+// Status implements accounts.Wallet, returning whether the seed of the wallet is
+// decrypted or not.
+func (w *hdWallet) Status() (string, error) {
+	w.stateLock.RLock()
+	defer w.stateLock.RUnlock()
+
+	if w.seed != nil {
+		return "Unlocked", nil
+	}
+	return "Locked", nil
+}
+
+// Open implements accounts.Wallet, decrypting the seed of the wallet with the
+// given passphrase, enabling account derivation and signing.
+func (w *hdWallet) Open(passphrase string) error {
+	w.stateLock.Lock()
+	if w.seed != nil {
+		w.stateLock.Unlock()
+		return accounts.ErrWalletAlreadyOpen
+	}
+	seed, err := decryptData(w.crypto, passphrase)
+	if err != nil {
+		w.stateLock.Unlock()
+		return err
+	}
+	w.seed = seed
+	w.stateLock.Unlock()
+
+	// Notify anyone listening for wallet events that a new wallet is available
+	w.store.updateFeed.Send(accounts.WalletEvent{Wallet: w, Kind: accounts.WalletOpened})
+	return nil
+}
+
+// Close implements accounts.Wallet, wiping the decrypted seed from memory.
+func (w *hdWallet) Close() error {
+	w.stateLock.Lock()
+	defer w.stateLock.Unlock()
+
+	w.lock()
+	return nil
+}
+
+// unlock decrypts the seed of the wallet with the given passphrase, even if it's
+// already open, and schedules the wallet to be locked again after the timeout,
+// if any.
+func (w *hdWallet) unlock(passphrase string, timeout time.Duration) error {
+	seed, err := decryptData(w.crypto, passphrase)
+	if err != nil {
+		return err
+	}
+	w.stateLock.Lock()
+	opened := w.seed == nil
+	w.lock()
+	w.seed = seed
+
+	w.unlocks++
+	if timeout > 0 {
+		unlock := w.unlocks
+		w.expiry = time.AfterFunc(timeout, func() {
+			w.stateLock.Lock()
+			defer w.stateLock.Unlock()
+
+			// Don't lock the wallet if it was unlocked again meanwhile
+			if w.unlocks == unlock {
+				w.lock()
+			}
+		})
+	}
+	w.stateLock.Unlock()
+
+	if opened {
+		w.store.updateFeed.Send(accounts.WalletEvent{Wallet: w, Kind: accounts.WalletOpened})
+	}
+	return nil
+}
+
+// lock wipes the decrypted seed from memory and cancels any pending expiry.
+//
+// Note, this method assumes the state lock is held!
+func (w *hdWallet) lock() {
+	if w.expiry != nil {
+		w.expiry.Stop()
+		w.expiry = nil
+	}
+	zeroBytes(w.seed)
+	w.seed = nil
+}
+
+// Accounts implements accounts.Wallet, returning the accounts pinned to the
+// wallet. They are available even while the wallet is locked.
+func (w *hdWallet) Accounts() []accounts.Account {
+	w.stateLock.RLock()
+	defer w.stateLock.RUnlock()
+
+	cpy := make([]accounts.Account, len(w.accounts))
+	copy(cpy, w.accounts)
+	return cpy
+}
+
+// Contains implements accounts.Wallet, returning whether a particular account is
+// or is not pinned into this wallet instance.
+func (w *hdWallet) Contains(account accounts.Account) bool {
+	w.stateLock.RLock()
+	defer w.stateLock.RUnlock()
+
+	_, exists := w.paths[account.Address]
+	return exists
+}
+
+// Derive implements accounts.Wallet, deriving the account at the given path
+// from the decrypted seed. If pin is set, the account is added to the wallet
+// and persisted into the seed file.
+func (w *hdWallet) Derive(path accounts.DerivationPath, pin bool) (accounts.Account, error) {
+	w.stateLock.RLock()
+	if w.seed == nil {
+		w.stateLock.RUnlock()
+		return accounts.Account{}, ErrLocked
+	}
+	key, err := deriveKey(w.seed, path)
+	w.stateLock.RUnlock()
+
+	if err != nil {
+		return accounts.Account{}, err
+	}
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	zeroKey(key)
+
+	account := accounts.Account{
+		Address: address,
+		URL:     accounts.URL{Scheme: w.url.Scheme, Path: fmt.Sprintf("%s/%s", w.url.Path, path)},
+	}
+	if pin && w.pin(address, path) {
+		if err := w.store.save(w); err != nil {
+			return accounts.Account{}, err
+		}
+	}
+	return account, nil
+}
+
+// SelfDerive implements accounts.Wallet, discovering the used accounts of the
+// wallet in the background, starting at the given base path. Accounts are
+// derived and pinned sequentially until the first one without any balance or
+// nonce, which is pinned too. The wallet needs to be open for the discovery.
+func (w *hdWallet) SelfDerive(base accounts.DerivationPath, chain ethereum.ChainStateReader) {
+	if chain == nil {
+		return
+	}
+	w.stateLock.RLock()
+	if w.seed == nil {
+		w.stateLock.RUnlock()
+		return
+	}
+	seed := common.CopyBytes(w.seed)
+	w.stateLock.RUnlock()
+
+	go w.selfDerive(seed, base, chain)
+}
+
+// selfDerive runs a single account discovery from the given base path.
+func (w *hdWallet) selfDerive(seed []byte, base accounts.DerivationPath, chain ethereum.ChainStateReader) {
+	defer zeroBytes(seed)
+
+	var (
+		path    = append(accounts.DerivationPath{}, base...)
+		changed = false
+	)
+	for {
+		key, err := deriveKey(seed, path)
+		if err != nil {
+			log.Warn("HD wallet account derivation failed", "url", w.url, "err", err)
+			break
+		}
+		address := crypto.PubkeyToAddress(key.PublicKey)
+		zeroKey(key)
+
+		// Check the account's status against the current chain state
+		balance, err := chain.BalanceAt(context.Background(), address, nil)
+		if err != nil {
+			log.Warn("HD wallet balance retrieval failed", "url", w.url, "err", err)
+			break
+		}
+		nonce, err := chain.NonceAt(context.Background(), address, nil)
+		if err != nil {
+			log.Warn("HD wallet nonce retrieval failed", "url", w.url, "err", err)
+			break
+		}
+		// Pin the account, stopping after the first empty one
+		if w.pin(address, path) {
+			log.Info("HD wallet discovered new account", "address", address, "path", path, "balance", balance, "nonce", nonce)
+			changed = true
+		}
+		if balance.Sign() == 0 && nonce == 0 {
+			break
+		}
+		path[len(path)-1]++
+	}
+	if changed {
+		if err := w.store.save(w); err != nil {
+			log.Warn("Failed to persist HD wallet accounts", "url", w.url, "err", err)
+		}
+	}
+}
+
+// signingKey derives the private key of a pinned account from the given seed.
+func (w *hdWallet) signingKey(seed []byte, account accounts.Account) (*ecdsa.PrivateKey, error) {
+	w.stateLock.RLock()
+	path, ok := w.paths[account.Address]
+	w.stateLock.RUnlock()
+
+	if !ok {
+		return nil, accounts.ErrUnknownAccount
+	}
+	return deriveKey(seed, path)
+}
+
+// unlockedKey derives the private key of a pinned account from the decrypted
+// seed of the wallet.
+func (w *hdWallet) unlockedKey(account accounts.Account) (*ecdsa.PrivateKey, error) {
+	w.stateLock.RLock()
+	defer w.stateLock.RUnlock()
+
+	if _, ok := w.paths[account.Address]; !ok {
+		return nil, accounts.ErrUnknownAccount
+	}
+	if w.seed == nil {
+		return nil, ErrLocked
+	}
+	return deriveKey(w.seed, w.paths[account.Address])
+}
+
+// passphraseKey decrypts the seed of the wallet with the given passphrase and
+// derives the private key of a pinned account from it.
+func (w *hdWallet) passphraseKey(account accounts.Account, passphrase string) (*ecdsa.PrivateKey, error) {
+	if !w.Contains(account) {
+		return nil, accounts.ErrUnknownAccount
+	}
+	seed, err := decryptData(w.crypto, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(seed)
+
+	return w.signingKey(seed, account)
+}
+
+// SignHash implements accounts.Wallet, signing the given hash with the key of
+// a pinned account. The wallet needs to be open.
+func (w *hdWallet) SignHash(account accounts.Account, hash []byte) ([]byte, error) {
+	key, err := w.unlockedKey(account)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroKey(key)
+
+	return crypto.Sign(hash, key)
+}
+
+// SignTx implements accounts.Wallet, signing the given transaction with the key
+// of a pinned account. The wallet needs to be open.
+func (w *hdWallet) SignTx(account accounts.Account, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	key, err := w.unlockedKey(account)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroKey(key)
+
+	return signTx(tx, chainID, key)
+}
+
+// SignHashWithPassphrase implements accounts.Wallet, signing the given hash with
+// the key of a pinned account, decrypting the seed with the given passphrase.
+func (w *hdWallet) SignHashWithPassphrase(account accounts.Account, passphrase string, hash []byte) ([]byte, error) {
+	key, err := w.passphraseKey(account, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroKey(key)
+
+	return crypto.Sign(hash, key)
+}
+
+// SignTxWithPassphrase implements accounts.Wallet, signing the given transaction
+// with the key of a pinned account, decrypting the seed with the given passphrase.
+func (w *hdWallet) SignTxWithPassphrase(account accounts.Account, passphrase string, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	key, err := w.passphraseKey(account, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroKey(key)
+
+	return signTx(tx, chainID, key)
+}
+
+// signTx signs a transaction with the EIP155 signer if a chain id is given, or
+// the homestead one otherwise.
+func signTx(tx *types.Transaction, chainID *big.Int, key *ecdsa.PrivateKey) (*types.Transaction, error) {
+	if chainID != nil {
+		return types.SignTx(tx, types.NewEIP155Signer(chainID), key)
+	}
+	return types.SignTx(tx, types.HomesteadSigner{}, key)
+}
+
+// zeroBytes overwrites a byte slice with zeros.
+func zeroBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,268 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+// Tests the mnemonic encoding against the BIP-39 test vectors.
+func TestMnemonic(t *testing.T) {
+	tests := []struct {
+		entropy  string
+		mnemonic string
+		seed     string // Seed with the "TREZOR" passphrase
+	}{
+		{
+			"00000000000000000000000000000000",
+			testMnemonic,
+			"c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+		},
+		{
+			"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+			"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo vote",
+			"dd48c104698c30cfe2b6142103248622fb7bb0ff692eebb00089b32d22484e1613912f0a5b694407be899ffd31ed3992c456cdf60f5d4564b8ba3f05a69890ad",
+		},
+	}
+	for i, tt := range tests {
+		entropy, _ := hex.DecodeString(tt.entropy)
+		if mnemonic := entropyToMnemonic(entropy); mnemonic != tt.mnemonic {
+			t.Errorf("test %d: mnemonic mismatch: have %q, want %q", i, mnemonic, tt.mnemonic)
+		}
+		decoded, err := mnemonicToEntropy(tt.mnemonic)
+		if err != nil || hex.EncodeToString(decoded) != tt.entropy {
+			t.Errorf("test %d: entropy mismatch: have %x/%v, want %s", i, decoded, err, tt.entropy)
+		}
+		seed, err := MnemonicToSeed(tt.mnemonic, "TREZOR")
+		if err != nil || hex.EncodeToString(seed) != tt.seed {
+			t.Errorf("test %d: seed mismatch: have %x/%v, want %s", i, seed, err, tt.seed)
+		}
+	}
+	// Ensure invalid mnemonics are rejected
+	for _, mnemonic := range []string{
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon etherium",
+	} {
+		if _, err := MnemonicToSeed(mnemonic, ""); err != ErrInvalidMnemonic {
+			t.Errorf("%q: error mismatch: have %v, want %v", mnemonic, err, ErrInvalidMnemonic)
+		}
+	}
+	// Ensure generated mnemonics round trip
+	mnemonic, err := NewMnemonic(DefaultMnemonicBits)
+	if err != nil {
+		t.Fatalf("failed to generate mnemonic: %v", err)
+	}
+	if words := strings.Fields(mnemonic); len(words) != 24 {
+		t.Errorf("mnemonic length mismatch: have %d words, want 24", len(words))
+	}
+	if _, err := mnemonicToEntropy(mnemonic); err != nil {
+		t.Errorf("generated mnemonic rejected: %v", err)
+	}
+}
+
+// Tests the key derivation against the BIP-32 test vectors and the addresses
+// derived by other Ethereum wallets.
+func TestDeriveKey(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	tests := []struct {
+		path string
+		key  string
+	}{
+		{"m/0'", "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea"},
+		{"m/0'/1", "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368"},
+		{"m/0'/1/2'", "cbce0d719ecf7431d88e6a89fa1483e02e35092af60c042b1df2ff59fa424dca"},
+	}
+	for _, tt := range tests {
+		path, err := accounts.ParseDerivationPath(tt.path)
+		if err != nil {
+			t.Fatalf("%s: failed to parse path: %v", tt.path, err)
+		}
+		key, err := deriveKey(seed, path)
+		if err != nil {
+			t.Errorf("%s: failed to derive key: %v", tt.path, err)
+			continue
+		}
+		if have := hex.EncodeToString(math.PaddedBigBytes(key.D, 32)); have != tt.key {
+			t.Errorf("%s: key mismatch: have %s, want %s", tt.path, have, tt.key)
+		}
+	}
+	seed, _ = MnemonicToSeed(testMnemonic, "")
+	key, err := deriveKey(seed, accounts.DefaultBaseDerivationPath)
+	if err != nil {
+		t.Fatalf("failed to derive key: %v", err)
+	}
+	if addr := crypto.PubkeyToAddress(key.PublicKey); addr != common.HexToAddress("0x9858EfFD232B4033E47d90003D41EC34EcaEda94") {
+		t.Errorf("address mismatch: have %x, want 0x9858EfFD232B4033E47d90003D41EC34EcaEda94", addr)
+	}
+}
+
+// Tests that an imported HD wallet can derive accounts and sign once opened, and
+// that the pinned accounts are persisted.
+func TestHDWallet(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eth-hdkeystore-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	hks := NewHDKeyStore(dir, veryLightScryptN, veryLightScryptP)
+	wallet, err := hks.ImportMnemonic(testMnemonic, "foo")
+	if err != nil {
+		t.Fatalf("failed to import mnemonic: %v", err)
+	}
+	if _, err := hks.ImportMnemonic(testMnemonic, "bar"); err != ErrDuplicateWallet {
+		t.Errorf("duplicate import error mismatch: have %v, want %v", err, ErrDuplicateWallet)
+	}
+	accs := wallet.Accounts()
+	if len(accs) != 1 || accs[0].Address != common.HexToAddress("0x9858EfFD232B4033E47d90003D41EC34EcaEda94") {
+		t.Fatalf("accounts mismatch: have %v", accs)
+	}
+	// Ensure a locked wallet can only sign with the passphrase
+	hash := crypto.Keccak256([]byte("hash"))
+	if _, err := wallet.SignHash(accs[0], hash); err != ErrLocked {
+		t.Errorf("locked signing error mismatch: have %v, want %v", err, ErrLocked)
+	}
+	if _, err := wallet.Derive(accounts.DefaultBaseDerivationPath, false); err != ErrLocked {
+		t.Errorf("locked derivation error mismatch: have %v, want %v", err, ErrLocked)
+	}
+	if _, err := wallet.SignHashWithPassphrase(accs[0], "bar", hash); err != ErrDecrypt {
+		t.Errorf("wrong passphrase error mismatch: have %v, want %v", err, ErrDecrypt)
+	}
+	if _, err := wallet.SignHashWithPassphrase(accs[0], "foo", hash); err != nil {
+		t.Errorf("failed to sign with passphrase: %v", err)
+	}
+	// Open the wallet and derive a new account
+	if err := wallet.Open("bar"); err != ErrDecrypt {
+		t.Errorf("wrong passphrase error mismatch: have %v, want %v", err, ErrDecrypt)
+	}
+	if err := wallet.Open("foo"); err != nil {
+		t.Fatalf("failed to open wallet: %v", err)
+	}
+	if status, _ := wallet.Status(); status != "Unlocked" {
+		t.Errorf("status mismatch: have %s, want Unlocked", status)
+	}
+	path := append(accounts.DerivationPath{}, accounts.DefaultBaseDerivationPath...)
+	path[len(path)-1] = 1
+
+	account, err := wallet.Derive(path, true)
+	if err != nil {
+		t.Fatalf("failed to derive account: %v", err)
+	}
+	if !wallet.Contains(account) {
+		t.Errorf("pinned account not contained")
+	}
+	tx := types.NewTransaction(0, common.Address{1}, big.NewInt(1), 21000, big.NewInt(1), nil)
+	signed, err := wallet.SignTx(account, tx, big.NewInt(1))
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	if sender, err := types.Sender(types.NewEIP155Signer(big.NewInt(1)), signed); err != nil || sender != account.Address {
+		t.Errorf("sender mismatch: have %x/%v, want %x", sender, err, account.Address)
+	}
+	if _, err := wallet.SignHash(accounts.Account{Address: common.Address{1}}, hash); err != accounts.ErrUnknownAccount {
+		t.Errorf("unknown account error mismatch: have %v, want %v", err, accounts.ErrUnknownAccount)
+	}
+	wallet.Close()
+	if _, err := wallet.SignTx(account, tx, big.NewInt(1)); err != ErrLocked {
+		t.Errorf("closed signing error mismatch: have %v, want %v", err, ErrLocked)
+	}
+	// Reload the keystore and ensure the pinned accounts are retained
+	wallets := NewHDKeyStore(dir, veryLightScryptN, veryLightScryptP).Wallets()
+	if len(wallets) != 1 || wallets[0].URL() != wallet.URL() {
+		t.Fatalf("reloaded wallets mismatch: have %v", wallets)
+	}
+	reloaded := wallets[0].Accounts()
+	if len(reloaded) != 2 || reloaded[0] != accs[0] || reloaded[1] != account {
+		t.Errorf("reloaded accounts mismatch: have %v, want [%v %v]", reloaded, accs[0], account)
+	}
+}
+
+// Tests that accounts derived from an HD wallet can be unlocked and locked by
+// address, and that timed unlocks expire.
+func TestHDKeyStoreUnlock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eth-hdkeystore-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	hks := NewHDKeyStore(dir, veryLightScryptN, veryLightScryptP)
+	wallet, err := hks.ImportMnemonic(testMnemonic, "foo")
+	if err != nil {
+		t.Fatalf("failed to import mnemonic: %v", err)
+	}
+	acc := wallet.Accounts()[0]
+	if !hks.HasAddress(acc.Address) {
+		t.Fatalf("derived account %x not found", acc.Address)
+	}
+	if hks.HasAddress(common.Address{1}) {
+		t.Errorf("foreign account found")
+	}
+	if err := hks.Unlock(accounts.Account{Address: common.Address{1}}, "foo"); err != ErrNoMatch {
+		t.Errorf("foreign unlock error mismatch: have %v, want %v", err, ErrNoMatch)
+	}
+	// Unlock indefinitely and ensure the account can sign until locked
+	hash := crypto.Keccak256([]byte("hash"))
+	if err := hks.Unlock(acc, "bar"); err != ErrDecrypt {
+		t.Errorf("wrong passphrase error mismatch: have %v, want %v", err, ErrDecrypt)
+	}
+	if err := hks.Unlock(acc, "foo"); err != nil {
+		t.Fatalf("failed to unlock: %v", err)
+	}
+	if _, err := wallet.SignHash(acc, hash); err != nil {
+		t.Errorf("failed to sign with unlocked account: %v", err)
+	}
+	if err := hks.Lock(acc.Address); err != nil {
+		t.Fatalf("failed to lock: %v", err)
+	}
+	if _, err := wallet.SignHash(acc, hash); err != ErrLocked {
+		t.Errorf("locked signing error mismatch: have %v, want %v", err, ErrLocked)
+	}
+	// Unlock with a timeout and ensure it expires, unless unlocked again
+	if err := hks.TimedUnlock(acc, "foo", 100*time.Millisecond); err != nil {
+		t.Fatalf("failed to unlock with timeout: %v", err)
+	}
+	if err := hks.TimedUnlock(acc, "foo", 0); err != nil {
+		t.Fatalf("failed to unlock indefinitely: %v", err)
+	}
+	time.Sleep(250 * time.Millisecond)
+	if _, err := wallet.SignHash(acc, hash); err != nil {
+		t.Errorf("replaced timeout locked the account: %v", err)
+	}
+	if err := hks.TimedUnlock(acc, "foo", 100*time.Millisecond); err != nil {
+		t.Fatalf("failed to unlock with timeout: %v", err)
+	}
+	time.Sleep(250 * time.Millisecond)
+	if _, err := wallet.SignHash(acc, hash); err != ErrLocked {
+		t.Errorf("expired signing error mismatch: have %v, want %v", err, ErrLocked)
+	}
+}
@@ -92,6 +92,15 @@ func NewPlaintextKeyStore(keydir string) *KeyStore {
 	return ks
 }
 
+// NewSecretKeyStore creates a keystore persisting its keys into the given secret
+// store instead of a key directory. The keys are encrypted the same way as with
+// NewKeyStore before being handed to the store.
+func NewSecretKeyStore(store SecretStore, scryptN, scryptP int) *KeyStore {
+	ks := &KeyStore{storage: &keyStoreSecret{store, scryptN, scryptP}}
+	ks.init("")
+	return ks
+}
+
 func (ks *KeyStore) init(keydir string) {
 	// Lock the mutex since the account cache might call back with events
 	ks.mu.Lock()
@@ -99,7 +108,11 @@ func (ks *KeyStore) init(keydir string) {
 
 	// Initialize the set of unlocked keys and the account cache
 	ks.unlocked = make(map[common.Address]*unlocked)
-	ks.cache, ks.changes = newAccountCache(keydir)
+	if secrets, ok := ks.storage.(*keyStoreSecret); ok {
+		ks.cache, ks.changes = newSecretAccountCache(secrets.store)
+	} else {
+		ks.cache, ks.changes = newAccountCache(keydir)
+	}
 
 	// TODO: In order for this finalizer to work, there must be no references
 	// to ks. addressCache doesn't keep a reference but unlocked keys do,
@@ -244,7 +257,11 @@ func (ks *KeyStore) Delete(a accounts.Account, passphrase string) error {
 	// The order is crucial here. The key is dropped from the
 	// cache after the file is gone so that a reload happening in
 	// between won't insert it into the cache again.
-	err = os.Remove(a.URL.Path)
+	if secrets, ok := ks.storage.(*keyStoreSecret); ok {
+		err = secrets.store.Delete(a.URL.Path)
+	} else {
+		err = os.Remove(a.URL.Path)
+	}
 	if err == nil {
 		ks.cache.delete(a)
 		ks.refreshWallets()
@@ -424,9 +441,12 @@ func (ks *KeyStore) Export(a accounts.Account, passphrase, newPassphrase string)
 		return nil, err
 	}
 	var N, P int
-	if store, ok := ks.storage.(*keyStorePassphrase); ok {
+	switch store := ks.storage.(type) {
+	case *keyStorePassphrase:
 		N, P = store.scryptN, store.scryptP
-	} else {
+	case *keyStoreSecret:
+		N, P = store.scryptN, store.scryptP
+	default:
 		N, P = StandardScryptN, StandardScryptP
 	}
 	return EncryptKey(key, newPassphrase, N, P)
@@ -0,0 +1,120 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto/randentropy"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// DefaultMnemonicBits is the entropy size of newly generated mnemonics, resulting
+// in 24 words.
+const DefaultMnemonicBits = 256
+
+var (
+	// ErrInvalidMnemonic is returned if a mnemonic contains unknown words or has
+	// an invalid length or checksum.
+	ErrInvalidMnemonic = errors.New("invalid mnemonic")
+
+	// bip39Index maps the words of the BIP-39 wordlist to their index.
+	bip39Index = make(map[string]int, len(bip39Words))
+)
+
+func init() {
+	for i, word := range bip39Words {
+		bip39Index[word] = i
+	}
+}
+
+// NewMnemonic generates a random BIP-39 mnemonic encoding the given number of
+// bits of entropy, which must be a multiple of 32 between 128 and 256.
+func NewMnemonic(bits int) (string, error) {
+	if bits%32 != 0 || bits < 128 || bits > 256 {
+		return "", fmt.Errorf("invalid mnemonic entropy size %d", bits)
+	}
+	return entropyToMnemonic(randentropy.GetEntropyCSPRNG(bits / 8)), nil
+}
+
+// entropyToMnemonic encodes the entropy, followed by the leading bits of its
+// hash as checksum, as words of 11 bits each.
+func entropyToMnemonic(entropy []byte) string {
+	var (
+		checksum = sha256.Sum256(entropy)
+		bits     = len(entropy)*8 + len(entropy)/4
+		words    = make([]string, bits/11)
+	)
+	data := append(append([]byte{}, entropy...), checksum[0])
+	for i := range words {
+		index := 0
+		for j := i * 11; j < (i+1)*11; j++ {
+			index = index<<1 | int(data[j/8]>>(7-uint(j%8))&1)
+		}
+		words[i] = bip39Words[index]
+	}
+	return strings.Join(words, " ")
+}
+
+// mnemonicToEntropy decodes the entropy from a mnemonic, verifying its checksum.
+func mnemonicToEntropy(mnemonic string) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+	if len(words)%3 != 0 || len(words) < 12 || len(words) > 24 {
+		return nil, ErrInvalidMnemonic
+	}
+	var (
+		bits = len(words) * 11
+		data = make([]byte, (bits+7)/8)
+	)
+	for i, word := range words {
+		index, ok := bip39Index[word]
+		if !ok {
+			return nil, ErrInvalidMnemonic
+		}
+		for j := 0; j < 11; j++ {
+			if index&(1<<uint(10-j)) != 0 {
+				pos := i*11 + j
+				data[pos/8] |= 1 << (7 - uint(pos%8))
+			}
+		}
+	}
+	entropy := data[:bits*32/33/8]
+	checksum := sha256.Sum256(entropy)
+
+	size := uint(len(entropy) / 4)
+	if data[len(entropy)]>>(8-size) != checksum[0]>>(8-size) {
+		return nil, ErrInvalidMnemonic
+	}
+	return entropy, nil
+}
+
+// MnemonicToSeed validates a BIP-39 mnemonic and derives the seed of the HD
+// wallet from it, salted with the optional mnemonic passphrase.
+//
+// Note, only the English wordlist is supported, so the mnemonic needs no Unicode
+// normalization. The passphrase is used as is.
+func MnemonicToSeed(mnemonic string, passphrase string) ([]byte, error) {
+	mnemonic = strings.Join(strings.Fields(mnemonic), " ")
+	if _, err := mnemonicToEntropy(mnemonic); err != nil {
+		return nil, err
+	}
+	return pbkdf2.Key([]byte(mnemonic), []byte("mnemonic"+passphrase), 2048, 64, sha512.New), nil
+}
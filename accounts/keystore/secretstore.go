@@ -0,0 +1,87 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrSecretNotFound is returned by secret stores if the requested secret does
+// not exist.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretStore is a backend persisting the keys of a keystore in place of the key
+// directory. The keys are handed to the store already encrypted according to the
+// Web3 Secret Storage specification, each under a unique name.
+//
+// Stores are polled for changes, so keys added or removed externally are picked
+// up by the keystore, firing the appropriate wallet events.
+type SecretStore interface {
+	// List returns the names of all the secrets in the store.
+	List() ([]string, error)
+
+	// Get retrieves the secret stored under the given name, or ErrSecretNotFound
+	// if it does not exist.
+	Get(name string) ([]byte, error)
+
+	// Put stores a secret under the given name, replacing any previous one.
+	Put(name string, secret []byte) error
+
+	// Delete removes the secret stored under the given name.
+	Delete(name string) error
+}
+
+// keyStoreSecret is a key storage encrypting keys with a passphrase, as done by
+// keyStorePassphrase, but persisting them into a secret store.
+type keyStoreSecret struct {
+	store   SecretStore
+	scryptN int
+	scryptP int
+}
+
+func (ks keyStoreSecret) GetKey(addr common.Address, name, auth string) (*Key, error) {
+	// Load the key from the secret store and decrypt its contents
+	keyjson, err := ks.store.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	key, err := DecryptKey(keyjson, auth)
+	if err != nil {
+		return nil, err
+	}
+	// Make sure we're really operating on the requested key (no swap attacks)
+	if key.Address != addr {
+		return nil, fmt.Errorf("key content mismatch: have account %x, want %x", key.Address, addr)
+	}
+	return key, nil
+}
+
+func (ks keyStoreSecret) StoreKey(name string, key *Key, auth string) error {
+	keyjson, err := EncryptKey(key, auth, ks.scryptN, ks.scryptP)
+	if err != nil {
+		return err
+	}
+	return ks.store.Put(name, keyjson)
+}
+
+// JoinPath returns the name as is, secret stores having a flat namespace.
+func (ks keyStoreSecret) JoinPath(name string) string {
+	return name
+}
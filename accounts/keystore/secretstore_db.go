@@ -0,0 +1,199 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	crand "crypto/rand"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"golang.org/x/crypto/scrypt"
+)
+
+var (
+	dbSecretMetaKey   = []byte("meta")    // Key of the encryption parameters of the database
+	dbSecretPrefix    = []byte("secret-") // dbSecretPrefix + name -> nonce + sealed secret
+	dbSecretCheckText = []byte("go-ethereum keystore secret store")
+)
+
+// dbSecretMeta is the encryption parameters of a secret database, along with a
+// sealed known text to verify the passphrase with.
+type dbSecretMeta struct {
+	Salt    hexutil.Bytes `json:"salt"`
+	ScryptN int           `json:"n"`
+	ScryptP int           `json:"p"`
+	Check   hexutil.Bytes `json:"check"`
+}
+
+// DBSecretStore is a secret store persisting secrets into a local LevelDB database,
+// sealing each with AES-GCM using a key derived from the database passphrase.
+type DBSecretStore struct {
+	db   *leveldb.DB
+	aead cipher.AEAD
+}
+
+// NewDBSecretStore opens or creates the encrypted secret database at the given
+// path. The scrypt parameters are only used when creating a new database, after
+// which the passphrase is verified against the ones it was created with.
+func NewDBSecretStore(path, passphrase string, scryptN, scryptP int) (*DBSecretStore, error) {
+	db, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	store := &DBSecretStore{db: db}
+	if err := store.init(passphrase, scryptN, scryptP); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// init derives the encryption key of the database from the passphrase, setting
+// up the encryption parameters if the database is new.
+func (s *DBSecretStore) init(passphrase string, scryptN, scryptP int) error {
+	var meta dbSecretMeta
+
+	blob, err := s.db.Get(dbSecretMetaKey, nil)
+	switch err {
+	case nil:
+		if err := json.Unmarshal(blob, &meta); err != nil {
+			return fmt.Errorf("invalid secret database metadata: %v", err)
+		}
+		if s.aead, err = newSecretAEAD(passphrase, meta.Salt, meta.ScryptN, meta.ScryptP); err != nil {
+			return err
+		}
+		if _, err := s.open(dbSecretMetaKey, meta.Check); err != nil {
+			return err
+		}
+		return nil
+
+	case leveldb.ErrNotFound:
+		meta = dbSecretMeta{Salt: make([]byte, 32), ScryptN: scryptN, ScryptP: scryptP}
+		if _, err := crand.Read(meta.Salt); err != nil {
+			return err
+		}
+		if s.aead, err = newSecretAEAD(passphrase, meta.Salt, scryptN, scryptP); err != nil {
+			return err
+		}
+		if meta.Check, err = s.seal(dbSecretMetaKey, dbSecretCheckText); err != nil {
+			return err
+		}
+		blob, err := json.Marshal(meta)
+		if err != nil {
+			return err
+		}
+		return s.db.Put(dbSecretMetaKey, blob, nil)
+
+	default:
+		return err
+	}
+}
+
+// newSecretAEAD derives an AES-256-GCM cipher from the passphrase.
+func newSecretAEAD(passphrase string, salt []byte, scryptN, scryptP int) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, scryptDKLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts the plaintext bound to the given database key, prefixing the
+// ciphertext with its random nonce.
+func (s *DBSecretStore) seal(key, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := crand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return s.aead.Seal(nonce, nonce, plaintext, key), nil
+}
+
+// open decrypts a sealed value stored under the given database key.
+func (s *DBSecretStore) open(key, sealed []byte) ([]byte, error) {
+	size := s.aead.NonceSize()
+	if len(sealed) < size {
+		return nil, ErrDecrypt
+	}
+	plaintext, err := s.aead.Open(nil, sealed[:size], sealed[size:], key)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+// dbSecretKey returns the database key a secret is stored under.
+func dbSecretKey(name string) []byte {
+	return append(append([]byte{}, dbSecretPrefix...), name...)
+}
+
+// List implements SecretStore, returning the names of all the stored secrets.
+func (s *DBSecretStore) List() ([]string, error) {
+	var names []string
+
+	it := s.db.NewIterator(util.BytesPrefix(dbSecretPrefix), nil)
+	for it.Next() {
+		names = append(names, string(it.Key()[len(dbSecretPrefix):]))
+	}
+	it.Release()
+	return names, it.Error()
+}
+
+// Get implements SecretStore, retrieving and decrypting the secret stored under
+// the given name.
+func (s *DBSecretStore) Get(name string) ([]byte, error) {
+	key := dbSecretKey(name)
+
+	sealed, err := s.db.Get(key, nil)
+	if err == leveldb.ErrNotFound {
+		return nil, ErrSecretNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.open(key, sealed)
+}
+
+// Put implements SecretStore, encrypting and storing a secret under the given
+// name.
+func (s *DBSecretStore) Put(name string, secret []byte) error {
+	key := dbSecretKey(name)
+
+	sealed, err := s.seal(key, secret)
+	if err != nil {
+		return err
+	}
+	return s.db.Put(key, sealed, nil)
+}
+
+// Delete implements SecretStore, removing the secret stored under the given name.
+func (s *DBSecretStore) Delete(name string) error {
+	return s.db.Delete(dbSecretKey(name), nil)
+}
+
+// Close closes the underlying database.
+func (s *DBSecretStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,259 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// vaultStub is a minimal in-memory implementation of the Vault key/value secrets
+// engine API, serving a single secrets path.
+type vaultStub struct {
+	token   string
+	secrets map[string]map[string]string
+	lock    sync.Mutex
+}
+
+func newVaultStub(token string) (*vaultStub, *httptest.Server) {
+	stub := &vaultStub{token: token, secrets: make(map[string]map[string]string)}
+	return stub, httptest.NewServer(stub)
+}
+
+func (s *vaultStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if r.Header.Get("X-Vault-Token") != s.token {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string][]string{"errors": {"permission denied"}})
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, "/v1/secret/keys")
+	name = strings.TrimPrefix(name, "/")
+
+	switch {
+	case name == "" && r.Method == http.MethodGet && r.URL.Query().Get("list") == "true":
+		if len(s.secrets) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		keys := []string{"nested/"}
+		for key := range s.secrets {
+			keys = append(keys, key)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string][]string{"keys": keys}})
+
+	case name != "" && r.Method == http.MethodGet:
+		secret, ok := s.secrets[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": secret})
+
+	case name != "" && r.Method == http.MethodPost:
+		secret := make(map[string]string)
+		if err := json.NewDecoder(r.Body).Decode(&secret); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.secrets[name] = secret
+		w.WriteHeader(http.StatusNoContent)
+
+	case name != "" && r.Method == http.MethodDelete:
+		delete(s.secrets, name)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// testSecretStore runs the basic storage operations against a secret store.
+func testSecretStore(t *testing.T, store SecretStore) {
+	if names, err := store.List(); err != nil || len(names) != 0 {
+		t.Fatalf("empty store listing mismatch: have %v/%v, want none", names, err)
+	}
+	if _, err := store.Get("foo"); err != ErrSecretNotFound {
+		t.Fatalf("missing secret error mismatch: have %v, want %v", err, ErrSecretNotFound)
+	}
+	secrets := map[string][]byte{
+		"foo": []byte(`{"address":"0000000000000000000000000000000000000001"}`),
+		"bar": []byte(`{"address":"0000000000000000000000000000000000000002"}`),
+	}
+	for name, secret := range secrets {
+		if err := store.Put(name, secret); err != nil {
+			t.Fatalf("failed to store secret %s: %v", name, err)
+		}
+	}
+	for name, secret := range secrets {
+		if have, err := store.Get(name); err != nil || !bytes.Equal(have, secret) {
+			t.Errorf("secret %s mismatch: have %s/%v, want %s", name, have, err, secret)
+		}
+	}
+	names, err := store.List()
+	if err != nil {
+		t.Fatalf("failed to list secrets: %v", err)
+	}
+	sort.Strings(names)
+	if len(names) != 2 || names[0] != "bar" || names[1] != "foo" {
+		t.Errorf("secret listing mismatch: have %v, want [bar foo]", names)
+	}
+	if err := store.Delete("foo"); err != nil {
+		t.Fatalf("failed to delete secret: %v", err)
+	}
+	if _, err := store.Get("foo"); err != ErrSecretNotFound {
+		t.Errorf("deleted secret error mismatch: have %v, want %v", err, ErrSecretNotFound)
+	}
+	if names, err := store.List(); err != nil || len(names) != 1 || names[0] != "bar" {
+		t.Errorf("secret listing mismatch after delete: have %v/%v, want [bar]", names, err)
+	}
+}
+
+// Tests the encrypted database secret store, including reopening it.
+func TestDBSecretStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eth-keystore-secrets-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "secrets")
+
+	store, err := NewDBSecretStore(path, "foo", veryLightScryptN, veryLightScryptP)
+	if err != nil {
+		t.Fatalf("failed to create secret store: %v", err)
+	}
+	testSecretStore(t, store)
+	store.Close()
+
+	if _, err := NewDBSecretStore(path, "bar", veryLightScryptN, veryLightScryptP); err != ErrDecrypt {
+		t.Fatalf("wrong passphrase error mismatch: have %v, want %v", err, ErrDecrypt)
+	}
+	if store, err = NewDBSecretStore(path, "foo", veryLightScryptN, veryLightScryptP); err != nil {
+		t.Fatalf("failed to reopen secret store: %v", err)
+	}
+	defer store.Close()
+
+	if secret, err := store.Get("bar"); err != nil || !bytes.Contains(secret, []byte("0002")) {
+		t.Errorf("secret mismatch after reopen: have %s/%v", secret, err)
+	}
+}
+
+// Tests the Vault secret store against a local stub server.
+func TestVaultSecretStore(t *testing.T) {
+	_, server := newVaultStub("token")
+	defer server.Close()
+
+	testSecretStore(t, NewVaultSecretStore(server.URL+"/v1/secret/keys/", "token"))
+
+	unauthorized := NewVaultSecretStore(server.URL+"/v1/secret/keys", "invalid")
+	if _, err := unauthorized.List(); err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("unauthorized error mismatch: have %v, want permission denied", err)
+	}
+}
+
+// Tests that a keystore backed by a secret store manages accounts and fires the
+// wallet events the same way as a filesystem one, also noticing external changes.
+func TestSecretKeyStore(t *testing.T) {
+	stub, server := newVaultStub("token")
+	defer server.Close()
+
+	ks := NewSecretKeyStore(NewVaultSecretStore(server.URL+"/v1/secret/keys", "token"), veryLightScryptN, veryLightScryptP)
+
+	updates := make(chan accounts.WalletEvent, 16)
+	sub := ks.Subscribe(updates)
+	defer sub.Unsubscribe()
+
+	// Create an account and ensure it's stored and announced
+	account, err := ks.NewAccount("foo")
+	if err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+	if ev := <-updates; ev.Kind != accounts.WalletArrived || ev.Wallet.Accounts()[0] != account {
+		t.Fatalf("arrival event mismatch: have %v %v", ev.Kind, ev.Wallet.Accounts())
+	}
+	stub.lock.Lock()
+	_, ok := stub.secrets[account.URL.Path]
+	stub.lock.Unlock()
+	if !ok {
+		t.Fatalf("account %s not in secret store", account.URL.Path)
+	}
+	// Unlock it, sign with it and change its passphrase
+	if err := ks.Unlock(account, "foo"); err != nil {
+		t.Fatalf("failed to unlock account: %v", err)
+	}
+	if _, err := ks.SignHash(accounts.Account{Address: account.Address}, testSigData); err != nil {
+		t.Fatalf("failed to sign with unlocked account: %v", err)
+	}
+	if err := ks.Update(account, "foo", "bar"); err != nil {
+		t.Fatalf("failed to update passphrase: %v", err)
+	}
+	if _, err := ks.SignHashWithPassphrase(account, "foo", testSigData); err != ErrDecrypt {
+		t.Fatalf("old passphrase error mismatch: have %v, want %v", err, ErrDecrypt)
+	}
+	// Add a key to the store externally and ensure it's picked up on reload
+	key, _ := crypto.GenerateKey()
+	keyjson, err := EncryptKey(newKeyFromECDSA(key), "baz", veryLightScryptN, veryLightScryptP)
+	if err != nil {
+		t.Fatalf("failed to encrypt key: %v", err)
+	}
+	store := ks.storage.(*keyStoreSecret).store
+	if err := store.Put("external", keyjson); err != nil {
+		t.Fatalf("failed to store external key: %v", err)
+	}
+	ks.cache.scanAccounts()
+	ks.refreshWallets()
+
+	if ev := <-updates; ev.Kind != accounts.WalletArrived || ev.Wallet.Accounts()[0].Address != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Fatalf("external arrival event mismatch: have %v %v", ev.Kind, ev.Wallet.Accounts())
+	}
+	if _, err := ks.SignHashWithPassphrase(accounts.Account{Address: crypto.PubkeyToAddress(key.PublicKey)}, "baz", testSigData); err != nil {
+		t.Fatalf("failed to sign with external key: %v", err)
+	}
+	// Delete both keys, internally and externally, and ensure they're dropped
+	if err := ks.Delete(account, "bar"); err != nil {
+		t.Fatalf("failed to delete account: %v", err)
+	}
+	if ev := <-updates; ev.Kind != accounts.WalletDropped || ev.Wallet.Accounts()[0] != account {
+		t.Fatalf("drop event mismatch: have %v %v", ev.Kind, ev.Wallet.Accounts())
+	}
+	if err := store.Delete("external"); err != nil {
+		t.Fatalf("failed to delete external key: %v", err)
+	}
+	ks.cache.scanAccounts()
+	ks.refreshWallets()
+
+	if ev := <-updates; ev.Kind != accounts.WalletDropped {
+		t.Fatalf("external drop event mismatch: have %v", ev.Kind)
+	}
+	if wallets := ks.Wallets(); len(wallets) != 0 {
+		t.Errorf("wallets left after deletion: %v", wallets)
+	}
+}
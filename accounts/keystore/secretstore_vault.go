@@ -0,0 +1,153 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// vaultRequestTimeout is the maximum time to wait for the secret store to reply.
+const vaultRequestTimeout = 10 * time.Second
+
+// VaultSecretStore is a secret store persisting secrets into a key/value secrets
+// engine of a HashiCorp Vault compatible HTTP server, each as the "key" field of
+// a secret under the configured path.
+type VaultSecretStore struct {
+	endpoint string // URL of the secrets path, e.g. http://127.0.0.1:8200/v1/secret/keystore
+	token    string // Access token sent along all requests
+	client   *http.Client
+}
+
+// vaultResponse is the envelope of the Vault API replies.
+type vaultResponse struct {
+	Data struct {
+		Key  string   `json:"key"`
+		Keys []string `json:"keys"`
+	} `json:"data"`
+	Errors []string `json:"errors"`
+}
+
+// NewVaultSecretStore creates a secret store over the secrets path at the given
+// Vault endpoint, authenticating with the given token.
+func NewVaultSecretStore(endpoint, token string) *VaultSecretStore {
+	return &VaultSecretStore{
+		endpoint: strings.TrimRight(endpoint, "/"),
+		token:    token,
+		client:   &http.Client{Timeout: vaultRequestTimeout},
+	}
+}
+
+// request sends an API request for the given secret (or the secrets path itself
+// if the name is empty), decoding the reply if there is one. Missing secrets are
+// reported as ErrSecretNotFound.
+func (s *VaultSecretStore) request(method, name string, query url.Values, body interface{}) (*vaultResponse, error) {
+	endpoint := s.endpoint
+	if name != "" {
+		endpoint += "/" + url.PathEscape(name)
+	}
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	var payload io.Reader
+	if body != nil {
+		blob, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		payload = bytes.NewReader(blob)
+	}
+	req, err := http.NewRequest(method, endpoint, payload)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", s.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	reply := new(vaultResponse)
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return nil, ErrSecretNotFound
+	case res.StatusCode == http.StatusNoContent:
+		return reply, nil
+	case res.StatusCode < 200 || res.StatusCode >= 300:
+		if err := json.NewDecoder(res.Body).Decode(reply); err == nil && len(reply.Errors) > 0 {
+			return nil, fmt.Errorf("vault: %s", strings.Join(reply.Errors, "; "))
+		}
+		return nil, fmt.Errorf("vault: %s", res.Status)
+	}
+	if err := json.NewDecoder(res.Body).Decode(reply); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return reply, nil
+}
+
+// List implements SecretStore, returning the names of all the secrets under the
+// secrets path. Nested paths are ignored.
+func (s *VaultSecretStore) List() ([]string, error) {
+	reply, err := s.request(http.MethodGet, "", url.Values{"list": {"true"}}, nil)
+	if err == ErrSecretNotFound {
+		return nil, nil // Vault reports empty paths as missing
+	}
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(reply.Data.Keys))
+	for _, name := range reply.Data.Keys {
+		if !strings.HasSuffix(name, "/") {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// Get implements SecretStore, retrieving the secret stored under the given name.
+func (s *VaultSecretStore) Get(name string) ([]byte, error) {
+	reply, err := s.request(http.MethodGet, name, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if reply.Data.Key == "" {
+		return nil, fmt.Errorf("vault: secret %q has no key field", name)
+	}
+	return []byte(reply.Data.Key), nil
+}
+
+// Put implements SecretStore, storing a secret under the given name.
+func (s *VaultSecretStore) Put(name string, secret []byte) error {
+	_, err := s.request(http.MethodPost, name, nil, map[string]string{"key": string(secret)})
+	return err
+}
+
+// Delete implements SecretStore, removing the secret stored under the given name.
+func (s *VaultSecretStore) Delete(name string) error {
+	_, err := s.request(http.MethodDelete, name, nil, nil)
+	return err
+}
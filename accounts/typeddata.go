@@ -0,0 +1,404 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package accounts
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// TypedDataDomainType is the name of the type describing the signing domain of
+// an EIP-712 typed data message.
+const TypedDataDomainType = "EIP712Domain"
+
+// typedDataArray matches array types, capturing the element type and the
+// optional fixed length.
+var typedDataArray = regexp.MustCompile(`^(.+)\[([0-9]*)\]$`)
+
+// TypedData is an EIP-712 typed structured data message: the message itself of
+// the primary type, the domain it is valid within and the definitions of all
+// the struct types they reference.
+type TypedData struct {
+	Types       TypedDataTypes         `json:"types"`
+	PrimaryType string                 `json:"primaryType"`
+	Domain      TypedDataDomain        `json:"domain"`
+	Message     map[string]interface{} `json:"message"`
+}
+
+// TypedDataTypes maps the names of the struct types to their member fields.
+type TypedDataTypes map[string][]TypedDataField
+
+// TypedDataField is a named and typed member of an EIP-712 struct type.
+type TypedDataField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// TypedDataDomain is the EIP-712 signing domain, separating the messages of
+// different applications. Only the fields listed in the EIP712Domain type are
+// included in the domain separator.
+type TypedDataDomain struct {
+	Name              string                `json:"name,omitempty"`
+	Version           string                `json:"version,omitempty"`
+	ChainId           *math.HexOrDecimal256 `json:"chainId,omitempty"`
+	VerifyingContract string                `json:"verifyingContract,omitempty"`
+	Salt              string                `json:"salt,omitempty"`
+}
+
+// UnmarshalJSON parses a signing domain, accepting the chain id both as a JSON
+// number and as a decimal or hex string.
+func (domain *TypedDataDomain) UnmarshalJSON(input []byte) error {
+	type typedDataDomain TypedDataDomain // Drops the methods to avoid recursion
+	var dec struct {
+		typedDataDomain
+		ChainId json.RawMessage `json:"chainId,omitempty"`
+	}
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	*domain = TypedDataDomain(dec.typedDataDomain)
+	if len(dec.ChainId) > 0 && string(dec.ChainId) != "null" {
+		chainId, err := parseInteger(strings.Trim(string(dec.ChainId), `"`))
+		if err != nil {
+			return fmt.Errorf("invalid chain id: %v", err)
+		}
+		domain.ChainId = (*math.HexOrDecimal256)(chainId)
+	}
+	return nil
+}
+
+// Map returns the domain as a generic struct value for hashing.
+func (domain *TypedDataDomain) Map() map[string]interface{} {
+	data := make(map[string]interface{})
+	if domain.Name != "" {
+		data["name"] = domain.Name
+	}
+	if domain.Version != "" {
+		data["version"] = domain.Version
+	}
+	if domain.ChainId != nil {
+		data["chainId"] = (*big.Int)(domain.ChainId)
+	}
+	if domain.VerifyingContract != "" {
+		data["verifyingContract"] = domain.VerifyingContract
+	}
+	if domain.Salt != "" {
+		data["salt"] = domain.Salt
+	}
+	return data
+}
+
+// SignHash returns the digest to sign for the typed data message:
+//
+//	keccak256("\x19\x01" || domainSeparator || hashStruct(message))
+func (typedData *TypedData) SignHash() ([]byte, error) {
+	domainSeparator, err := typedData.DomainSeparator()
+	if err != nil {
+		return nil, err
+	}
+	messageHash, err := typedData.MessageHash()
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Keccak256([]byte{0x19, 0x01}, domainSeparator, messageHash), nil
+}
+
+// DomainSeparator returns the hash of the signing domain.
+func (typedData *TypedData) DomainSeparator() ([]byte, error) {
+	if _, ok := typedData.Types[TypedDataDomainType]; !ok {
+		return nil, fmt.Errorf("missing %s type definition", TypedDataDomainType)
+	}
+	return typedData.HashStruct(TypedDataDomainType, typedData.Domain.Map())
+}
+
+// MessageHash returns the hash of the message of the primary type.
+func (typedData *TypedData) MessageHash() ([]byte, error) {
+	if typedData.PrimaryType == TypedDataDomainType {
+		return nil, errors.New("primary type cannot be the domain type")
+	}
+	return typedData.HashStruct(typedData.PrimaryType, typedData.Message)
+}
+
+// HashStruct returns the hash of a struct value of the given type:
+//
+//	keccak256(typeHash || encodeData(data))
+func (typedData *TypedData) HashStruct(primaryType string, data map[string]interface{}) ([]byte, error) {
+	encoded, err := typedData.EncodeData(primaryType, data)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Keccak256(encoded), nil
+}
+
+// TypeHash returns the hash of the encoded type definition.
+func (typedData *TypedData) TypeHash(primaryType string) []byte {
+	return crypto.Keccak256(typedData.EncodeType(primaryType))
+}
+
+// EncodeType returns the canonical definition of a struct type, followed by the
+// alphabetically sorted definitions of all the struct types it references:
+//
+//	Mail(Person from,Person to,string contents)Person(string name,address wallet)
+func (typedData *TypedData) EncodeType(primaryType string) []byte {
+	deps := typedData.dependencies(primaryType, make(map[string]bool))
+	if len(deps) == 0 {
+		return nil
+	}
+	sort.Strings(deps[1:])
+
+	var buffer bytes.Buffer
+	for _, dep := range deps {
+		fields := make([]string, len(typedData.Types[dep]))
+		for i, field := range typedData.Types[dep] {
+			fields[i] = field.Type + " " + field.Name
+		}
+		buffer.WriteString(dep + "(" + strings.Join(fields, ",") + ")")
+	}
+	return buffer.Bytes()
+}
+
+// dependencies returns the given struct type followed by all the struct types
+// it references, directly or indirectly, in no particular order.
+func (typedData *TypedData) dependencies(primaryType string, found map[string]bool) []string {
+	if found[primaryType] {
+		return nil
+	}
+	if _, ok := typedData.Types[primaryType]; !ok {
+		return nil
+	}
+	found[primaryType] = true
+
+	deps := []string{primaryType}
+	for _, field := range typedData.Types[primaryType] {
+		deps = append(deps, typedData.dependencies(baseType(field.Type), found)...)
+	}
+	return deps
+}
+
+// baseType strips all array suffixes from a type.
+func baseType(typ string) string {
+	for {
+		match := typedDataArray.FindStringSubmatch(typ)
+		if match == nil {
+			return typ
+		}
+		typ = match[1]
+	}
+}
+
+// EncodeData returns the type hash of a struct type followed by the encoding of
+// each of the struct members of the given value, in the order of the type
+// definition.
+func (typedData *TypedData) EncodeData(primaryType string, data map[string]interface{}) ([]byte, error) {
+	fields, ok := typedData.Types[primaryType]
+	if !ok {
+		return nil, fmt.Errorf("unknown type %q", primaryType)
+	}
+	if len(data) > len(fields) {
+		return nil, fmt.Errorf("%s: value has more fields than the type definition", primaryType)
+	}
+	buffer := bytes.NewBuffer(typedData.TypeHash(primaryType))
+	for _, field := range fields {
+		value, ok := data[field.Name]
+		if !ok {
+			return nil, fmt.Errorf("%s: missing value for field %q", primaryType, field.Name)
+		}
+		encoded, err := typedData.encodeValue(field.Type, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %v", primaryType, field.Name, err)
+		}
+		buffer.Write(encoded)
+	}
+	return buffer.Bytes(), nil
+}
+
+// encodeValue encodes a single value of the given type into a 32 byte word.
+// Dynamic values and references to arrays and structs are replaced by their
+// hashes.
+func (typedData *TypedData) encodeValue(typ string, value interface{}) ([]byte, error) {
+	// Arrays are encoded as the hash of the concatenated encoding of their items
+	if match := typedDataArray.FindStringSubmatch(typ); match != nil {
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid value for %s: %v", typ, value)
+		}
+		if match[2] != "" {
+			if size, err := strconv.Atoi(match[2]); err != nil || size != len(items) {
+				return nil, fmt.Errorf("invalid length for %s: %d", typ, len(items))
+			}
+		}
+		var buffer bytes.Buffer
+		for i, item := range items {
+			encoded, err := typedData.encodeValue(match[1], item)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %v", i, err)
+			}
+			buffer.Write(encoded)
+		}
+		return crypto.Keccak256(buffer.Bytes()), nil
+	}
+	// Structs are encoded as their struct hash
+	if _, ok := typedData.Types[typ]; ok {
+		data, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid value for %s: %v", typ, value)
+		}
+		return typedData.HashStruct(typ, data)
+	}
+	// Otherwise encode the atomic or dynamic value
+	switch {
+	case typ == "string":
+		str, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid string: %v", value)
+		}
+		return crypto.Keccak256([]byte(str)), nil
+
+	case typ == "bytes":
+		blob, err := parseBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		return crypto.Keccak256(blob), nil
+
+	case typ == "bool":
+		flag, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("invalid bool: %v", value)
+		}
+		if flag {
+			return math.PaddedBigBytes(common.Big1, 32), nil
+		}
+		return make([]byte, 32), nil
+
+	case typ == "address":
+		str, ok := value.(string)
+		if !ok || !common.IsHexAddress(str) {
+			return nil, fmt.Errorf("invalid address: %v", value)
+		}
+		return common.LeftPadBytes(common.HexToAddress(str).Bytes(), 32), nil
+
+	case strings.HasPrefix(typ, "bytes"):
+		size, err := strconv.Atoi(typ[len("bytes"):])
+		if err != nil || size < 1 || size > 32 {
+			return nil, fmt.Errorf("unknown type %q", typ)
+		}
+		blob, err := parseBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		if len(blob) != size {
+			return nil, fmt.Errorf("invalid length for %s: %d", typ, len(blob))
+		}
+		return common.RightPadBytes(blob, 32), nil
+
+	case strings.HasPrefix(typ, "int") || strings.HasPrefix(typ, "uint"):
+		signed := strings.HasPrefix(typ, "int")
+
+		bits, err := strconv.Atoi(strings.TrimPrefix(strings.TrimPrefix(typ, "u"), "int"))
+		if err != nil || bits < 8 || bits > 256 || bits%8 != 0 {
+			return nil, fmt.Errorf("unknown type %q", typ)
+		}
+		number, err := parseInteger(value)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkIntegerRange(number, bits, signed); err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", typ, err)
+		}
+		return math.PaddedBigBytes(math.U256(new(big.Int).Set(number)), 32), nil
+	}
+	return nil, fmt.Errorf("unknown type %q", typ)
+}
+
+// parseBytes converts a hex string or a byte slice into a byte slice.
+func parseBytes(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case []byte:
+		return v, nil
+	case hexutil.Bytes:
+		return v, nil
+	case string:
+		blob, err := hexutil.Decode(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bytes %q: %v", v, err)
+		}
+		return blob, nil
+	}
+	return nil, fmt.Errorf("invalid bytes: %v", value)
+}
+
+// parseInteger converts a JSON number, a decimal or hex string, or a big integer
+// into a big integer.
+func parseInteger(value interface{}) (*big.Int, error) {
+	switch v := value.(type) {
+	case *big.Int:
+		return v, nil
+	case *math.HexOrDecimal256:
+		return (*big.Int)(v), nil
+	case json.Number:
+		return parseInteger(string(v))
+	case float64:
+		// JSON numbers are decoded as floats, only accept them if exact
+		if v != float64(int64(v)) || v > 1<<53 || v < -(1<<53) {
+			return nil, fmt.Errorf("invalid integer %v, use a string for large values", v)
+		}
+		return big.NewInt(int64(v)), nil
+	case string:
+		if v == "" {
+			return nil, errors.New("empty integer")
+		}
+		negative := strings.HasPrefix(v, "-")
+		number, ok := math.ParseBig256(strings.TrimPrefix(v, "-"))
+		if !ok {
+			return nil, fmt.Errorf("invalid integer %q", v)
+		}
+		if negative {
+			number.Neg(number)
+		}
+		return number, nil
+	}
+	return nil, fmt.Errorf("invalid integer: %v", value)
+}
+
+// checkIntegerRange verifies that a number fits into an integer type of the
+// given size and signedness.
+func checkIntegerRange(number *big.Int, bits int, signed bool) error {
+	if !signed {
+		if number.Sign() < 0 || number.BitLen() > bits {
+			return fmt.Errorf("%v out of range", number)
+		}
+		return nil
+	}
+	limit := new(big.Int).Lsh(common.Big1, uint(bits-1))
+	if number.Cmp(limit) >= 0 || number.Cmp(new(big.Int).Neg(limit)) < 0 {
+		return fmt.Errorf("%v out of range", number)
+	}
+	return nil
+}
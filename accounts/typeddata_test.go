@@ -0,0 +1,174 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package accounts
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// mailTypedData is the example message of the EIP-712 specification.
+const mailTypedData = `{
+	"types": {
+		"EIP712Domain": [
+			{"name": "name", "type": "string"},
+			{"name": "version", "type": "string"},
+			{"name": "chainId", "type": "uint256"},
+			{"name": "verifyingContract", "type": "address"}
+		],
+		"Person": [
+			{"name": "name", "type": "string"},
+			{"name": "wallet", "type": "address"}
+		],
+		"Mail": [
+			{"name": "from", "type": "Person"},
+			{"name": "to", "type": "Person"},
+			{"name": "contents", "type": "string"}
+		]
+	},
+	"primaryType": "Mail",
+	"domain": {
+		"name": "Ether Mail",
+		"version": "1",
+		"chainId": 1,
+		"verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+	},
+	"message": {
+		"from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+		"to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+		"contents": "Hello, Bob!"
+	}
+}`
+
+// Tests the hashing and signing of the example message against the values given
+// in the EIP-712 specification.
+func TestTypedDataMail(t *testing.T) {
+	var typedData TypedData
+	if err := json.Unmarshal([]byte(mailTypedData), &typedData); err != nil {
+		t.Fatalf("failed to parse typed data: %v", err)
+	}
+	if have, want := string(typedData.EncodeType("Mail")), "Mail(Person from,Person to,string contents)Person(string name,address wallet)"; have != want {
+		t.Errorf("encoded type mismatch: have %s, want %s", have, want)
+	}
+	if have, want := hexutil.Encode(typedData.TypeHash("Mail")), "0xa0cedeb2dc280ba39b857546d74f5549c3a1d7bdc2dd96bf881f76108e23dac2"; have != want {
+		t.Errorf("type hash mismatch: have %s, want %s", have, want)
+	}
+	domain, err := typedData.DomainSeparator()
+	if err != nil {
+		t.Fatalf("failed to hash domain: %v", err)
+	}
+	if have, want := hexutil.Encode(domain), "0xf2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f"; have != want {
+		t.Errorf("domain separator mismatch: have %s, want %s", have, want)
+	}
+	message, err := typedData.MessageHash()
+	if err != nil {
+		t.Fatalf("failed to hash message: %v", err)
+	}
+	if have, want := hexutil.Encode(message), "0xc52c0ee5d84264471806290a3f2c4cecfc5490626bf912d01f240d7a274b371e"; have != want {
+		t.Errorf("message hash mismatch: have %s, want %s", have, want)
+	}
+	hash, err := typedData.SignHash()
+	if err != nil {
+		t.Fatalf("failed to hash typed data: %v", err)
+	}
+	if have, want := hexutil.Encode(hash), "0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2"; have != want {
+		t.Errorf("sign hash mismatch: have %s, want %s", have, want)
+	}
+	key, _ := crypto.ToECDSA(crypto.Keccak256([]byte("cow")))
+	if addr := crypto.PubkeyToAddress(key.PublicKey); addr != common.HexToAddress("0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826") {
+		t.Fatalf("signer mismatch: have %x", addr)
+	}
+	sig, err := crypto.Sign(hash, key)
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	if have, want := hexutil.Encode(sig[:64]), "0x4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b91562"; have != want {
+		t.Errorf("signature mismatch: have %s, want %s", have, want)
+	}
+	if sig[64]+27 != 28 {
+		t.Errorf("signature V mismatch: have %d, want 28", sig[64]+27)
+	}
+}
+
+// Tests the encoding of the atomic, dynamic and array values, and that invalid
+// values are rejected.
+func TestTypedDataValues(t *testing.T) {
+	tests := []struct {
+		typ   string
+		value interface{}
+		want  string // Expected encoding, empty if the value is invalid
+	}{
+		{"bool", true, "0x0000000000000000000000000000000000000000000000000000000000000001"},
+		{"bool", "true", ""},
+		{"uint8", float64(255), "0x00000000000000000000000000000000000000000000000000000000000000ff"},
+		{"uint8", float64(256), ""},
+		{"uint8", float64(1.5), ""},
+		{"uint256", "0x100", "0x0000000000000000000000000000000000000000000000000000000000000100"},
+		{"uint256", "-1", ""},
+		{"int8", "-1", "0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"},
+		{"int8", "-129", ""},
+		{"int7", "1", ""},
+		{"bytes2", "0xcafe", "0xcafe000000000000000000000000000000000000000000000000000000000000"},
+		{"bytes2", "0xca", ""},
+		{"bytes33", "0xca", ""},
+		{"bytes", "0xcafe", hexutil.Encode(crypto.Keccak256([]byte{0xca, 0xfe}))},
+		{"string", "cafe", hexutil.Encode(crypto.Keccak256([]byte("cafe")))},
+		{"address", "0xcafe", ""},
+		{"uint8[2]", []interface{}{float64(1), float64(2)}, hexutil.Encode(crypto.Keccak256(
+			common.LeftPadBytes([]byte{1}, 32), common.LeftPadBytes([]byte{2}, 32),
+		))},
+		{"uint8[2]", []interface{}{float64(1)}, ""},
+		{"uint8[]", []interface{}{}, hexutil.Encode(crypto.Keccak256(nil))},
+		{"float", float64(1), ""},
+	}
+	typedData := new(TypedData)
+	for i, tt := range tests {
+		encoded, err := typedData.encodeValue(tt.typ, tt.value)
+		switch {
+		case tt.want == "" && err == nil:
+			t.Errorf("test %d: invalid %s %v accepted: %x", i, tt.typ, tt.value, encoded)
+		case tt.want != "" && err != nil:
+			t.Errorf("test %d: failed to encode %s %v: %v", i, tt.typ, tt.value, err)
+		case tt.want != "" && hexutil.Encode(encoded) != tt.want:
+			t.Errorf("test %d: encoding mismatch for %s %v: have %x, want %s", i, tt.typ, tt.value, encoded, tt.want)
+		}
+	}
+}
+
+// Tests that the chain id of the signing domain is accepted both as a number and
+// as a string.
+func TestTypedDataDomainChainId(t *testing.T) {
+	for _, input := range []string{`{"chainId": 4}`, `{"chainId": "4"}`, `{"chainId": "0x4"}`} {
+		var domain TypedDataDomain
+		if err := json.Unmarshal([]byte(input), &domain); err != nil {
+			t.Errorf("%s: failed to parse domain: %v", input, err)
+			continue
+		}
+		if domain.ChainId == nil || (*big.Int)(domain.ChainId).Int64() != 4 {
+			t.Errorf("%s: chain id mismatch: have %v, want 4", input, domain.ChainId)
+		}
+	}
+	var domain TypedDataDomain
+	if err := json.Unmarshal([]byte(`{"chainId": "four"}`), &domain); err == nil {
+		t.Errorf("invalid chain id accepted")
+	}
+}
@@ -37,9 +37,10 @@ var (
 	solcFlag = flag.String("solc", "solc", "Solidity compiler to use if source builds are requested")
 	excFlag  = flag.String("exc", "", "Comma separated types to exclude from binding")
 
-	pkgFlag  = flag.String("pkg", "", "Package name to generate the binding into")
-	outFlag  = flag.String("out", "", "Output file for the generated binding (default = stdout)")
-	langFlag = flag.String("lang", "go", "Destination language for the bindings (go, java, objc)")
+	pkgFlag   = flag.String("pkg", "", "Package name to generate the binding into")
+	outFlag   = flag.String("out", "", "Output file for the generated binding (default = stdout)")
+	langFlag  = flag.String("lang", "go", "Destination language for the bindings (go, java, objc, ts, jsonschema)")
+	batchFlag = flag.Bool("batch", false, "Generate batched callers aggregating calls into a single request (go only)")
 )
 
 func main() {
@@ -65,6 +66,10 @@ func main() {
 		lang = bind.LangJava
 	case "objc":
 		lang = bind.LangObjC
+	case "ts":
+		lang = bind.LangTypeScript
+	case "jsonschema":
+		lang = bind.LangJSONSchema
 	default:
 		fmt.Printf("Unsupported destination language \"%s\" (--lang)\n", *langFlag)
 		os.Exit(-1)
@@ -123,7 +128,7 @@ func main() {
 		types = append(types, kind)
 	}
 	// Generate the contract binding
-	code, err := bind.Bind(types, abis, bins, *pkgFlag, lang)
+	code, err := bind.Bind(types, abis, bins, *pkgFlag, lang, *batchFlag)
 	if err != nil {
 		fmt.Printf("Failed to generate ABI binding: %v\n", err)
 		os.Exit(-1)
@@ -0,0 +1,109 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"github.com/ethereum/go-ethereum/cmd/evm/internal/debugger"
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/ethdb"
+	cli "gopkg.in/urfave/cli.v1"
+)
+
+var (
+	BreakpointFlag = cli.StringSliceFlag{
+		Name:  "break",
+		Usage: "breakpoint to stop at, as <pc|op|depth> <value> (may be repeated)",
+	}
+	ScriptFlag = cli.StringFlag{
+		Name:  "script",
+		Usage: "file with debugger commands to run instead of reading them from stdin",
+	}
+)
+
+var debugCommand = cli.Command{
+	Action:    debugCmd,
+	Name:      "debug",
+	Usage:     "step through the execution of arbitrary evm binary",
+	ArgsUsage: "<code>",
+	Flags: []cli.Flag{
+		BreakpointFlag,
+		ScriptFlag,
+	},
+	Description: `
+The debug command executes arbitrary EVM code, pausing before the first
+instruction and at every breakpoint to inspect the stack, memory and storage.
+Type help at the prompt for the list of commands. If a script is given, the
+commands are read from it instead, and the execution runs to completion once
+the script ends.`,
+}
+
+func debugCmd(ctx *cli.Context) error {
+	code, err := readCode(ctx)
+	if err != nil {
+		return err
+	}
+	if len(code) == 0 {
+		return errors.New("no code to debug")
+	}
+	var in io.Reader = os.Stdin
+	if path := ctx.String(ScriptFlag.Name); path != "" {
+		script, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer script.Close()
+		in = script
+	}
+	dbg := debugger.New(in, os.Stdout)
+	for _, spec := range ctx.StringSlice(BreakpointFlag.Name) {
+		b, err := debugger.ParseBreakpoint(spec)
+		if err != nil {
+			return err
+		}
+		dbg.AddBreakpoint(b)
+	}
+	cfg := &runtime.Config{
+		GasLimit: ctx.GlobalUint64(GasFlag.Name),
+		GasPrice: utils.GlobalBig(ctx, PriceFlag.Name),
+		Value:    utils.GlobalBig(ctx, ValueFlag.Name),
+		EVMConfig: vm.Config{
+			Tracer: dbg,
+			Debug:  true,
+		},
+	}
+	if ctx.GlobalString(GenesisFlag.Name) != "" {
+		gen := readGenesis(ctx.GlobalString(GenesisFlag.Name))
+		db, _ := ethdb.NewMemDatabase()
+		genesis := gen.ToBlock(db)
+		cfg.State, _ = state.New(genesis.Root(), state.NewDatabase(db))
+		cfg.ChainConfig = gen.Config
+	}
+	if ctx.GlobalString(SenderFlag.Name) != "" {
+		cfg.Origin = common.HexToAddress(ctx.GlobalString(SenderFlag.Name))
+	}
+	// Execution errors are reported by the debugger itself
+	runtime.Execute(code, common.Hex2Bytes(ctx.GlobalString(InputFlag.Name)), cfg)
+	return nil
+}
@@ -0,0 +1,329 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+// Package debugger implements an interactive step debugger for the EVM.
+package debugger
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// Breakpoint conditions the debugger can stop on.
+const (
+	BreakPC    = "pc"    // Stop before executing the instruction at a program counter
+	BreakOp    = "op"    // Stop before executing a specific opcode
+	BreakDepth = "depth" // Stop whenever a call frame at a specific depth is entered
+)
+
+// Breakpoint is a condition on which the debugger pauses the execution.
+type Breakpoint struct {
+	Kind  string // Type of the condition (BreakPC, BreakOp or BreakDepth)
+	Value uint64 // Program counter, opcode or call depth to stop at
+}
+
+// String implements fmt.Stringer.
+func (b Breakpoint) String() string {
+	if b.Kind == BreakOp {
+		return fmt.Sprintf("%s %v", b.Kind, vm.OpCode(b.Value))
+	}
+	return fmt.Sprintf("%s %d", b.Kind, b.Value)
+}
+
+// run modes of the debugger between two pauses.
+const (
+	modeStep     = iota // Pause before the next instruction
+	modeContinue        // Pause at the next breakpoint
+	modeStepOut         // Pause once the current call frame is left
+	modeDetached        // Never pause again
+)
+
+// Debugger also tracks the accessed storage slots through the state hooks.
+var _ vm.StateTracer = (*Debugger)(nil)
+
+// Debugger is an EVM tracer which pauses the execution before instructions
+// matching its breakpoints, and reads commands from its input to inspect the
+// machine state and to resume the execution. The debugger starts paused on the
+// first instruction. Once the input is exhausted the execution runs to its end,
+// so a scripted session can be fed from any reader.
+type Debugger struct {
+	in  *bufio.Scanner
+	out io.Writer
+
+	breakpoints []Breakpoint
+	mode        int
+	target      int // Call depth to pause below when stepping out
+	depth       int // Call depth of the last executed instruction
+
+	env      *vm.EVM
+	contract *vm.Contract
+	storage  map[common.Address]map[common.Hash]struct{} // Storage slots accessed during execution
+}
+
+// New creates a debugger reading commands from in and writing its output to out.
+func New(in io.Reader, out io.Writer) *Debugger {
+	return &Debugger{
+		in:      bufio.NewScanner(in),
+		out:     out,
+		mode:    modeStep,
+		storage: make(map[common.Address]map[common.Hash]struct{}),
+	}
+}
+
+// AddBreakpoint adds a new condition on which to pause the execution.
+func (d *Debugger) AddBreakpoint(b Breakpoint) {
+	d.breakpoints = append(d.breakpoints, b)
+}
+
+// Breakpoints returns the currently active breakpoints.
+func (d *Debugger) Breakpoints() []Breakpoint {
+	return append([]Breakpoint{}, d.breakpoints...)
+}
+
+// ParseBreakpoint parses a breakpoint in the form of "<kind> <value>", where the
+// kind is one of pc, op or depth and the value is a number or an opcode name.
+func ParseBreakpoint(spec string) (Breakpoint, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 2 {
+		return Breakpoint{}, fmt.Errorf("invalid breakpoint %q, want <pc|op|depth> <value>", spec)
+	}
+	switch kind, value := fields[0], fields[1]; kind {
+	case BreakPC, BreakDepth:
+		n, err := strconv.ParseUint(value, 0, 64)
+		if err != nil {
+			return Breakpoint{}, fmt.Errorf("invalid %s %q: %v", kind, value, err)
+		}
+		return Breakpoint{Kind: kind, Value: n}, nil
+
+	case BreakOp:
+		op := vm.StringToOp(strings.ToUpper(value))
+		if op.String() != strings.ToUpper(value) {
+			return Breakpoint{}, fmt.Errorf("unknown opcode %q", value)
+		}
+		return Breakpoint{Kind: kind, Value: uint64(op)}, nil
+
+	default:
+		return Breakpoint{}, fmt.Errorf("unknown breakpoint kind %q", kind)
+	}
+}
+
+func (d *Debugger) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	return nil
+}
+
+// CaptureState pauses the execution if the instruction about to be executed is
+// a stopping point in the current run mode, processing commands until one of
+// them resumes the execution.
+func (d *Debugger) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	entered := depth > d.depth // Returning into a caller frame is not an entry
+	d.env, d.contract, d.depth = env, contract, depth
+
+	if !d.shouldPause(pc, op, depth, entered) {
+		return nil
+	}
+	fmt.Fprintf(d.out, "pc=%d op=%v gas=%d cost=%d depth=%d contract=%x\n", pc, op, gas, cost, depth, contract.Address())
+	if err != nil {
+		fmt.Fprintf(d.out, "error: %v\n", err)
+	}
+	for {
+		fmt.Fprint(d.out, "> ")
+		if !d.in.Scan() {
+			// Input exhausted, run the execution to completion
+			fmt.Fprintln(d.out)
+			d.mode = modeDetached
+			return nil
+		}
+		if d.command(d.in.Text(), memory, stack) {
+			return nil
+		}
+	}
+}
+
+// shouldPause reports whether the execution should be paused before the given
+// instruction, based on the current run mode and the breakpoints.
+func (d *Debugger) shouldPause(pc uint64, op vm.OpCode, depth int, entered bool) bool {
+	switch d.mode {
+	case modeDetached:
+		return false
+	case modeStep:
+		return true
+	case modeStepOut:
+		if depth < d.target {
+			return true
+		}
+	}
+	for _, b := range d.breakpoints {
+		switch {
+		case b.Kind == BreakPC && b.Value == pc:
+			return true
+		case b.Kind == BreakOp && b.Value == uint64(op):
+			return true
+		case b.Kind == BreakDepth && b.Value == uint64(depth) && entered:
+			return true
+		}
+	}
+	return false
+}
+
+// command executes a single debugger command, returning whether the execution
+// should be resumed.
+func (d *Debugger) command(line string, memory *vm.Memory, stack *vm.Stack) bool {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return false
+	}
+	switch fields[0] {
+	case "step", "s":
+		d.mode = modeStep
+		return true
+
+	case "continue", "c":
+		d.mode = modeContinue
+		return true
+
+	case "out", "o":
+		d.mode, d.target = modeStepOut, d.depth
+		return true
+
+	case "quit", "q":
+		d.mode = modeDetached
+		d.env.Cancel()
+		return true
+
+	case "break", "b":
+		b, err := ParseBreakpoint(strings.Join(fields[1:], " "))
+		if err != nil {
+			fmt.Fprintln(d.out, err)
+			return false
+		}
+		d.AddBreakpoint(b)
+		fmt.Fprintf(d.out, "breakpoint #%d: %v\n", len(d.breakpoints)-1, b)
+
+	case "delete", "d":
+		index := -1
+		if len(fields) == 2 {
+			index, _ = strconv.Atoi(fields[1])
+		}
+		if index < 0 || index >= len(d.breakpoints) {
+			fmt.Fprintln(d.out, "invalid breakpoint, want delete <index>")
+			return false
+		}
+		d.breakpoints = append(d.breakpoints[:index], d.breakpoints[index+1:]...)
+
+	case "breakpoints", "bl":
+		for i, b := range d.breakpoints {
+			fmt.Fprintf(d.out, "#%d: %v\n", i, b)
+		}
+
+	case "stack":
+		data := stack.Data()
+		for i := len(data) - 1; i >= 0; i-- {
+			fmt.Fprintf(d.out, "%04d: %x\n", len(data)-1-i, wordBytes(data[i]))
+		}
+
+	case "memory", "mem":
+		data := memory.Data()
+		for i := 0; i < len(data); i += 32 {
+			fmt.Fprintf(d.out, "%04x: %x\n", i, data[i:i+32])
+		}
+
+	case "storage":
+		addr := d.contract.Address()
+		keys := make([]common.Hash, 0, len(d.storage[addr]))
+		for key := range d.storage[addr] {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i][:], keys[j][:]) < 0 })
+		for _, key := range keys {
+			fmt.Fprintf(d.out, "%x: %x\n", key, d.env.StateDB.GetState(addr, key))
+		}
+
+	case "help", "h":
+		fmt.Fprint(d.out, help)
+
+	default:
+		fmt.Fprintf(d.out, "unknown command %q, try help\n", fields[0])
+	}
+	return false
+}
+
+// help is the list of commands supported by the debugger.
+const help = `step, s              execute the next instruction
+continue, c          resume execution until the next breakpoint
+out, o               resume execution until the current call frame returns
+break, b <kind> <v>  add a breakpoint on a pc, op or depth
+delete, d <index>    remove a breakpoint
+breakpoints, bl      list the breakpoints
+stack                show the stack, top first
+memory, mem          show the memory
+storage              show the accessed storage slots of the current contract
+quit, q              abort the execution
+`
+
+// wordBytes pads a stack item to 32 bytes for display.
+func wordBytes(x *big.Int) []byte {
+	return common.LeftPadBytes(x.Bytes(), 32)
+}
+
+func (d *Debugger) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureEnd reports the outcome of the execution.
+func (d *Debugger) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error {
+	fmt.Fprintf(d.out, "execution finished: output=0x%x gas=%d\n", output, gasUsed)
+	if err != nil {
+		fmt.Fprintf(d.out, "error: %v\n", err)
+	}
+	return nil
+}
+
+func (d *Debugger) CaptureAccountRead(addr common.Address)                           {}
+func (d *Debugger) CaptureBalanceChange(addr common.Address, prev, balance *big.Int) {}
+func (d *Debugger) CaptureNonceChange(addr common.Address, prev, nonce uint64)       {}
+func (d *Debugger) CaptureCodeRead(addr common.Address)                              {}
+func (d *Debugger) CaptureCodeChange(addr common.Address, code []byte)               {}
+func (d *Debugger) CaptureLog(log *types.Log)                                        {}
+func (d *Debugger) CaptureSelfDestruct(addr common.Address, balance *big.Int)        {}
+func (d *Debugger) CaptureAccountDelete(addr common.Address)                         {}
+
+// CaptureStorageRead tracks the storage slot for the storage command.
+func (d *Debugger) CaptureStorageRead(addr common.Address, key, value common.Hash) {
+	d.trackSlot(addr, key)
+}
+
+// CaptureStorageWrite tracks the storage slot for the storage command.
+func (d *Debugger) CaptureStorageWrite(addr common.Address, key, prev, value common.Hash) {
+	d.trackSlot(addr, key)
+}
+
+func (d *Debugger) trackSlot(addr common.Address, key common.Hash) {
+	if d.storage[addr] == nil {
+		d.storage[addr] = make(map[common.Hash]struct{})
+	}
+	d.storage[addr][key] = struct{}{}
+}
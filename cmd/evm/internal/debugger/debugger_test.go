@@ -0,0 +1,165 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package debugger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// Tests that a scripted debugging session stops at the expected instructions.
+func TestScriptedSession(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+
+	statedb.SetCode(common.HexToAddress("0x0b"), []byte{
+		byte(vm.PUSH1), 1,
+		byte(vm.PUSH1), 0,
+		byte(vm.SSTORE),
+		byte(vm.STOP),
+	})
+	code := []byte{
+		byte(vm.PUSH1), 0,
+		byte(vm.DUP1),
+		byte(vm.DUP1),
+		byte(vm.DUP1),
+		byte(vm.DUP1),
+		byte(vm.PUSH1), 0x0b,
+		byte(vm.GAS),
+		byte(vm.CALL),
+		byte(vm.STOP),
+	}
+	script := strings.Join([]string{
+		"break op sstore",
+		"continue",
+		"stack",
+		"step",
+		"storage",
+		"out",
+	}, "\n")
+	out := new(bytes.Buffer)
+	debugger := New(strings.NewReader(script), out)
+
+	_, _, err := runtime.Execute(code, nil, &runtime.Config{
+		State:     statedb,
+		EVMConfig: vm.Config{Debug: true, Tracer: debugger},
+	})
+	if err != nil {
+		t.Fatalf("execution failed: %v", err)
+	}
+	for _, want := range []string{
+		"pc=0 op=PUSH1 gas=",
+		"breakpoint #0: op SSTORE",
+		"pc=4 op=SSTORE",
+		"0000: 0000000000000000000000000000000000000000000000000000000000000000\n0001: 0000000000000000000000000000000000000000000000000000000000000001\n",
+		"pc=5 op=STOP",
+		"0000000000000000000000000000000000000000000000000000000000000000: 0000000000000000000000000000000000000000000000000000000000000001\n",
+		"pc=10 op=STOP",
+		"execution finished: output=0x",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+	if strings.Count(out.String(), "pc=") != 4 {
+		t.Errorf("pause count mismatch:\n%s", out.String())
+	}
+}
+
+// Tests that depth breakpoints only stop when a call frame is entered, not when
+// the execution returns into it from a nested call.
+func TestDepthBreakpoint(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+
+	statedb.SetCode(common.HexToAddress("0x0b"), []byte{byte(vm.STOP)})
+	code := []byte{
+		byte(vm.PUSH1), 0,
+		byte(vm.DUP1),
+		byte(vm.DUP1),
+		byte(vm.DUP1),
+		byte(vm.DUP1),
+		byte(vm.PUSH1), 0x0b,
+		byte(vm.GAS),
+		byte(vm.CALL),
+		byte(vm.STOP),
+	}
+	tests := []struct {
+		depth string
+		want  []string
+	}{
+		{"1", nil},
+		{"2", []string{"pc=0 op=STOP gas="}},
+	}
+	for i, tt := range tests {
+		out := new(bytes.Buffer)
+		debugger := New(strings.NewReader("break depth "+tt.depth+"\ncontinue\ncontinue\n"), out)
+
+		if _, _, err := runtime.Execute(code, nil, &runtime.Config{
+			State:     statedb.Copy(),
+			EVMConfig: vm.Config{Debug: true, Tracer: debugger},
+		}); err != nil {
+			t.Fatalf("test %d: execution failed: %v", i, err)
+		}
+		// The first pause is the initial step before the first instruction
+		if have := strings.Count(out.String(), "pc="); have != len(tt.want)+1 {
+			t.Errorf("test %d: pause count mismatch: have %d, want %d:\n%s", i, have, len(tt.want)+1, out.String())
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("test %d: output missing %q:\n%s", i, want, out.String())
+			}
+		}
+	}
+}
+
+// Tests the parsing of breakpoint specifications.
+func TestParseBreakpoint(t *testing.T) {
+	tests := []struct {
+		spec string
+		want Breakpoint
+		fail bool
+	}{
+		{spec: "pc 10", want: Breakpoint{BreakPC, 10}},
+		{spec: "pc 0x10", want: Breakpoint{BreakPC, 16}},
+		{spec: "op sload", want: Breakpoint{BreakOp, uint64(vm.SLOAD)}},
+		{spec: "op STOP", want: Breakpoint{BreakOp, uint64(vm.STOP)}},
+		{spec: "depth 2", want: Breakpoint{BreakDepth, 2}},
+		{spec: "op FOO", fail: true},
+		{spec: "line 1", fail: true},
+		{spec: "pc", fail: true},
+	}
+	for i, tt := range tests {
+		b, err := ParseBreakpoint(tt.spec)
+		if tt.fail {
+			if err == nil {
+				t.Errorf("test %d: expected failure, got %v", i, b)
+			}
+			continue
+		}
+		if err != nil || b != tt.want {
+			t.Errorf("test %d: breakpoint mismatch: have %v/%v, want %v", i, b, err, tt.want)
+		}
+	}
+}
@@ -43,6 +43,10 @@ var (
 		Name:  "cpuprofile",
 		Usage: "creates a CPU profile at the given path",
 	}
+	EVMProfileFlag = cli.StringFlag{
+		Name:  "evmprofile",
+		Usage: "creates a pprof profile of the executed EVM opcodes at the given path",
+	}
 	StatDumpFlag = cli.BoolFlag{
 		Name:  "statdump",
 		Usage: "displays stack and heap memory information",
@@ -131,6 +135,7 @@ func init() {
 		DisableGasMeteringFlag,
 		MemProfileFlag,
 		CPUProfileFlag,
+		EVMProfileFlag,
 		StatDumpFlag,
 		GenesisFlag,
 		MachineFlag,
@@ -141,6 +146,7 @@ func init() {
 	}
 	app.Commands = []cli.Command{
 		compileCommand,
+		debugCommand,
 		disasmCommand,
 		runCommand,
 		stateTestCommand,
@@ -18,7 +18,9 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
@@ -30,6 +32,11 @@ import (
 )
 
 var (
+	mnemonicFlag = cli.BoolFlag{
+		Name:  "mnemonic",
+		Usage: "Use a BIP-39 mnemonic backed HD wallet instead of a single key",
+	}
+
 	walletCommand = cli.Command{
 		Name:      "wallet",
 		Usage:     "Manage Ethereum presale wallets",
@@ -52,6 +59,9 @@ passwordfile as argument containing the wallet password in plaintext.`,
 				Flags: []cli.Flag{
 					utils.DataDirFlag,
 					utils.KeyStoreDirFlag,
+					utils.KeyStoreBackendFlag,
+					utils.KeyStoreURLFlag,
+					utils.KeyStoreTokenFileFlag,
 					utils.PasswordFileFlag,
 					utils.LightKDFFlag,
 				},
@@ -84,7 +94,8 @@ either new or import). Without it you are not able to unlock your account.
 
 Note that exporting your key in unencrypted format is NOT supported.
 
-Keys are stored under <DATADIR>/keystore.
+Keys are stored under <DATADIR>/keystore, the encrypted seeds of mnemonic based
+HD wallets under <DATADIR>/keystore/hd.
 It is safe to transfer the entire directory or the individual keys therein
 between ethereum nodes by simply copying.
 
@@ -97,6 +108,9 @@ Make sure you backup your keys regularly.`,
 				Flags: []cli.Flag{
 					utils.DataDirFlag,
 					utils.KeyStoreDirFlag,
+					utils.KeyStoreBackendFlag,
+					utils.KeyStoreURLFlag,
+					utils.KeyStoreTokenFileFlag,
 				},
 				Description: `
 Print a short summary of all accounts`,
@@ -108,8 +122,12 @@ Print a short summary of all accounts`,
 				Flags: []cli.Flag{
 					utils.DataDirFlag,
 					utils.KeyStoreDirFlag,
+					utils.KeyStoreBackendFlag,
+					utils.KeyStoreURLFlag,
+					utils.KeyStoreTokenFileFlag,
 					utils.PasswordFileFlag,
 					utils.LightKDFFlag,
+					mnemonicFlag,
 				},
 				Description: `
     geth account new
@@ -124,6 +142,15 @@ For non-interactive use the passphrase can be specified with the --password flag
 
 Note, this is meant to be used for testing only, it is a bad idea to save your
 password to file or expose in any other way.
+
+    geth account new --mnemonic
+
+Generates a new 24 word mnemonic and creates an HD wallet from it, printing the
+mnemonic and the address of its first account (m/44'/60'/0'/0/0). Write down the
+mnemonic, it is the only way to restore the wallet if the keystore is lost.
+
+The seed of the wallet is saved in encrypted format, you are prompted for a
+passphrase. Further accounts can be derived once the wallet is opened.
 `,
 			},
 			{
@@ -134,6 +161,9 @@ password to file or expose in any other way.
 				Flags: []cli.Flag{
 					utils.DataDirFlag,
 					utils.KeyStoreDirFlag,
+					utils.KeyStoreBackendFlag,
+					utils.KeyStoreURLFlag,
+					utils.KeyStoreTokenFileFlag,
 					utils.LightKDFFlag,
 				},
 				Description: `
@@ -162,8 +192,12 @@ changing your password is only possible interactively.
 				Flags: []cli.Flag{
 					utils.DataDirFlag,
 					utils.KeyStoreDirFlag,
+					utils.KeyStoreBackendFlag,
+					utils.KeyStoreURLFlag,
+					utils.KeyStoreTokenFileFlag,
 					utils.PasswordFileFlag,
 					utils.LightKDFFlag,
+					mnemonicFlag,
 				},
 				ArgsUsage: "<keyFile>",
 				Description: `
@@ -182,6 +216,11 @@ For non-interactive use the passphrase can be specified with the -password flag:
 
     geth account import [options] <keyfile>
 
+    geth account import --mnemonic [<mnemonicfile>]
+
+Imports a BIP-39 mnemonic as an HD wallet, read from <mnemonicfile> if given or
+prompted for otherwise. Prints the address of its first account.
+
 Note:
 As you can directly copy your encrypted accounts to another ethereum instance,
 this import mechanism is not needed when you transfer an account between
@@ -204,16 +243,21 @@ func accountList(ctx *cli.Context) error {
 	return nil
 }
 
-// tries unlocking the specified account a few times.
-func unlockAccount(ctx *cli.Context, ks *keystore.KeyStore, address string, i int, passwords []string) (accounts.Account, string) {
+// tries unlocking the specified account a few times. Accounts derived from an
+// HD wallet of hks, if given, are unlocked through it instead of ks.
+func unlockAccount(ctx *cli.Context, ks *keystore.KeyStore, hks *keystore.HDKeyStore, address string, i int, passwords []string) (accounts.Account, string) {
 	account, err := utils.MakeAddress(ks, address)
 	if err != nil {
 		utils.Fatalf("Could not list accounts: %v", err)
 	}
+	unlock := ks.Unlock
+	if hks != nil && hks.HasAddress(account.Address) {
+		unlock = hks.Unlock
+	}
 	for trials := 0; trials < 3; trials++ {
 		prompt := fmt.Sprintf("Unlocking account %s | Attempt %d/%d", address, trials+1, 3)
 		password := getPassPhrase(prompt, false, i, passwords)
-		err = ks.Unlock(account, password)
+		err = unlock(account, password)
 		if err == nil {
 			log.Info("Unlocked account", "address", account.Address.Hex())
 			return account, password
@@ -304,9 +348,36 @@ func accountCreate(ctx *cli.Context) error {
 	if err != nil {
 		utils.Fatalf("Failed to read configuration: %v", err)
 	}
-
+	store, err := cfg.Node.SecretStore()
+	if err != nil {
+		utils.Fatalf("Failed to open key store: %v", err)
+	}
+	if closer, ok := store.(io.Closer); ok {
+		defer closer.Close()
+	}
+	// HD wallet seeds are always kept in key files, refuse to bypass the backend
+	if store != nil && ctx.Bool(mnemonicFlag.Name) {
+		utils.Fatalf("HD wallets are not supported by the %q key store backend", cfg.Node.KeyStoreBackend)
+	}
 	password := getPassPhrase("Your new account is locked with a password. Please give a password. Do not forget this password.", true, 0, utils.MakePasswordList(ctx))
 
+	if ctx.Bool(mnemonicFlag.Name) {
+		mnemonic, wallet, err := keystore.NewHDKeyStore(keydir, scryptN, scryptP).NewMnemonic(password)
+		if err != nil {
+			utils.Fatalf("Failed to create HD wallet: %v", err)
+		}
+		fmt.Printf("Mnemonic: %s\n", mnemonic)
+		fmt.Printf("Address: {%x}\n", wallet.Accounts()[0].Address)
+		return nil
+	}
+	if store != nil {
+		account, err := keystore.NewSecretKeyStore(store, scryptN, scryptP).NewAccount(password)
+		if err != nil {
+			utils.Fatalf("Failed to create account: %v", err)
+		}
+		fmt.Printf("Address: {%x}\n", account.Address)
+		return nil
+	}
 	address, err := keystore.StoreKey(keydir, password, scryptN, scryptP)
 
 	if err != nil {
@@ -326,7 +397,7 @@ func accountUpdate(ctx *cli.Context) error {
 	ks := stack.AccountManager().Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
 
 	for _, addr := range ctx.Args() {
-		account, oldPassword := unlockAccount(ctx, ks, addr, 0, nil)
+		account, oldPassword := unlockAccount(ctx, ks, nil, addr, 0, nil)
 		newPassword := getPassPhrase("Please give a new password. Do not forget this password.", true, 0, nil)
 		if err := ks.Update(account, oldPassword, newPassword); err != nil {
 			utils.Fatalf("Could not update the account: %v", err)
@@ -358,6 +429,9 @@ func importWallet(ctx *cli.Context) error {
 }
 
 func accountImport(ctx *cli.Context) error {
+	if ctx.Bool(mnemonicFlag.Name) {
+		return accountImportMnemonic(ctx)
+	}
 	keyfile := ctx.Args().First()
 	if len(keyfile) == 0 {
 		utils.Fatalf("keyfile must be given as argument")
@@ -377,3 +451,38 @@ func accountImport(ctx *cli.Context) error {
 	fmt.Printf("Address: {%x}\n", acct.Address)
 	return nil
 }
+
+// accountImportMnemonic imports a BIP-39 mnemonic as an HD wallet, reading it
+// from the file given as argument or prompting for it.
+func accountImportMnemonic(ctx *cli.Context) error {
+	var mnemonic string
+	if file := ctx.Args().First(); file != "" {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			utils.Fatalf("Failed to read the mnemonic: %v", err)
+		}
+		mnemonic = string(content)
+	} else {
+		input, err := console.Stdin.PromptPassword("Mnemonic: ")
+		if err != nil {
+			utils.Fatalf("Failed to read the mnemonic: %v", err)
+		}
+		mnemonic = input
+	}
+	mnemonic = strings.Join(strings.Fields(mnemonic), " ")
+
+	stack, cfg := makeConfigNode(ctx)
+	backends := stack.AccountManager().Backends(keystore.HDKeyStoreType)
+	if len(backends) == 0 {
+		utils.Fatalf("HD wallets are not supported by the %q key store backend", cfg.Node.KeyStoreBackend)
+	}
+	passphrase := getPassPhrase("Your new wallet is locked with a password. Please give a password. Do not forget this password.", true, 0, utils.MakePasswordList(ctx))
+
+	hks := backends[0].(*keystore.HDKeyStore)
+	wallet, err := hks.ImportMnemonic(mnemonic, passphrase)
+	if err != nil {
+		utils.Fatalf("Could not create the HD wallet: %v", err)
+	}
+	fmt.Printf("Address: {%x}\n", wallet.Accounts()[0].Address)
+	return nil
+}
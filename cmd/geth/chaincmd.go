@@ -31,6 +31,7 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth"
 	"github.com/ethereum/go-ethereum/eth/downloader"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
@@ -138,6 +139,31 @@ Remove blockchain and state databases`,
 The arguments are interpreted as block numbers or hashes.
 Use "ethereum dump 0" to dump the genesis block.`,
 	}
+	verifyStateCommand = cli.Command{
+		Action:    utils.MigrateFlags(verifyState),
+		Name:      "verify-state",
+		Usage:     "Verify the integrity of a state trie",
+		ArgsUsage: "[<stateRoot>]",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.CacheFlag,
+			utils.TestnetFlag,
+			utils.RinkebyFlag,
+			verifyRepairFlag,
+		},
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `
+The verify-state command walks the account trie, every storage trie and all the
+contract codes of the given state root (or of the current head block if omitted),
+reporting any missing or corrupt entries along with their trie paths.
+
+With --repair, the node is started and the damaged entries are retrieved again
+from the network peers via the state sync.`,
+	}
+	verifyRepairFlag = cli.BoolFlag{
+		Name:  "repair",
+		Usage: "Retrieve missing or corrupt state entries from the network",
+	}
 )
 
 // initGenesis will initialise the given JSON format genesis file and writes it as
@@ -390,6 +416,75 @@ func dump(ctx *cli.Context) error {
 	return nil
 }
 
+func verifyState(ctx *cli.Context) error {
+	stack := makeFullNode(ctx)
+
+	// Open the database directly, or through a running node if repairing
+	var (
+		chainDb ethdb.Database
+		dl      *downloader.Downloader
+	)
+	repair := ctx.Bool(verifyRepairFlag.Name)
+	if repair {
+		utils.StartNode(stack)
+		defer stack.Stop()
+
+		var ethereum *eth.Ethereum
+		if err := stack.Service(&ethereum); err != nil {
+			utils.Fatalf("Ethereum service not running: %v", err)
+		}
+		chainDb, dl = ethereum.ChainDb(), ethereum.Downloader()
+	} else {
+		chainDb = utils.MakeChainDatabase(ctx, stack)
+		defer chainDb.Close()
+	}
+	// Resolve the state root to verify, defaulting to the head block
+	var root common.Hash
+	if arg := ctx.Args().First(); arg != "" {
+		root = common.HexToHash(arg)
+	} else {
+		hash := core.GetHeadBlockHash(chainDb)
+		header := core.GetHeader(chainDb, hash, core.GetBlockNumber(chainDb, hash))
+		if header == nil {
+			utils.Fatalf("Failed to retrieve head block %x", hash)
+		}
+		root = header.Root
+	}
+	log.Info("Verifying state", "root", root)
+	start := time.Now()
+
+	result, err := state.VerifyState(state.NewDatabase(chainDb), root)
+	if err != nil {
+		utils.Fatalf("State verification failed: %v", err)
+	}
+	for _, issue := range result.Issues {
+		fmt.Println(issue)
+	}
+	fmt.Printf("Verified %d accounts, %d account nodes, %d storage nodes and %d codes in %v\n",
+		result.Accounts, result.AccountNodes, result.StorageNodes, result.Codes, common.PrettyDuration(time.Since(start)))
+	fmt.Printf("Found %d missing or corrupt entries\n", len(result.Issues))
+
+	if !repair || len(result.Issues) == 0 {
+		return nil
+	}
+	// Drop any corrupt entries and retrieve everything damaged from the network
+	for _, issue := range result.Issues {
+		if issue.Corrupt {
+			if err := chainDb.Delete(issue.Hash.Bytes()); err != nil {
+				utils.Fatalf("Failed to delete corrupt entry %x: %v", issue.Hash, err)
+			}
+		}
+	}
+	log.Info("Repairing state", "entries", len(result.Issues))
+	start = time.Now()
+
+	if err := dl.RepairState(state.NewStateRepair(result.Issues, chainDb)); err != nil {
+		utils.Fatalf("State repair failed: %v", err)
+	}
+	fmt.Printf("State repair done in %v\n", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
 // hashish returns true for strings that look like hashes.
 func hashish(x string) bool {
 	_, err := strconv.Atoi(x)
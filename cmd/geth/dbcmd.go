@@ -0,0 +1,111 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/olekukonko/tablewriter"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	dbCommand = cli.Command{
+		Name:     "db",
+		Usage:    "Low level database operations",
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `
+Low level operations on the chain database, mostly useful for debugging and
+maintenance of a node's data directory.`,
+		Subcommands: []cli.Command{
+			{
+				Name:      "inspect",
+				Usage:     "Inspect the storage size for each type of data in the database",
+				ArgsUsage: " ",
+				Action:    utils.MigrateFlags(inspectDatabase),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.CacheFlag,
+					utils.LightModeFlag,
+					utils.TestnetFlag,
+					utils.RinkebyFlag,
+				},
+				Description: `
+    geth db inspect
+
+Iterates over the entire chain database and reports the number of entries and
+the storage size taken up by headers, bodies, receipts, transaction lookups,
+bloom bits, preimages and trie nodes. Depending on the size of the database
+this may take a long time.`,
+			},
+		},
+	}
+)
+
+// inspectDatabase iterates over the chain database and prints a per category
+// breakdown of the entry counts and storage sizes.
+func inspectDatabase(ctx *cli.Context) error {
+	stack := makeFullNode(ctx)
+	chainDb := utils.MakeChainDatabase(ctx, stack)
+	defer chainDb.Close()
+
+	db, ok := chainDb.(core.DatabaseIteratee)
+	if !ok {
+		utils.Fatalf("Database does not support iteration")
+	}
+	start := time.Now()
+
+	stats, err := core.InspectDatabase(db)
+	if err != nil {
+		utils.Fatalf("Failed to inspect database: %v", err)
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Category", "Items", "Size"})
+	table.SetAlignment(tablewriter.ALIGN_RIGHT)
+
+	for _, row := range []struct {
+		name string
+		stat core.DatabaseStat
+	}{
+		{"Headers", stats.Headers},
+		{"Total difficulties", stats.TotalDiffs},
+		{"Canonical hashes", stats.CanonicalHashes},
+		{"Hash to number", stats.HashNumbers},
+		{"Bodies", stats.Bodies},
+		{"Receipts", stats.Receipts},
+		{"Transaction lookups", stats.TxLookups},
+		{"Bloom bits", stats.BloomBits},
+		{"Preimages", stats.Preimages},
+		{"Contract ABIs", stats.ContractABIs},
+		{"Trie nodes and codes", stats.TrieNodes},
+		{"Metadata", stats.Metadata},
+		{"Unaccounted", stats.Unaccounted},
+	} {
+		table.Append([]string{row.name, fmt.Sprintf("%d", row.stat.Count), row.stat.Size.String()})
+	}
+	total := stats.Total()
+	table.Append([]string{"Total", fmt.Sprintf("%d", total.Count), total.Size.String()})
+	table.Render()
+
+	fmt.Printf("Inspection done in %v\n", common.PrettyDuration(time.Since(start)))
+	return nil
+}
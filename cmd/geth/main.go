@@ -55,11 +55,15 @@ var (
 		utils.IdentityFlag,
 		utils.UnlockedAccountFlag,
 		utils.PasswordFileFlag,
+		utils.ExternalSignerFlag,
 		utils.BootnodesFlag,
 		utils.BootnodesV4Flag,
 		utils.BootnodesV5Flag,
 		utils.DataDirFlag,
 		utils.KeyStoreDirFlag,
+		utils.KeyStoreBackendFlag,
+		utils.KeyStoreURLFlag,
+		utils.KeyStoreTokenFileFlag,
 		utils.NoUSBFlag,
 		utils.DashboardEnabledFlag,
 		utils.DashboardAddrFlag,
@@ -75,6 +79,8 @@ var (
 		utils.TxPoolNoLocalsFlag,
 		utils.TxPoolJournalFlag,
 		utils.TxPoolRejournalFlag,
+		utils.TxPoolRemoteJournalFlag,
+		utils.TxPoolRemoteJournalCapFlag,
 		utils.TxPoolPriceLimitFlag,
 		utils.TxPoolPriceBumpFlag,
 		utils.TxPoolAccountSlotsFlag,
@@ -82,6 +88,10 @@ var (
 		utils.TxPoolAccountQueueFlag,
 		utils.TxPoolGlobalQueueFlag,
 		utils.TxPoolLifetimeFlag,
+		utils.TxPoolAllowedSendersFlag,
+		utils.TxPoolBlockedDestinationsFlag,
+		utils.TxPoolSenderRateLimitFlag,
+		utils.TxPoolSenderRateWindowFlag,
 		utils.FastSyncFlag,
 		utils.LightModeFlag,
 		utils.SyncModeFlag,
@@ -101,6 +111,8 @@ var (
 		utils.MinerThreadsFlag,
 		utils.MiningEnabledFlag,
 		utils.TargetGasLimitFlag,
+		utils.MinerOrderingFlag,
+		utils.MinerPrioritySendersFlag,
 		utils.NATFlag,
 		utils.NoDiscoverFlag,
 		utils.DiscoveryV5Flag,
@@ -158,6 +170,9 @@ func init() {
 		copydbCommand,
 		removedbCommand,
 		dumpCommand,
+		verifyStateCommand,
+		// See dbcmd.go:
+		dbCommand,
 		// See monitorcmd.go:
 		monitorCommand,
 		// See accountcmd.go:
@@ -230,11 +245,16 @@ func startNode(ctx *cli.Context, stack *node.Node) {
 	// Unlock any account specifically requested
 	ks := stack.AccountManager().Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
 
+	var hks *keystore.HDKeyStore
+	if backends := stack.AccountManager().Backends(keystore.HDKeyStoreType); len(backends) > 0 {
+		hks = backends[0].(*keystore.HDKeyStore)
+	}
+
 	passwords := utils.MakePasswordList(ctx)
 	unlocks := strings.Split(ctx.GlobalString(utils.UnlockedAccountFlag.Name), ",")
 	for i, account := range unlocks {
 		if trimmed := strings.TrimSpace(account); trimmed != "" {
-			unlockAccount(ctx, ks, trimmed, i, passwords)
+			unlockAccount(ctx, ks, hks, trimmed, i, passwords)
 		}
 	}
 	// Register wallet event handlers to open and auto-derive wallets
@@ -249,8 +269,12 @@ func startNode(ctx *cli.Context, stack *node.Node) {
 		}
 		stateReader := ethclient.NewClient(rpcClient)
 
-		// Open any wallets already attached
+		// Open any wallets already attached, keystore ones are opened with their
+		// passphrase on demand
 		for _, wallet := range stack.AccountManager().Wallets() {
+			if wallet.URL().Scheme == keystore.KeyStoreScheme {
+				continue
+			}
 			if err := wallet.Open(""); err != nil {
 				log.Warn("Failed to open wallet", "url", wallet.URL(), "err", err)
 			}
@@ -259,6 +283,10 @@ func startNode(ctx *cli.Context, stack *node.Node) {
 		for event := range events {
 			switch event.Kind {
 			case accounts.WalletArrived:
+				// Keystore wallets are opened with their passphrase on demand
+				if event.Wallet.URL().Scheme == keystore.KeyStoreScheme {
+					break
+				}
 				if err := event.Wallet.Open(""); err != nil {
 					log.Warn("New wallet appeared, failed to open", "url", event.Wallet.URL(), "err", err)
 				}
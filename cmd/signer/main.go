@@ -0,0 +1,234 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+// signer is a utility that can be used to sign transactions and arbitrary data,
+// holding the accounts outside of the node and requiring every request to be
+// approved through a UI, optionally guarded by a set of rules.
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/accounts/usbwallet"
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core"
+	"github.com/ethereum/go-ethereum/signer/rules"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// Git SHA1 commit hash of the release (set via linker flags)
+var gitCommit = ""
+
+var (
+	logLevelFlag = cli.IntFlag{
+		Name:  "loglevel",
+		Value: int(log.LvlInfo),
+		Usage: "log level to emit to the screen (0-5)",
+	}
+	keystoreFlag = cli.StringFlag{
+		Name:  "keystore",
+		Value: filepath.Join(node.DefaultDataDir(), "keystore"),
+		Usage: "Directory for the keystore",
+	}
+	lightKDFFlag = cli.BoolFlag{
+		Name:  "lightkdf",
+		Usage: "Reduce key-derivation RAM & CPU usage at some expense of KDF strength",
+	}
+	noUSBFlag = cli.BoolFlag{
+		Name:  "nousb",
+		Usage: "Disables monitoring for and managing USB hardware wallets",
+	}
+	chainIdFlag = cli.Int64Flag{
+		Name:  "chainid",
+		Value: 1,
+		Usage: "Chain id to use for signing (1=mainnet, 3=ropsten, 4=rinkeby)",
+	}
+	rulesFlag = cli.StringFlag{
+		Name:  "rules",
+		Usage: "JSON file with the rules (value limits, allowed destinations) to enforce on transactions",
+	}
+	ipcDisabledFlag = cli.BoolFlag{
+		Name:  "ipcdisable",
+		Usage: "Disable the IPC-RPC server",
+	}
+	ipcPathFlag = cli.StringFlag{
+		Name:  "ipcpath",
+		Value: filepath.Join(node.DefaultDataDir(), "signer.ipc"),
+		Usage: "Filename for IPC socket/pipe",
+	}
+	rpcEnabledFlag = cli.BoolFlag{
+		Name:  "rpc",
+		Usage: "Enable the HTTP-RPC server",
+	}
+	rpcListenAddrFlag = cli.StringFlag{
+		Name:  "rpcaddr",
+		Value: "localhost",
+		Usage: "HTTP-RPC server listening interface",
+	}
+	rpcPortFlag = cli.IntFlag{
+		Name:  "rpcport",
+		Value: 8550,
+		Usage: "HTTP-RPC server listening port",
+	}
+	rpcCORSFlag = cli.StringFlag{
+		Name:  "rpccorsdomain",
+		Usage: "Comma separated list of domains from which to accept cross origin requests (browser enforced)",
+	}
+)
+
+var app = utils.NewApp(gitCommit, "Manage Ethereum account operations")
+
+func init() {
+	app.Name = "signer"
+	app.Flags = []cli.Flag{
+		logLevelFlag,
+		keystoreFlag,
+		lightKDFFlag,
+		noUSBFlag,
+		chainIdFlag,
+		rulesFlag,
+		ipcDisabledFlag,
+		ipcPathFlag,
+		rpcEnabledFlag,
+		rpcListenAddrFlag,
+		rpcPortFlag,
+		rpcCORSFlag,
+	}
+	app.Action = signer
+}
+
+func main() {
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func signer(ctx *cli.Context) error {
+	log.Root().SetHandler(log.LvlFilterHandler(log.Lvl(ctx.Int(logLevelFlag.Name)), log.StreamHandler(os.Stderr, log.TerminalFormat(true))))
+
+	// Assemble the UI, guarding it with the rules if requested
+	var ui core.SignerUI = core.NewCommandlineUI()
+	if path := ctx.String(rulesFlag.Name); path != "" {
+		config, err := rules.LoadConfig(path)
+		if err != nil {
+			return err
+		}
+		ui = rules.NewRulesetUI(ui, config)
+		log.Info("Loaded signing rules", "path", path)
+	}
+	// Assemble the account manager and the signer API on top
+	am, err := makeAccountManager(ctx.String(keystoreFlag.Name), ctx.Bool(lightKDFFlag.Name), ctx.Bool(noUSBFlag.Name))
+	if err != nil {
+		return err
+	}
+	defer am.Close()
+
+	api := core.NewSignerAPI(am, big.NewInt(ctx.Int64(chainIdFlag.Name)), ui)
+
+	server := rpc.NewServer()
+	if err := server.RegisterName("account", api); err != nil {
+		return err
+	}
+	defer server.Stop()
+
+	info := map[string]interface{}{
+		"extapi_version": core.ExternalAPIVersion,
+	}
+	// Start the requested endpoints for the signer API
+	if ctx.Bool(rpcEnabledFlag.Name) {
+		endpoint := fmt.Sprintf("%s:%d", ctx.String(rpcListenAddrFlag.Name), ctx.Int(rpcPortFlag.Name))
+		listener, err := net.Listen("tcp", endpoint)
+		if err != nil {
+			return err
+		}
+		defer listener.Close()
+
+		var cors []string
+		if domains := ctx.String(rpcCORSFlag.Name); domains != "" {
+			cors = strings.Split(domains, ",")
+		}
+		go rpc.NewHTTPServer(cors, server).Serve(listener)
+
+		info["extapi_http"] = fmt.Sprintf("http://%s", endpoint)
+		log.Info("HTTP endpoint opened", "url", info["extapi_http"])
+	}
+	if !ctx.Bool(ipcDisabledFlag.Name) {
+		endpoint := ctx.String(ipcPathFlag.Name)
+		listener, err := rpc.CreateIPCListener(endpoint)
+		if err != nil {
+			return err
+		}
+		defer listener.Close()
+
+		go server.ServeListener(listener)
+
+		info["extapi_ipc"] = endpoint
+		log.Info("IPC endpoint opened", "url", endpoint)
+	}
+	ui.OnSignerStartup(core.StartupInfo{Info: info})
+
+	// Serve requests until interrupted
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigc)
+
+	sig := <-sigc
+	log.Info("Exiting...", "signal", sig)
+	return nil
+}
+
+// makeAccountManager creates the account manager with the keystore in the given
+// directory and the USB hardware wallets, unless disabled.
+func makeAccountManager(keydir string, lightKDF, noUSB bool) (*accounts.Manager, error) {
+	scryptN, scryptP := keystore.StandardScryptN, keystore.StandardScryptP
+	if lightKDF {
+		scryptN, scryptP = keystore.LightScryptN, keystore.LightScryptP
+	}
+	if err := os.MkdirAll(keydir, 0700); err != nil {
+		return nil, err
+	}
+	backends := []accounts.Backend{
+		keystore.NewKeyStore(keydir, scryptN, scryptP),
+	}
+	if !noUSB {
+		// Start a USB hub for Ledger hardware wallets
+		if ledgerhub, err := usbwallet.NewLedgerHub(); err != nil {
+			log.Warn(fmt.Sprintf("Failed to start Ledger hub, disabling: %v", err))
+		} else {
+			backends = append(backends, ledgerhub)
+		}
+		// Start a USB hub for Trezor hardware wallets
+		if trezorhub, err := usbwallet.NewTrezorHub(); err != nil {
+			log.Warn(fmt.Sprintf("Failed to start Trezor hub, disabling: %v", err))
+		} else {
+			backends = append(backends, trezorhub)
+		}
+	}
+	return accounts.NewManager(backends...), nil
+}
@@ -122,6 +122,19 @@ var (
 		Name:  "keystore",
 		Usage: "Directory for the keystore (default = inside the datadir)",
 	}
+	KeyStoreBackendFlag = cli.StringFlag{
+		Name:  "keystore.backend",
+		Usage: `Key storage backend ("file", "db" or "vault")`,
+		Value: "file",
+	}
+	KeyStoreURLFlag = cli.StringFlag{
+		Name:  "keystore.url",
+		Usage: "Key database path (default = inside the datadir) or Vault secrets endpoint",
+	}
+	KeyStoreTokenFileFlag = cli.StringFlag{
+		Name:  "keystore.tokenfile",
+		Usage: "File containing the key database passphrase or Vault access token",
+	}
 	NoUSBFlag = cli.BoolFlag{
 		Name:  "nousb",
 		Usage: "Disables monitoring for and managing USB hardware wallets",
@@ -259,6 +272,16 @@ var (
 		Usage: "Time interval to regenerate the local transaction journal",
 		Value: core.DefaultTxPoolConfig.Rejournal,
 	}
+	TxPoolRemoteJournalFlag = cli.StringFlag{
+		Name:  "txpool.remotejournal",
+		Usage: "Disk journal for remote transactions to survive node restarts (disabled if empty)",
+		Value: core.DefaultTxPoolConfig.RemoteJournal,
+	}
+	TxPoolRemoteJournalCapFlag = cli.Uint64Flag{
+		Name:  "txpool.remotejournalcap",
+		Usage: "Maximum number of remote transactions to journal (0 = unlimited)",
+		Value: core.DefaultTxPoolConfig.RemoteJournalCap,
+	}
 	TxPoolPriceLimitFlag = cli.Uint64Flag{
 		Name:  "txpool.pricelimit",
 		Usage: "Minimum gas price limit to enforce for acceptance into the pool",
@@ -294,6 +317,24 @@ var (
 		Usage: "Maximum amount of time non-executable transaction are queued",
 		Value: eth.DefaultConfig.TxPool.Lifetime,
 	}
+	TxPoolAllowedSendersFlag = cli.StringFlag{
+		Name:  "txpool.allowedsenders",
+		Usage: "Comma separated accounts permitted to submit transactions (default = anyone)",
+	}
+	TxPoolBlockedDestinationsFlag = cli.StringFlag{
+		Name:  "txpool.blockeddestinations",
+		Usage: "Comma separated accounts and contracts transactions may not be sent to",
+	}
+	TxPoolSenderRateLimitFlag = cli.Uint64Flag{
+		Name:  "txpool.senderratelimit",
+		Usage: "Maximum number of remote transactions accepted per sender and window (0 = unlimited)",
+		Value: eth.DefaultConfig.TxPool.SenderRateLimit,
+	}
+	TxPoolSenderRateWindowFlag = cli.DurationFlag{
+		Name:  "txpool.senderratewindow",
+		Usage: "Time window of the per sender transaction rate limit",
+		Value: eth.DefaultConfig.TxPool.SenderRateWindow,
+	}
 	// Performance tuning settings
 	CacheFlag = cli.IntFlag{
 		Name:  "cache",
@@ -344,6 +385,15 @@ var (
 		Name:  "extradata",
 		Usage: "Block extra data set by the miner (default = client version)",
 	}
+	MinerOrderingFlag = cli.StringFlag{
+		Name:  "minerordering",
+		Usage: `Transaction ordering of the mined blocks ("price", "fifo", "fair" or "priority")`,
+		Value: "price",
+	}
+	MinerPrioritySendersFlag = cli.StringFlag{
+		Name:  "minerpriority",
+		Usage: "Comma separated list of senders whose transactions are mined first with the priority ordering",
+	}
 	// Account settings
 	UnlockedAccountFlag = cli.StringFlag{
 		Name:  "unlock",
@@ -355,6 +405,11 @@ var (
 		Usage: "Password file to use for non-interactive password input",
 		Value: "",
 	}
+	ExternalSignerFlag = cli.StringFlag{
+		Name:  "signer",
+		Usage: "External signer (IPC path or HTTP URL) to delegate transaction signing to",
+		Value: "",
+	}
 
 	VMEnableDebugFlag = cli.BoolFlag{
 		Name:  "vmdebug",
@@ -884,12 +939,28 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	if ctx.GlobalIsSet(KeyStoreDirFlag.Name) {
 		cfg.KeyStoreDir = ctx.GlobalString(KeyStoreDirFlag.Name)
 	}
+	if ctx.GlobalIsSet(KeyStoreBackendFlag.Name) {
+		cfg.KeyStoreBackend = ctx.GlobalString(KeyStoreBackendFlag.Name)
+	}
+	if ctx.GlobalIsSet(KeyStoreURLFlag.Name) {
+		cfg.KeyStoreURL = ctx.GlobalString(KeyStoreURLFlag.Name)
+	}
+	if ctx.GlobalIsSet(KeyStoreTokenFileFlag.Name) {
+		text, err := ioutil.ReadFile(ctx.GlobalString(KeyStoreTokenFileFlag.Name))
+		if err != nil {
+			Fatalf("Failed to read key store token file: %v", err)
+		}
+		cfg.KeyStoreToken = strings.TrimRight(string(text), "\r\n")
+	}
 	if ctx.GlobalIsSet(LightKDFFlag.Name) {
 		cfg.UseLightweightKDF = ctx.GlobalBool(LightKDFFlag.Name)
 	}
 	if ctx.GlobalIsSet(NoUSBFlag.Name) {
 		cfg.NoUSB = ctx.GlobalBool(NoUSBFlag.Name)
 	}
+	if ctx.GlobalIsSet(ExternalSignerFlag.Name) {
+		cfg.ExternalSigner = ctx.GlobalString(ExternalSignerFlag.Name)
+	}
 }
 
 func setGPO(ctx *cli.Context, cfg *gasprice.Config) {
@@ -911,6 +982,12 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolRejournalFlag.Name) {
 		cfg.Rejournal = ctx.GlobalDuration(TxPoolRejournalFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolRemoteJournalFlag.Name) {
+		cfg.RemoteJournal = ctx.GlobalString(TxPoolRemoteJournalFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolRemoteJournalCapFlag.Name) {
+		cfg.RemoteJournalCap = ctx.GlobalUint64(TxPoolRemoteJournalCapFlag.Name)
+	}
 	if ctx.GlobalIsSet(TxPoolPriceLimitFlag.Name) {
 		cfg.PriceLimit = ctx.GlobalUint64(TxPoolPriceLimitFlag.Name)
 	}
@@ -932,6 +1009,34 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolLifetimeFlag.Name) {
 		cfg.Lifetime = ctx.GlobalDuration(TxPoolLifetimeFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolAllowedSendersFlag.Name) {
+		cfg.AllowedSenders = makeAddressList(TxPoolAllowedSendersFlag.Name, ctx.GlobalString(TxPoolAllowedSendersFlag.Name))
+	}
+	if ctx.GlobalIsSet(TxPoolBlockedDestinationsFlag.Name) {
+		cfg.BlockedDestinations = makeAddressList(TxPoolBlockedDestinationsFlag.Name, ctx.GlobalString(TxPoolBlockedDestinationsFlag.Name))
+	}
+	if ctx.GlobalIsSet(TxPoolSenderRateLimitFlag.Name) {
+		cfg.SenderRateLimit = ctx.GlobalUint64(TxPoolSenderRateLimitFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolSenderRateWindowFlag.Name) {
+		cfg.SenderRateWindow = ctx.GlobalDuration(TxPoolSenderRateWindowFlag.Name)
+	}
+}
+
+// makeAddressList parses a comma separated list of hex addresses given to a
+// flag, terminating the process if any of them is invalid.
+func makeAddressList(flag string, input string) []common.Address {
+	var addrs []common.Address
+	for _, account := range splitAndTrim(input) {
+		if account == "" {
+			continue
+		}
+		if !common.IsHexAddress(account) {
+			Fatalf("Invalid account in --%s: %s", flag, account)
+		}
+		addrs = append(addrs, common.HexToAddress(account))
+	}
+	return addrs
 }
 
 func setEthash(ctx *cli.Context, cfg *eth.Config) {
@@ -1060,6 +1165,12 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 	if ctx.GlobalIsSet(GasPriceFlag.Name) {
 		cfg.GasPrice = GlobalBig(ctx, GasPriceFlag.Name)
 	}
+	if ctx.GlobalIsSet(MinerOrderingFlag.Name) {
+		cfg.MinerOrdering = ctx.GlobalString(MinerOrderingFlag.Name)
+	}
+	if ctx.GlobalIsSet(MinerPrioritySendersFlag.Name) {
+		cfg.MinerPrioritySenders = makeAddressList(MinerPrioritySendersFlag.Name, ctx.GlobalString(MinerPrioritySendersFlag.Name))
+	}
 	if ctx.GlobalIsSet(VMEnableDebugFlag.Name) {
 		// TODO(fjl): force-enable this in --dev mode
 		cfg.EnablePreimageRecording = ctx.GlobalBool(VMEnableDebugFlag.Name)
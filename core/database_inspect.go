@@ -0,0 +1,131 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+)
+
+// DatabaseIteratee wraps the NewIterator method of a backing data store.
+type DatabaseIteratee interface {
+	NewIterator() iterator.Iterator
+}
+
+// DatabaseStat accumulates the number of entries and the total size of the
+// keys and values belonging to a single data category.
+type DatabaseStat struct {
+	Count uint64             // Number of database entries in the category
+	Size  common.StorageSize // Total size of keys and values in the category
+}
+
+// add accounts a single database entry into the statistic.
+func (s *DatabaseStat) add(key, value []byte) {
+	s.Count++
+	s.Size += common.StorageSize(len(key) + len(value))
+}
+
+// DatabaseStats is a per category breakdown of the contents of a chain database.
+type DatabaseStats struct {
+	Headers         DatabaseStat // Block headers (headerPrefix + num + hash)
+	TotalDiffs      DatabaseStat // Total difficulties (headerPrefix + num + hash + tdSuffix)
+	CanonicalHashes DatabaseStat // Canonical number to hash mappings (headerPrefix + num + numSuffix)
+	HashNumbers     DatabaseStat // Hash to number mappings (blockHashPrefix + hash)
+	Bodies          DatabaseStat // Block bodies (bodyPrefix + num + hash)
+	Receipts        DatabaseStat // Block receipts (blockReceiptsPrefix + num + hash)
+	TxLookups       DatabaseStat // Transaction lookup entries (lookupPrefix + hash)
+	BloomBits       DatabaseStat // Bloom bit vectors (bloomBitsPrefix + bit + section + hash)
+	Preimages       DatabaseStat // Trie key preimages (preimagePrefix + hash)
+	ContractABIs    DatabaseStat // Registered contract ABIs (abiPrefix + address)
+	TrieNodes       DatabaseStat // Hash keyed entries: state trie nodes and contract codes
+	Metadata        DatabaseStat // Head markers, chain configs and chain indexer progress
+	Unaccounted     DatabaseStat // Anything not matching a known key layout
+}
+
+// Total returns the aggregated statistics of all the categories.
+func (s *DatabaseStats) Total() DatabaseStat {
+	var total DatabaseStat
+	for _, stat := range []DatabaseStat{
+		s.Headers, s.TotalDiffs, s.CanonicalHashes, s.HashNumbers, s.Bodies, s.Receipts,
+		s.TxLookups, s.BloomBits, s.Preimages, s.ContractABIs, s.TrieNodes, s.Metadata, s.Unaccounted,
+	} {
+		total.Count += stat.Count
+		total.Size += stat.Size
+	}
+	return total
+}
+
+// InspectDatabase iterates over the entire database and classifies every entry
+// based on its key layout, returning the number of items and storage size each
+// category takes up.
+func InspectDatabase(db DatabaseIteratee) (*DatabaseStats, error) {
+	it := db.NewIterator()
+	defer it.Release()
+
+	var (
+		stats  = new(DatabaseStats)
+		start  = time.Now()
+		logged = time.Now()
+		count  uint64
+	)
+	for it.Next() {
+		key, value := it.Key(), it.Value()
+
+		switch {
+		case bytes.HasPrefix(key, headerPrefix) && len(key) == len(headerPrefix)+8+common.HashLength:
+			stats.Headers.add(key, value)
+		case bytes.HasPrefix(key, headerPrefix) && len(key) == len(headerPrefix)+8+common.HashLength+len(tdSuffix) && bytes.HasSuffix(key, tdSuffix):
+			stats.TotalDiffs.add(key, value)
+		case bytes.HasPrefix(key, headerPrefix) && len(key) == len(headerPrefix)+8+len(numSuffix) && bytes.HasSuffix(key, numSuffix):
+			stats.CanonicalHashes.add(key, value)
+		case bytes.HasPrefix(key, blockHashPrefix) && len(key) == len(blockHashPrefix)+common.HashLength:
+			stats.HashNumbers.add(key, value)
+		case bytes.HasPrefix(key, bodyPrefix) && len(key) == len(bodyPrefix)+8+common.HashLength:
+			stats.Bodies.add(key, value)
+		case bytes.HasPrefix(key, blockReceiptsPrefix) && len(key) == len(blockReceiptsPrefix)+8+common.HashLength:
+			stats.Receipts.add(key, value)
+		case bytes.HasPrefix(key, lookupPrefix) && len(key) == len(lookupPrefix)+common.HashLength:
+			stats.TxLookups.add(key, value)
+		case bytes.HasPrefix(key, bloomBitsPrefix) && len(key) == len(bloomBitsPrefix)+2+8+common.HashLength:
+			stats.BloomBits.add(key, value)
+		case bytes.HasPrefix(key, []byte(preimagePrefix)) && len(key) == len(preimagePrefix)+common.HashLength:
+			stats.Preimages.add(key, value)
+		case bytes.HasPrefix(key, abiPrefix) && len(key) == len(abiPrefix)+common.AddressLength:
+			stats.ContractABIs.add(key, value)
+		case len(key) == common.HashLength:
+			stats.TrieNodes.add(key, value)
+		case bytes.Equal(key, headHeaderKey), bytes.Equal(key, headBlockKey), bytes.Equal(key, headFastKey),
+			bytes.Equal(key, []byte("BlockchainVersion")), bytes.HasPrefix(key, configPrefix), bytes.HasPrefix(key, []byte("i")):
+			stats.Metadata.add(key, value)
+		default:
+			stats.Unaccounted.add(key, value)
+		}
+		count++
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Inspecting database", "count", count, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// Tests that database inspection correctly classifies entries by their key layout.
+func TestInspectDatabase(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inspect-test")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := ethdb.NewLDBDatabase(dir, 0, 0)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	// Write a few blocks worth of data with all the ancillary indices
+	for i := int64(1); i <= 3; i++ {
+		tx := types.NewTransaction(uint64(i), common.Address{0x11}, big.NewInt(i), 21000, big.NewInt(1), nil)
+		block := types.NewBlock(&types.Header{Number: big.NewInt(i)}, []*types.Transaction{tx}, nil, nil)
+
+		if err := WriteBlock(db, block); err != nil {
+			t.Fatalf("failed to write block %d: %v", i, err)
+		}
+		if err := WriteTd(db, block.Hash(), block.NumberU64(), big.NewInt(i)); err != nil {
+			t.Fatalf("failed to write td %d: %v", i, err)
+		}
+		if err := WriteCanonicalHash(db, block.Hash(), block.NumberU64()); err != nil {
+			t.Fatalf("failed to write canonical hash %d: %v", i, err)
+		}
+		if err := WriteBlockReceipts(db, block.Hash(), block.NumberU64(), types.Receipts{}); err != nil {
+			t.Fatalf("failed to write receipts %d: %v", i, err)
+		}
+		if err := WriteTxLookupEntries(db, block); err != nil {
+			t.Fatalf("failed to write lookups %d: %v", i, err)
+		}
+		if err := WriteHeadBlockHash(db, block.Hash()); err != nil {
+			t.Fatalf("failed to write head block %d: %v", i, err)
+		}
+	}
+	WriteBloomBits(db, 0, 0, common.Hash{0x02}, []byte{0x01})
+	if err := WritePreimages(db, 0, map[common.Hash][]byte{{0x03}: []byte("preimage")}); err != nil {
+		t.Fatalf("failed to write preimages: %v", err)
+	}
+	if err := WriteContractABI(db, common.Address{0x04}, []byte("[]")); err != nil {
+		t.Fatalf("failed to write contract ABI: %v", err)
+	}
+	db.Put(common.Hash{0x01}.Bytes(), []byte("trie node"))
+	db.Put([]byte("unknown"), []byte("junk"))
+
+	stats, err := InspectDatabase(db)
+	if err != nil {
+		t.Fatalf("failed to inspect database: %v", err)
+	}
+	tests := []struct {
+		name string
+		stat DatabaseStat
+		want uint64
+	}{
+		{"headers", stats.Headers, 3},
+		{"tds", stats.TotalDiffs, 3},
+		{"canonical", stats.CanonicalHashes, 3},
+		{"numbers", stats.HashNumbers, 3},
+		{"bodies", stats.Bodies, 3},
+		{"receipts", stats.Receipts, 3},
+		{"lookups", stats.TxLookups, 3},
+		{"bloombits", stats.BloomBits, 1},
+		{"preimages", stats.Preimages, 1},
+		{"abis", stats.ContractABIs, 1},
+		{"trie", stats.TrieNodes, 1},
+		{"metadata", stats.Metadata, 1},
+		{"unaccounted", stats.Unaccounted, 1},
+	}
+	for _, tt := range tests {
+		if tt.stat.Count != tt.want {
+			t.Errorf("%s: item count mismatch: have %d, want %d", tt.name, tt.stat.Count, tt.want)
+		}
+		if tt.stat.Count > 0 && tt.stat.Size == 0 {
+			t.Errorf("%s: missing storage size", tt.name)
+		}
+	}
+	if total := stats.Total(); total.Count != 27 {
+		t.Errorf("total item count mismatch: have %d, want %d", total.Count, 27)
+	}
+}
@@ -60,6 +60,7 @@ var (
 
 	preimagePrefix = "secure-key-"              // preimagePrefix + hash -> preimage
 	configPrefix   = []byte("ethereum-config-") // config prefix for the db
+	abiPrefix      = []byte("contract-abi-")    // abiPrefix + address -> contract ABI JSON
 
 	// Chain index prefixes (use `i` + single byte to avoid mixing data types).
 	BloomBitsIndexPrefix = []byte("iB") // BloomBitsIndexPrefix is the data table of a chain indexer to track its progress
@@ -559,6 +560,23 @@ func WritePreimages(db ethdb.Database, number uint64, preimages map[common.Hash]
 	return nil
 }
 
+// GetContractABI retrieves the JSON ABI definition registered for a contract,
+// or nil if none was registered.
+func GetContractABI(db DatabaseReader, address common.Address) []byte {
+	data, _ := db.Get(append(abiPrefix, address.Bytes()...))
+	return data
+}
+
+// WriteContractABI stores the JSON ABI definition of a contract into the database.
+func WriteContractABI(db ethdb.Putter, address common.Address, abi []byte) error {
+	return db.Put(append(abiPrefix, address.Bytes()...), abi)
+}
+
+// DeleteContractABI removes the JSON ABI definition registered for a contract.
+func DeleteContractABI(db DatabaseDeleter, address common.Address) {
+	db.Delete(append(abiPrefix, address.Bytes()...))
+}
+
 // GetBlockChainVersion reads the version number from db.
 func GetBlockChainVersion(db DatabaseReader) int {
 	var vsn uint
@@ -386,3 +386,27 @@ func TestBlockReceiptStorage(t *testing.T) {
 		t.Fatalf("deleted receipts returned: %v", rs)
 	}
 }
+
+// Tests contract ABI storage and retrieval operations.
+func TestContractABIStorage(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+
+	address := common.Address{0x01}
+	if abi := GetContractABI(db, address); abi != nil {
+		t.Fatalf("non existent ABI returned: %s", abi)
+	}
+	abi := []byte(`[{"type":"function","name":"foo","inputs":[]}]`)
+	if err := WriteContractABI(db, address, abi); err != nil {
+		t.Fatalf("failed to write contract ABI: %v", err)
+	}
+	if have := GetContractABI(db, address); !bytes.Equal(have, abi) {
+		t.Fatalf("ABI mismatch: have %s, want %s", have, abi)
+	}
+	if have := GetContractABI(db, common.Address{0x02}); have != nil {
+		t.Fatalf("ABI returned for unregistered contract: %s", have)
+	}
+	DeleteContractABI(db, address)
+	if have := GetContractABI(db, address); have != nil {
+		t.Fatalf("deleted ABI returned: %s", have)
+	}
+}
@@ -24,6 +24,19 @@ import (
 // TxPreEvent is posted when a transaction enters the transaction pool.
 type TxPreEvent struct{ Tx *types.Transaction }
 
+// TxQueuedEvent is posted when a transaction enters the non-executable queue of
+// the transaction pool, either as a new future transaction or demoted from the
+// pending set.
+type TxQueuedEvent struct{ Tx *types.Transaction }
+
+// TxDropEvent is posted when a transaction is dropped from the transaction pool
+// for any reason other than being included in a block.
+type TxDropEvent struct {
+	Tx          *types.Transaction
+	Reason      TxDropReason
+	Replacement common.Hash // Hash of the replacing transaction if the reason is TxDropReplaced
+}
+
 // PendingLogsEvent is posted pre mining and notifies of pending logs.
 type PendingLogsEvent struct {
 	Logs []*types.Log
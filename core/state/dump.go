@@ -79,3 +79,19 @@ func (self *StateDB) Dump() []byte {
 
 	return json
 }
+
+// StorageRangeResult is a range of storage slots of a contract, as returned by
+// the debug_storageRangeAt API call.
+type StorageRangeResult struct {
+	Storage StorageMap   `json:"storage"`
+	NextKey *common.Hash `json:"nextKey"` // nil if Storage includes the last key in the trie.
+}
+
+// StorageMap is a set of storage slots keyed by the hash of their keys.
+type StorageMap map[common.Hash]StorageEntry
+
+// StorageEntry is a single storage slot. The key is nil if its preimage is unknown.
+type StorageEntry struct {
+	Key   *common.Hash `json:"key"`
+	Value common.Hash  `json:"value"`
+}
@@ -80,6 +80,9 @@ type StateDB struct {
 	validRevisions []revision
 	nextRevisionId int
 
+	// Optional callback notified of the accounts deleted while finalising
+	onDelete func(addr common.Address)
+
 	lock sync.Mutex
 }
 
@@ -315,6 +318,16 @@ func (self *StateDB) SetState(addr common.Address, key common.Hash, value common
 	}
 }
 
+// SetStorage replaces the entire storage of an account with the given one. The
+// change is not journaled, so it is only meant to override the state of a
+// throwaway state database (e.g. when simulating calls).
+func (self *StateDB) SetStorage(addr common.Address, storage map[common.Hash]common.Hash) {
+	stateObject := self.GetOrNewStateObject(addr)
+	if stateObject != nil {
+		stateObject.setStorage(self.db, storage)
+	}
+}
+
 // Suicide marks the given account as suicided.
 // This clears the account balance.
 //
@@ -528,6 +541,9 @@ func (s *StateDB) Finalise(deleteEmptyObjects bool) {
 	for addr := range s.stateObjectsDirty {
 		stateObject := s.stateObjects[addr]
 		if stateObject.suicided || (deleteEmptyObjects && stateObject.empty()) {
+			if s.onDelete != nil && !stateObject.deleted {
+				s.onDelete(addr)
+			}
 			s.deleteStateObject(stateObject)
 		} else {
 			stateObject.updateRoot(s.db)
@@ -538,6 +554,13 @@ func (s *StateDB) Finalise(deleteEmptyObjects bool) {
 	s.clearJournalAndRefund()
 }
 
+// SetDeleteHook sets a callback to be notified of every account removed from
+// the state while finalising it, either because it self-destructed or because
+// it was touched and left empty. A nil hook disables the notifications.
+func (s *StateDB) SetDeleteHook(hook func(addr common.Address)) {
+	s.onDelete = hook
+}
+
 // IntermediateRoot computes the current root hash of the state trie.
 // It is called in between transactions to get the root hash that
 // goes into transaction receipts.
@@ -168,6 +168,61 @@ func TestCopy(t *testing.T) {
 	}
 }
 
+// Tests that replacing the storage of an account drops all the previous slots,
+// both the committed and the dirty ones.
+func TestSetStorage(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	state, _ := New(common.Hash{}, NewDatabase(db))
+
+	addr := common.BytesToAddress([]byte{1})
+	state.SetState(addr, common.Hash{1}, common.Hash{1})
+	root, _ := state.Commit(false)
+
+	state, _ = New(root, state.Database())
+	state.SetState(addr, common.Hash{2}, common.Hash{2})
+	state.SetStorage(addr, map[common.Hash]common.Hash{{3}: {3}})
+
+	for key, want := range map[common.Hash]common.Hash{{1}: {}, {2}: {}, {3}: {3}} {
+		if have := state.GetState(addr, key); have != want {
+			t.Errorf("slot %x: value mismatch: have %x, want %x", key, have, want)
+		}
+	}
+	// Ensure the replaced storage is also the one committed
+	root, _ = state.Commit(false)
+	state, _ = New(root, state.Database())
+	for key, want := range map[common.Hash]common.Hash{{1}: {}, {2}: {}, {3}: {3}} {
+		if have := state.GetState(addr, key); have != want {
+			t.Errorf("committed slot %x: value mismatch: have %x, want %x", key, have, want)
+		}
+	}
+}
+
+// Tests that the delete hook is notified of self-destructed and touched empty
+// accounts exactly once when the state is finalised.
+func TestDeleteHook(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	state, _ := New(common.Hash{}, NewDatabase(db))
+
+	suicided, empty, alive := common.Address{1}, common.Address{2}, common.Address{3}
+	state.SetBalance(suicided, big.NewInt(1))
+	state.SetBalance(alive, big.NewInt(1))
+	root, _ := state.Commit(false)
+
+	state, _ = New(root, state.Database())
+	state.Suicide(suicided)
+	state.AddBalance(empty, new(big.Int))
+	state.AddBalance(alive, new(big.Int))
+
+	deleted := make(map[common.Address]int)
+	state.SetDeleteHook(func(addr common.Address) { deleted[addr]++ })
+	state.Finalise(true)
+	state.Finalise(true)
+
+	if want := map[common.Address]int{suicided: 1, empty: 1}; !reflect.DeepEqual(deleted, want) {
+		t.Errorf("deleted accounts mismatch: have %v, want %v", deleted, want)
+	}
+}
+
 func TestSnapshotRandom(t *testing.T) {
 	config := &quick.Config{MaxCount: 1000}
 	err := quick.Check((*snapshotTest).run, config)
@@ -20,6 +20,7 @@ import (
 	"bytes"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/trie"
 )
@@ -39,3 +40,32 @@ func NewStateSync(root common.Hash, database trie.DatabaseReader) *trie.TrieSync
 	syncer = trie.NewTrieSync(root, database, callback)
 	return syncer
 }
+
+// NewStateRepair creates a state download scheduler that retrieves the entries
+// reported missing by a state verification, along with any further entries
+// missing beneath them. Corrupt entries must be deleted from the database prior
+// to scheduling, otherwise they are considered already present.
+func NewStateRepair(issues []*VerifyIssue, database trie.DatabaseReader) *trie.TrieSync {
+	var syncer *trie.TrieSync
+	callback := func(leaf []byte, parent common.Hash) error {
+		var obj Account
+		if err := rlp.Decode(bytes.NewReader(leaf), &obj); err != nil {
+			return err
+		}
+		syncer.AddSubTrie(obj.Root, 64, parent, nil)
+		syncer.AddRawEntry(common.BytesToHash(obj.CodeHash), 64, parent)
+		return nil
+	}
+	syncer = trie.NewTrieSync(types.EmptyRootHash, database, nil)
+	for _, issue := range issues {
+		switch {
+		case issue.Code:
+			syncer.AddRawEntry(issue.Hash, 64, common.Hash{})
+		case issue.Owner == (common.Hash{}):
+			syncer.AddSubTrie(issue.Hash, len(issue.Path), common.Hash{}, callback)
+		default:
+			syncer.AddSubTrie(issue.Hash, 64+len(issue.Path), common.Hash{}, nil)
+		}
+	}
+	return syncer
+}
@@ -0,0 +1,218 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// VerifyIssue describes a single missing or corrupt state entry found during
+// the verification of a state trie.
+type VerifyIssue struct {
+	Owner   common.Hash // Hash of the account owning the storage trie or code (empty for the account trie)
+	Hash    common.Hash // Hash of the missing or corrupt trie node or contract code
+	Path    []byte      // Hex-encoded path of the node within its trie (nil for contract code)
+	Code    bool        // Whether the entry is contract code instead of a trie node
+	Corrupt bool        // Whether the entry is present but corrupt instead of missing
+}
+
+// String implements fmt.Stringer, producing a human readable description.
+func (issue *VerifyIssue) String() string {
+	kind, what := "missing", "trie node"
+	if issue.Corrupt {
+		kind = "corrupt"
+	}
+	if issue.Code {
+		what = "contract code"
+	}
+	if issue.Owner == (common.Hash{}) {
+		return fmt.Sprintf("%s account %s %x (path %x)", kind, what, issue.Hash, issue.Path)
+	}
+	if issue.Code {
+		return fmt.Sprintf("%s %s %x (account %x)", kind, what, issue.Hash, issue.Owner)
+	}
+	return fmt.Sprintf("%s storage %s %x (account %x, path %x)", kind, what, issue.Hash, issue.Owner, issue.Path)
+}
+
+// VerifyResult contains the statistics and the discovered issues of a state
+// verification run.
+type VerifyResult struct {
+	Accounts     uint64         // Number of accounts iterated over
+	AccountNodes uint64         // Number of account trie nodes checked
+	StorageNodes uint64         // Number of storage trie nodes checked
+	Codes        uint64         // Number of contract codes checked
+	Issues       []*VerifyIssue // Missing or corrupt entries found
+}
+
+// stateVerifier is a helper to walk an entire state and gather its issues.
+type stateVerifier struct {
+	db     Database
+	result *VerifyResult
+
+	start  time.Time
+	logged time.Time
+}
+
+// VerifyState walks the account trie rooted at root, along with every storage
+// trie and contract code referenced from it, and checks that all the entries are
+// present in the database and hash to their expected values. Missing and corrupt
+// entries are collected and their subtries skipped, so a single run quantifies
+// all the damage. An error is only returned for unexpected failures.
+func VerifyState(db Database, root common.Hash) (*VerifyResult, error) {
+	v := &stateVerifier{
+		db:     db,
+		result: new(VerifyResult),
+		start:  time.Now(),
+		logged: time.Now(),
+	}
+	tr, err := db.OpenTrie(root)
+	if err != nil {
+		if missing, ok := err.(*trie.MissingNodeError); ok {
+			v.report(common.Hash{}, missing)
+			return v.result, nil
+		}
+		return nil, err
+	}
+	err = v.verifyTrie(tr, common.Hash{}, &v.result.AccountNodes, func(key, blob []byte) error {
+		var account Account
+		if err := rlp.DecodeBytes(blob, &account); err != nil {
+			return fmt.Errorf("invalid account %x: %v", key, err)
+		}
+		v.result.Accounts++
+		return v.verifyAccount(common.BytesToHash(key), &account)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.result, nil
+}
+
+// verifyAccount checks the storage trie and contract code of a single account.
+func (v *stateVerifier) verifyAccount(addrHash common.Hash, account *Account) error {
+	if account.Root != types.EmptyRootHash {
+		tr, err := v.db.OpenStorageTrie(addrHash, account.Root)
+		if err != nil {
+			missing, ok := err.(*trie.MissingNodeError)
+			if !ok {
+				return err
+			}
+			v.report(addrHash, missing)
+		} else if err := v.verifyTrie(tr, addrHash, &v.result.StorageNodes, nil); err != nil {
+			return err
+		}
+	}
+	if !bytes.Equal(account.CodeHash, emptyCodeHash) {
+		hash := common.BytesToHash(account.CodeHash)
+
+		v.result.Codes++
+		code, err := v.db.ContractCode(addrHash, hash)
+		switch {
+		case err != nil || len(code) == 0:
+			v.result.Issues = append(v.result.Issues, &VerifyIssue{Owner: addrHash, Hash: hash, Code: true})
+		case crypto.Keccak256Hash(code) != hash:
+			v.result.Issues = append(v.result.Issues, &VerifyIssue{Owner: addrHash, Hash: hash, Code: true, Corrupt: true})
+		}
+	}
+	return nil
+}
+
+// verifyTrie iterates over all the nodes of a trie, checking the integrity of
+// each hashed node and invoking onLeaf for every leaf. Whenever a missing node
+// is hit, it is reported and the iteration is restarted after its subtrie.
+func (v *stateVerifier) verifyTrie(tr Trie, owner common.Hash, nodes *uint64, onLeaf func(key, blob []byte) error) error {
+	triedb := v.db.TrieDB()
+
+	var start []byte
+	for {
+		it := tr.NodeIterator(start)
+		for it.Next(true) {
+			if hash := it.Hash(); hash != (common.Hash{}) {
+				*nodes++
+				if blob, err := triedb.Node(hash); err != nil || crypto.Keccak256Hash(blob) != hash {
+					v.result.Issues = append(v.result.Issues, &VerifyIssue{Owner: owner, Hash: hash, Path: common.CopyBytes(it.Path()), Corrupt: true})
+				}
+			}
+			if it.Leaf() && onLeaf != nil {
+				if err := onLeaf(it.LeafKey(), it.LeafBlob()); err != nil {
+					return err
+				}
+			}
+			if time.Since(v.logged) > 8*time.Second {
+				log.Info("Verifying state", "accounts", v.result.Accounts, "nodes", v.result.AccountNodes+v.result.StorageNodes,
+					"codes", v.result.Codes, "issues", len(v.result.Issues), "elapsed", common.PrettyDuration(time.Since(v.start)))
+				v.logged = time.Now()
+			}
+		}
+		err := it.Error()
+		if err == nil {
+			return nil
+		}
+		missing, ok := err.(*trie.MissingNodeError)
+		if !ok {
+			return err
+		}
+		v.report(owner, missing)
+
+		if start = nextSubtrieKey(missing.Path); start == nil {
+			return nil
+		}
+	}
+}
+
+// report records a missing trie node, checking whether the node is absent or
+// present but undecodable.
+func (v *stateVerifier) report(owner common.Hash, missing *trie.MissingNodeError) {
+	blob, _ := v.db.TrieDB().Node(missing.NodeHash)
+	v.result.Issues = append(v.result.Issues, &VerifyIssue{
+		Owner:   owner,
+		Hash:    missing.NodeHash,
+		Path:    common.CopyBytes(missing.Path),
+		Corrupt: len(blob) > 0,
+	})
+}
+
+// nextSubtrieKey returns the smallest key located after every key starting with
+// the given hex-encoded path, or nil if there is no such key.
+func nextSubtrieKey(path []byte) []byte {
+	next := common.CopyBytes(path)
+	for len(next) > 0 && next[len(next)-1] == 0x0f {
+		next = next[:len(next)-1]
+	}
+	if len(next) == 0 {
+		return nil
+	}
+	next[len(next)-1]++
+
+	// Convert the nibbles back into key bytes, padding odd paths with zero
+	if len(next)%2 == 1 {
+		next = append(next, 0)
+	}
+	key := make([]byte, len(next)/2)
+	for i := range key {
+		key[i] = next[2*i]<<4 | next[2*i+1]
+	}
+	return key
+}
@@ -0,0 +1,159 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// makeVerifyTestState creates a sample test state and flushes it to disk.
+func makeVerifyTestState(t *testing.T) (*ethdb.MemDatabase, common.Hash) {
+	db, root, _ := makeTestState()
+	if err := db.TrieDB().Commit(root, false); err != nil {
+		t.Fatalf("failed to flush state: %v", err)
+	}
+	return db.TrieDB().DiskDB().(*ethdb.MemDatabase), root
+}
+
+// Tests that an intact state is verified without any issues.
+func TestVerifyIntactState(t *testing.T) {
+	diskdb, root := makeVerifyTestState(t)
+
+	result, err := VerifyState(NewDatabase(diskdb), root)
+	if err != nil {
+		t.Fatalf("failed to verify state: %v", err)
+	}
+	if len(result.Issues) != 0 {
+		t.Errorf("issues found in intact state: %v", result.Issues)
+	}
+	if result.Accounts != 96 {
+		t.Errorf("account count mismatch: have %d, want %d", result.Accounts, 96)
+	}
+	if result.Codes != 32 {
+		t.Errorf("code count mismatch: have %d, want %d", result.Codes, 32)
+	}
+}
+
+// Tests that missing and corrupt entries are all detected, and that the state
+// can be repaired by retrieving only the damaged entries.
+func TestVerifyDamagedState(t *testing.T) {
+	srcdb, root := makeVerifyTestState(t)
+	diskdb, _ := makeVerifyTestState(t)
+
+	// Collect the inner nodes of the account trie and a contract code to damage
+	tr, _ := trie.New(root, trie.NewDatabase(diskdb))
+	var nodes []common.Hash
+	for it := tr.NodeIterator(nil); it.Next(true); {
+		if hash := it.Hash(); hash != (common.Hash{}) && hash != root {
+			nodes = append(nodes, hash)
+		}
+	}
+	if len(nodes) < 8 {
+		t.Fatalf("too few trie nodes to damage: %d", len(nodes))
+	}
+	missing, corrupt := nodes[1], nodes[len(nodes)-2]
+	diskdb.Delete(missing[:])
+	diskdb.Put(corrupt[:], []byte("corrupt"))
+
+	var code common.Hash
+	for _, key := range diskdb.Keys() {
+		if blob, _ := diskdb.Get(key); bytes.Equal(blob, []byte{3, 3, 3, 3, 3}) {
+			code = common.BytesToHash(key)
+		}
+	}
+	if code == (common.Hash{}) {
+		t.Fatalf("contract code not found in database")
+	}
+	diskdb.Delete(code[:])
+
+	// Verify the state and ensure all the damage is found
+	result, err := VerifyState(NewDatabase(diskdb), root)
+	if err != nil {
+		t.Fatalf("failed to verify state: %v", err)
+	}
+	found := make(map[common.Hash]*VerifyIssue)
+	for _, issue := range result.Issues {
+		found[issue.Hash] = issue
+	}
+	if len(found) != 3 {
+		t.Fatalf("issue count mismatch: have %v, want 3", result.Issues)
+	}
+	if issue := found[missing]; issue == nil || issue.Corrupt || issue.Code || len(issue.Path) == 0 {
+		t.Errorf("missing node not reported correctly: %v", issue)
+	}
+	if issue := found[corrupt]; issue == nil || !issue.Corrupt || issue.Code {
+		t.Errorf("corrupt node not reported correctly: %v", issue)
+	}
+	if issue := found[code]; issue == nil || issue.Corrupt || !issue.Code {
+		t.Errorf("missing code not reported correctly: %v", issue)
+	}
+	if result.Accounts == 0 || result.Accounts >= 96 {
+		t.Errorf("account count mismatch: have %d, want (0, 96)", result.Accounts)
+	}
+	// Drop the corrupt entries and repair everything from the source database
+	diskdb.Delete(corrupt[:])
+
+	sched := NewStateRepair(result.Issues, diskdb)
+	for queue := sched.Missing(0); len(queue) > 0; queue = sched.Missing(0) {
+		results := make([]trie.SyncResult, len(queue))
+		for i, hash := range queue {
+			data, err := srcdb.Get(hash[:])
+			if err != nil {
+				t.Fatalf("failed to retrieve node data for %x", hash)
+			}
+			results[i] = trie.SyncResult{Hash: hash, Data: data}
+		}
+		if _, index, err := sched.Process(results); err != nil {
+			t.Fatalf("failed to process result #%d: %v", index, err)
+		}
+		if index, err := sched.Commit(diskdb); err != nil {
+			t.Fatalf("failed to commit data #%d: %v", index, err)
+		}
+	}
+	if result, err = VerifyState(NewDatabase(diskdb), root); err != nil {
+		t.Fatalf("failed to verify repaired state: %v", err)
+	}
+	if len(result.Issues) != 0 || result.Accounts != 96 {
+		t.Errorf("repaired state mismatch: accounts %d, issues %v", result.Accounts, result.Issues)
+	}
+}
+
+// Tests the calculation of the key following all keys of a trie path.
+func TestNextSubtrieKey(t *testing.T) {
+	tests := []struct {
+		path []byte
+		want []byte
+	}{
+		{[]byte{}, nil},
+		{[]byte{0x0f}, nil},
+		{[]byte{0x0f, 0x0f}, nil},
+		{[]byte{0x03}, []byte{0x40}},
+		{[]byte{0x03, 0x0f}, []byte{0x40}},
+		{[]byte{0x03, 0x04}, []byte{0x35}},
+		{[]byte{0x03, 0x04, 0x0a}, []byte{0x34, 0xb0}},
+	}
+	for i, tt := range tests {
+		if have := nextSubtrieKey(tt.path); !bytes.Equal(have, tt.want) {
+			t.Errorf("test %d: key mismatch: have %x, want %x", i, have, tt.want)
+		}
+	}
+}
@@ -95,6 +95,11 @@ func ApplyTransaction(config *params.ChainConfig, bc *BlockChain, author *common
 	// Create a new environment which holds all relevant information
 	// about the transaction and calling mechanisms.
 	vmenv := vm.NewEVM(context, statedb, config, cfg)
+	// Report the accounts deleted while finalising the state to state tracers
+	if tracer, ok := cfg.Tracer.(vm.StateTracer); ok && cfg.Debug {
+		statedb.SetDeleteHook(tracer.CaptureAccountDelete)
+		defer statedb.SetDeleteHook(nil)
+	}
 	// Apply the transaction to the current state (included in the env)
 	_, gas, failed, err := ApplyMessage(vmenv, msg, gp)
 	if err != nil {
@@ -0,0 +1,98 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// deleteTracer is a vm.StateTracer only recording the deleted accounts.
+type deleteTracer struct {
+	*vm.StructLogger
+	deleted []common.Address
+}
+
+func (t *deleteTracer) CaptureAccountRead(addr common.Address)                           {}
+func (t *deleteTracer) CaptureBalanceChange(addr common.Address, prev, balance *big.Int) {}
+func (t *deleteTracer) CaptureNonceChange(addr common.Address, prev, nonce uint64)       {}
+func (t *deleteTracer) CaptureCodeRead(addr common.Address)                              {}
+func (t *deleteTracer) CaptureCodeChange(addr common.Address, code []byte)               {}
+func (t *deleteTracer) CaptureStorageRead(addr common.Address, key, value common.Hash)   {}
+func (t *deleteTracer) CaptureStorageWrite(addr common.Address, key, prev, value common.Hash) {
+}
+func (t *deleteTracer) CaptureLog(log *types.Log)                                 {}
+func (t *deleteTracer) CaptureSelfDestruct(addr common.Address, balance *big.Int) {}
+func (t *deleteTracer) CaptureAccountDelete(addr common.Address) {
+	t.deleted = append(t.deleted, addr)
+}
+
+// Tests that applying a transaction reports the accounts deleted while finalising
+// the state to state tracers.
+func TestApplyTransactionDeleteHook(t *testing.T) {
+	var (
+		key, _   = crypto.GenerateKey()
+		sender   = crypto.PubkeyToAddress(key.PublicKey)
+		contract = common.Address{0xc0}
+		heir     = common.BytesToAddress([]byte{0xbe}) // Beneficiary of the self-destruct
+		coinbase = common.Address{0xcb}
+		db, _    = ethdb.NewMemDatabase()
+	)
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	statedb.SetBalance(sender, big.NewInt(params.Ether))
+	statedb.SetCode(contract, []byte{byte(vm.PUSH1), 0xbe, byte(vm.SELFDESTRUCT)})
+	statedb.Finalise(true)
+
+	signer := types.NewEIP155Signer(params.TestChainConfig.ChainId)
+	tx, _ := types.SignTx(types.NewTransaction(0, contract, new(big.Int), 100000, big.NewInt(1), nil), signer, key)
+
+	header := &types.Header{
+		Number:     big.NewInt(1),
+		Time:       new(big.Int),
+		Difficulty: new(big.Int),
+		GasLimit:   1000000,
+		Coinbase:   coinbase,
+	}
+	tracer := &deleteTracer{StructLogger: vm.NewStructLogger(nil)}
+
+	var usedGas uint64
+	if _, _, err := ApplyTransaction(params.TestChainConfig, nil, &coinbase, new(GasPool).AddGas(header.GasLimit), statedb, header, tx, &usedGas, vm.Config{Debug: true, Tracer: tracer}); err != nil {
+		t.Fatalf("failed to apply transaction: %v", err)
+	}
+	// Both the self-destructed contract and its touched, empty heir are gone
+	deleted := make(map[common.Address]bool)
+	for _, addr := range tracer.deleted {
+		deleted[addr] = true
+	}
+	if len(tracer.deleted) != 2 || !deleted[contract] || !deleted[heir] {
+		t.Errorf("deleted accounts mismatch: have %x, want [%x %x]", tracer.deleted, contract, heir)
+	}
+	// Ensure the hook is detached after the transaction
+	statedb.Suicide(sender)
+	statedb.Finalise(true)
+	if len(tracer.deleted) != 2 {
+		t.Errorf("hook still attached: deleted %x", tracer.deleted)
+	}
+}
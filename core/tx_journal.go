@@ -20,6 +20,7 @@ import (
 	"errors"
 	"io"
 	"os"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -40,17 +41,63 @@ type devNull struct{}
 func (*devNull) Write(p []byte) (n int, err error) { return len(p), nil }
 func (*devNull) Close() error                      { return nil }
 
+// journalEntry is a transaction stored in the journal along with its arrival
+// time, so that it keeps its place in the arrival order when reloaded. Older
+// journals only contain the bare transactions.
+type journalEntry struct {
+	Tx   *types.Transaction
+	Time uint64 // Arrival time in unix nanoseconds, zero if unknown
+}
+
+// encodeJournalEntry writes a transaction into a journal along with its arrival
+// time.
+func encodeJournalEntry(w io.Writer, tx *types.Transaction) error {
+	entry := &journalEntry{Tx: tx}
+	if !tx.Time().IsZero() {
+		entry.Time = uint64(tx.Time().UnixNano())
+	}
+	return rlp.Encode(w, entry)
+}
+
+// decodeJournalEntry parses a journaled transaction, restoring its arrival time
+// if it was stored along with it.
+func decodeJournalEntry(blob []byte) (*types.Transaction, error) {
+	_, content, _, err := rlp.Split(blob)
+	if err != nil {
+		return nil, err
+	}
+	if fields, err := rlp.CountValues(content); err != nil || fields != 2 {
+		// Not an entry, fall back to a bare transaction of an old journal
+		tx := new(types.Transaction)
+		if err := rlp.DecodeBytes(blob, tx); err != nil {
+			return nil, err
+		}
+		return tx, nil
+	}
+	entry := new(journalEntry)
+	if err := rlp.DecodeBytes(blob, entry); err != nil {
+		return nil, err
+	}
+	if entry.Time != 0 {
+		entry.Tx.SetTime(time.Unix(0, int64(entry.Time)))
+	}
+	return entry.Tx, nil
+}
+
 // txJournal is a rotating log of transactions with the aim of storing locally
-// created transactions to allow non-executed ones to survive node restarts.
+// created (or optionally remote) transactions to allow non-executed ones to
+// survive node restarts.
 type txJournal struct {
 	path   string         // Filesystem path to store the transactions at
+	kind   string         // Kind of transactions stored, used for logging
 	writer io.WriteCloser // Output stream to write new transactions into
 }
 
 // newTxJournal creates a new transaction journal to
-func newTxJournal(path string) *txJournal {
+func newTxJournal(path string, kind string) *txJournal {
 	return &txJournal{
 		path: path,
+		kind: kind,
 	}
 }
 
@@ -79,13 +126,18 @@ func (journal *txJournal) load(add func(*types.Transaction) error) error {
 	var failure error
 	for {
 		// Parse the next transaction and terminate on error
-		tx := new(types.Transaction)
-		if err = stream.Decode(tx); err != nil {
+		var blob rlp.RawValue
+		if err = stream.Decode(&blob); err != nil {
 			if err != io.EOF {
 				failure = err
 			}
 			break
 		}
+		tx, err := decodeJournalEntry(blob)
+		if err != nil {
+			failure = err
+			break
+		}
 		// Import the transaction and bump the appropriate progress counters
 		total++
 		if err = add(tx); err != nil {
@@ -94,7 +146,7 @@ func (journal *txJournal) load(add func(*types.Transaction) error) error {
 			continue
 		}
 	}
-	log.Info("Loaded local transaction journal", "transactions", total, "dropped", dropped)
+	log.Info("Loaded "+journal.kind+" transaction journal", "transactions", total, "dropped", dropped)
 
 	return failure
 }
@@ -104,7 +156,7 @@ func (journal *txJournal) insert(tx *types.Transaction) error {
 	if journal.writer == nil {
 		return errNoActiveJournal
 	}
-	if err := rlp.Encode(journal.writer, tx); err != nil {
+	if err := encodeJournalEntry(journal.writer, tx); err != nil {
 		return err
 	}
 	return nil
@@ -128,7 +180,7 @@ func (journal *txJournal) rotate(all map[common.Address]types.Transactions) erro
 	journaled := 0
 	for _, txs := range all {
 		for _, tx := range txs {
-			if err = rlp.Encode(replacement, tx); err != nil {
+			if err = encodeJournalEntry(replacement, tx); err != nil {
 				replacement.Close()
 				return err
 			}
@@ -146,7 +198,7 @@ func (journal *txJournal) rotate(all map[common.Address]types.Transactions) erro
 		return err
 	}
 	journal.writer = sink
-	log.Info("Regenerated local transaction journal", "transactions", journaled, "accounts", len(all))
+	log.Info("Regenerated "+journal.kind+" transaction journal", "transactions", journaled, "accounts", len(all))
 
 	return nil
 }
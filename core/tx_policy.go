@@ -0,0 +1,173 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	// ErrSenderNotAllowed is returned if the sender of a transaction is not in
+	// the allowlist of the transaction pool.
+	ErrSenderNotAllowed = errors.New("sender not allowed")
+
+	// ErrDestinationBlocked is returned if the recipient of a transaction is in
+	// the blocklist of the transaction pool.
+	ErrDestinationBlocked = errors.New("destination blocked")
+
+	// ErrSenderRateLimited is returned if the sender of a transaction submitted
+	// more transactions than permitted within the rate limiting window.
+	ErrSenderRateLimited = errors.New("sender rate limited")
+)
+
+// TxPolicy is an admission rule consulted by the transaction pool for every new
+// transaction that passed the standard validations. Policies are invoked with
+// the pool lock held, so implementations are not required to be thread safe
+// unless they are shared between multiple pools.
+type TxPolicy interface {
+	// Admit checks whether a transaction from the given sender may enter the
+	// pool, returning an error describing the violated rule otherwise.
+	Admit(tx *types.Transaction, from common.Address, local bool) error
+}
+
+// StatefulTxPolicy is an optional extension of TxPolicy for rules keeping track
+// of the transactions they admitted. The pool calls Admitted once a transaction
+// passed every check and was actually inserted, so transactions rejected later
+// on (e.g. as underpriced) don't count against any quota. Transactions reloaded
+// from the journals on startup are not reported.
+type StatefulTxPolicy interface {
+	TxPolicy
+
+	// Admitted records that a transaction from the given sender entered the pool.
+	Admitted(tx *types.Transaction, from common.Address, local bool)
+}
+
+// senderAllowlist is a TxPolicy only admitting transactions from a fixed set
+// of senders.
+type senderAllowlist map[common.Address]struct{}
+
+// NewSenderAllowlist creates a policy only admitting transactions signed by one
+// of the given accounts.
+func NewSenderAllowlist(senders []common.Address) TxPolicy {
+	allowed := make(senderAllowlist)
+	for _, sender := range senders {
+		allowed[sender] = struct{}{}
+	}
+	return allowed
+}
+
+func (allowed senderAllowlist) Admit(tx *types.Transaction, from common.Address, local bool) error {
+	if _, ok := allowed[from]; !ok {
+		return ErrSenderNotAllowed
+	}
+	return nil
+}
+
+// destinationBlocklist is a TxPolicy rejecting transactions sent to a fixed set
+// of recipients.
+type destinationBlocklist map[common.Address]struct{}
+
+// NewDestinationBlocklist creates a policy rejecting all transactions sent to
+// any of the given accounts or contracts.
+func NewDestinationBlocklist(destinations []common.Address) TxPolicy {
+	blocked := make(destinationBlocklist)
+	for _, destination := range destinations {
+		blocked[destination] = struct{}{}
+	}
+	return blocked
+}
+
+func (blocked destinationBlocklist) Admit(tx *types.Transaction, from common.Address, local bool) error {
+	if to := tx.To(); to != nil {
+		if _, ok := blocked[*to]; ok {
+			return ErrDestinationBlocked
+		}
+	}
+	return nil
+}
+
+// rateWindow is the number of transactions admitted from a sender since the
+// start of its current rate limiting window.
+type rateWindow struct {
+	start time.Time
+	count uint64
+}
+
+// senderRateLimiter is a StatefulTxPolicy limiting the number of transactions a
+// single sender may get accepted within a time window. Local transactions are
+// exempt.
+type senderRateLimiter struct {
+	limit   uint64
+	period  time.Duration
+	senders map[common.Address]*rateWindow
+	cleaned time.Time // Last time expired windows were cleaned up
+
+	now func() time.Time // Time source, replaceable for testing
+}
+
+// NewSenderRateLimiter creates a policy admitting at most limit transactions
+// from a single remote sender within each window.
+func NewSenderRateLimiter(limit uint64, window time.Duration) TxPolicy {
+	return &senderRateLimiter{
+		limit:   limit,
+		period:  window,
+		senders: make(map[common.Address]*rateWindow),
+		cleaned: time.Now(),
+		now:     time.Now,
+	}
+}
+
+func (l *senderRateLimiter) Admit(tx *types.Transaction, from common.Address, local bool) error {
+	if local {
+		return nil
+	}
+	if window := l.window(from, l.now()); window.count >= l.limit {
+		return ErrSenderRateLimited
+	}
+	return nil
+}
+
+func (l *senderRateLimiter) Admitted(tx *types.Transaction, from common.Address, local bool) {
+	if local {
+		return
+	}
+	l.window(from, l.now()).count++
+}
+
+// window retrieves the current rate limiting window of a sender, starting a new
+// one if the previous expired.
+func (l *senderRateLimiter) window(from common.Address, now time.Time) *rateWindow {
+	// Periodically drop the expired windows to avoid accumulating senders
+	if now.Sub(l.cleaned) > l.period {
+		for sender, window := range l.senders {
+			if now.Sub(window.start) > l.period {
+				delete(l.senders, sender)
+			}
+		}
+		l.cleaned = now
+	}
+	window := l.senders[from]
+	if window == nil || now.Sub(window.start) > l.period {
+		window = &rateWindow{start: now}
+		l.senders[from] = window
+	}
+	return window
+}
@@ -0,0 +1,240 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// policyTransaction creates a transaction to the given recipient, signed by key.
+func policyTransaction(nonce uint64, to common.Address, key *ecdsa.PrivateKey) *types.Transaction {
+	tx, _ := types.SignTx(types.NewTransaction(nonce, to, big.NewInt(100), 100000, big.NewInt(1), nil), types.HomesteadSigner{}, key)
+	return tx
+}
+
+// setupPolicyTxPool creates a transaction pool with the given config, funding
+// the accounts of all the given keys.
+func setupPolicyTxPool(config TxPoolConfig, keys ...*ecdsa.PrivateKey) *TxPool {
+	db, _ := ethdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	for _, key := range keys {
+		statedb.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+	}
+	return NewTxPool(config, params.TestChainConfig, blockchain)
+}
+
+// Tests that only transactions from allowed senders are admitted.
+func TestTransactionSenderAllowlist(t *testing.T) {
+	t.Parallel()
+
+	allowed, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+
+	config := testTxPoolConfig
+	config.AllowedSenders = []common.Address{crypto.PubkeyToAddress(allowed.PublicKey)}
+
+	pool := setupPolicyTxPool(config, allowed, other)
+	defer pool.Stop()
+
+	if err := pool.AddRemote(policyTransaction(0, common.Address{}, allowed)); err != nil {
+		t.Fatalf("allowed sender rejected: %v", err)
+	}
+	if err := pool.AddRemote(policyTransaction(0, common.Address{}, other)); err != ErrSenderNotAllowed {
+		t.Fatalf("disallowed remote sender error mismatch: have %v, want %v", err, ErrSenderNotAllowed)
+	}
+	if err := pool.AddLocal(policyTransaction(0, common.Address{}, other)); err != ErrSenderNotAllowed {
+		t.Fatalf("disallowed local sender error mismatch: have %v, want %v", err, ErrSenderNotAllowed)
+	}
+	if pending, queued := pool.Stats(); pending != 1 || queued != 0 {
+		t.Fatalf("pool content mismatch: have %d/%d, want %d/%d", pending, queued, 1, 0)
+	}
+}
+
+// Tests that transactions to blocked destinations are rejected, while contract
+// creations and other recipients are unaffected.
+func TestTransactionDestinationBlocklist(t *testing.T) {
+	t.Parallel()
+
+	key, _ := crypto.GenerateKey()
+	blocked := common.HexToAddress("0xdeadbeef")
+
+	config := testTxPoolConfig
+	config.BlockedDestinations = []common.Address{blocked}
+
+	pool := setupPolicyTxPool(config, key)
+	defer pool.Stop()
+
+	if err := pool.AddRemote(policyTransaction(0, blocked, key)); err != ErrDestinationBlocked {
+		t.Fatalf("blocked destination error mismatch: have %v, want %v", err, ErrDestinationBlocked)
+	}
+	if err := pool.AddRemote(policyTransaction(0, common.Address{}, key)); err != nil {
+		t.Fatalf("unblocked destination rejected: %v", err)
+	}
+	create, _ := types.SignTx(types.NewContractCreation(1, big.NewInt(0), 100000, big.NewInt(1), nil), types.HomesteadSigner{}, key)
+	if err := pool.AddRemote(create); err != nil {
+		t.Fatalf("contract creation rejected: %v", err)
+	}
+}
+
+// Tests that remote senders are rate limited within a window, local ones are
+// exempt and that the limit resets once the window passes.
+func TestTransactionSenderRateLimit(t *testing.T) {
+	t.Parallel()
+
+	remote, _ := crypto.GenerateKey()
+	local, _ := crypto.GenerateKey()
+
+	config := testTxPoolConfig
+	config.SenderRateLimit = 2
+	config.SenderRateWindow = time.Minute
+
+	pool := setupPolicyTxPool(config, remote, local)
+	defer pool.Stop()
+
+	// Replace the time source of the limiter to control the window
+	now := time.Now()
+	limiter := pool.policies[0].(*senderRateLimiter)
+	limiter.now = func() time.Time { return now }
+
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		if err := pool.AddRemote(policyTransaction(nonce, common.Address{}, remote)); err != nil {
+			t.Fatalf("remote transaction %d rejected: %v", nonce, err)
+		}
+	}
+	if err := pool.AddRemote(policyTransaction(2, common.Address{}, remote)); err != ErrSenderRateLimited {
+		t.Fatalf("rate limit error mismatch: have %v, want %v", err, ErrSenderRateLimited)
+	}
+	for nonce := uint64(0); nonce < 3; nonce++ {
+		if err := pool.AddLocal(policyTransaction(nonce, common.Address{}, local)); err != nil {
+			t.Fatalf("local transaction %d rejected: %v", nonce, err)
+		}
+	}
+	now = now.Add(time.Minute + time.Second)
+	if err := pool.AddRemote(policyTransaction(2, common.Address{}, remote)); err != nil {
+		t.Fatalf("remote transaction rejected after window: %v", err)
+	}
+}
+
+// Tests that only accepted transactions count against the rate limit, neither the
+// ones rejected after the policy checks nor the ones reloaded from the journals.
+func TestTransactionSenderRateLimitAccounting(t *testing.T) {
+	t.Parallel()
+
+	// Create a temporary file for the remote journal
+	file, err := ioutil.TempFile("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary journal: %v", err)
+	}
+	journal := file.Name()
+	defer os.Remove(journal)
+
+	file.Close()
+	os.Remove(journal)
+
+	remote, _ := crypto.GenerateKey()
+
+	config := testTxPoolConfig
+	config.SenderRateLimit = 2
+	config.SenderRateWindow = time.Hour
+	config.RemoteJournal = journal
+
+	pool := setupPolicyTxPool(config, remote)
+
+	if err := pool.AddRemote(policyTransaction(0, common.Address{}, remote)); err != nil {
+		t.Fatalf("remote transaction rejected: %v", err)
+	}
+	// Underpriced replacements pass the policies but are rejected by the pool
+	if err := pool.AddRemote(policyTransaction(0, common.Address{1}, remote)); err != ErrReplaceUnderpriced {
+		t.Fatalf("replacement error mismatch: have %v, want %v", err, ErrReplaceUnderpriced)
+	}
+	if err := pool.AddRemote(policyTransaction(1, common.Address{}, remote)); err != nil {
+		t.Fatalf("remote transaction rejected after failed replacement: %v", err)
+	}
+	if err := pool.AddRemote(policyTransaction(2, common.Address{}, remote)); err != ErrSenderRateLimited {
+		t.Fatalf("rate limit error mismatch: have %v, want %v", err, ErrSenderRateLimited)
+	}
+	pool.Stop()
+
+	// Restart the pool and ensure the reloaded transactions are not charged
+	pool = setupPolicyTxPool(config, remote)
+	defer pool.Stop()
+
+	if pending, _ := pool.Stats(); pending != 2 {
+		t.Fatalf("reloaded transaction count mismatch: have %d, want %d", pending, 2)
+	}
+	for nonce := uint64(2); nonce < 4; nonce++ {
+		if err := pool.AddRemote(policyTransaction(nonce, common.Address{}, remote)); err != nil {
+			t.Fatalf("remote transaction %d rejected after reload: %v", nonce, err)
+		}
+	}
+	if err := pool.AddRemote(policyTransaction(4, common.Address{}, remote)); err != ErrSenderRateLimited {
+		t.Fatalf("rate limit error mismatch after reload: have %v, want %v", err, ErrSenderRateLimited)
+	}
+}
+
+// testRejectPolicy is a custom admission policy rejecting every transaction
+// with a gas limit above a threshold.
+type testRejectPolicy uint64
+
+var errTestGasTooHigh = errors.New("gas too high")
+
+func (p testRejectPolicy) Admit(tx *types.Transaction, from common.Address, local bool) error {
+	if tx.Gas() > uint64(p) {
+		return errTestGasTooHigh
+	}
+	return nil
+}
+
+// Tests that custom policies are consulted after the standard validations.
+func TestTransactionCustomPolicy(t *testing.T) {
+	t.Parallel()
+
+	key, _ := crypto.GenerateKey()
+
+	config := testTxPoolConfig
+	config.Policies = []TxPolicy{testRejectPolicy(50000)}
+
+	pool := setupPolicyTxPool(config, key)
+	defer pool.Stop()
+
+	if err := pool.AddRemote(policyTransaction(0, common.Address{}, key)); err != errTestGasTooHigh {
+		t.Fatalf("custom policy error mismatch: have %v, want %v", err, errTestGasTooHigh)
+	}
+	if err := pool.AddRemote(transaction(0, 21000, key)); err != nil {
+		t.Fatalf("admitted transaction rejected: %v", err)
+	}
+	// Invalid transactions should fail validation before reaching the policies
+	if err := pool.AddRemote(transaction(1, 1000000000, key)); err != ErrGasLimit {
+		t.Fatalf("validation error mismatch: have %v, want %v", err, ErrGasLimit)
+	}
+}
@@ -17,6 +17,7 @@
 package core
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"math"
@@ -101,6 +102,7 @@ var (
 	// General tx metrics
 	invalidTxCounter     = metrics.NewCounter("txpool/invalid")
 	underpricedTxCounter = metrics.NewCounter("txpool/underpriced")
+	rejectedTxCounter    = metrics.NewCounter("txpool/rejected") // Disallowed by an admission policy
 )
 
 // TxStatus is the current status of a transaction as seen by the pool.
@@ -113,6 +115,38 @@ const (
 	TxStatusIncluded
 )
 
+// TxDropReason is the cause of a transaction being dropped from the pool.
+type TxDropReason uint
+
+const (
+	TxDropUnderpriced TxDropReason = iota // Evicted by better priced transactions or below the minimum price
+	TxDropReplaced                        // Replaced by a transaction with the same nonce
+	TxDropStale                           // Nonce became too low after a new head
+	TxDropUnpayable                       // Balance or block gas limit became insufficient
+	TxDropRateLimit                       // Exceeded the per account or global pool limits
+	TxDropExpired                         // Spent more than the allowed lifetime in the queue
+)
+
+// String implements fmt.Stringer.
+func (r TxDropReason) String() string {
+	switch r {
+	case TxDropUnderpriced:
+		return "underpriced"
+	case TxDropReplaced:
+		return "replaced"
+	case TxDropStale:
+		return "stale"
+	case TxDropUnpayable:
+		return "unpayable"
+	case TxDropRateLimit:
+		return "ratelimit"
+	case TxDropExpired:
+		return "expired"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint(r))
+	}
+}
+
 // blockChain provides the state of blockchain and current gas limit to do
 // some pre checks in tx pool and event subscribers.
 type blockChain interface {
@@ -129,6 +163,9 @@ type TxPoolConfig struct {
 	Journal   string        // Journal of local transactions to survive node restarts
 	Rejournal time.Duration // Time interval to regenerate the local transaction journal
 
+	RemoteJournal    string // Journal of remote transactions to survive node restarts (disabled if empty)
+	RemoteJournalCap uint64 // Maximum number of remote transactions to journal (0 = unlimited)
+
 	PriceLimit uint64 // Minimum gas price to enforce for acceptance into the pool
 	PriceBump  uint64 // Minimum price bump percentage to replace an already existing transaction (nonce)
 
@@ -138,6 +175,13 @@ type TxPoolConfig struct {
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+
+	AllowedSenders      []common.Address // Senders permitted to submit transactions (empty = anyone)
+	BlockedDestinations []common.Address // Recipients transactions may not be sent to
+	SenderRateLimit     uint64           // Maximum number of remote transactions per sender and window (0 = unlimited)
+	SenderRateWindow    time.Duration    // Time window of the per sender rate limit
+
+	Policies []TxPolicy `toml:"-"` // Custom admission rules consulted after the built-in ones
 }
 
 // DefaultTxPoolConfig contains the default configurations for the transaction
@@ -155,6 +199,8 @@ var DefaultTxPoolConfig = TxPoolConfig{
 	GlobalQueue:  1024,
 
 	Lifetime: 3 * time.Hour,
+
+	SenderRateWindow: time.Minute,
 }
 
 // sanitize checks the provided user configurations and changes anything that's
@@ -173,9 +219,29 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 		log.Warn("Sanitizing invalid txpool price bump", "provided", conf.PriceBump, "updated", DefaultTxPoolConfig.PriceBump)
 		conf.PriceBump = DefaultTxPoolConfig.PriceBump
 	}
+	if conf.SenderRateLimit > 0 && conf.SenderRateWindow <= 0 {
+		log.Warn("Sanitizing invalid txpool sender rate window", "provided", conf.SenderRateWindow, "updated", DefaultTxPoolConfig.SenderRateWindow)
+		conf.SenderRateWindow = DefaultTxPoolConfig.SenderRateWindow
+	}
 	return conf
 }
 
+// policies assembles the admission rules of the pool, the built-in ones enabled
+// by the configuration followed by any custom ones.
+func (config *TxPoolConfig) policies() []TxPolicy {
+	var policies []TxPolicy
+	if len(config.AllowedSenders) > 0 {
+		policies = append(policies, NewSenderAllowlist(config.AllowedSenders))
+	}
+	if len(config.BlockedDestinations) > 0 {
+		policies = append(policies, NewDestinationBlocklist(config.BlockedDestinations))
+	}
+	if config.SenderRateLimit > 0 {
+		policies = append(policies, NewSenderRateLimiter(config.SenderRateLimit, config.SenderRateWindow))
+	}
+	return append(policies, config.Policies...)
+}
+
 // TxPool contains all currently known transactions. Transactions
 // enter the pool when they are received from the network or submitted
 // locally. They exit the pool when they are included in the blockchain.
@@ -189,6 +255,8 @@ type TxPool struct {
 	chain        blockChain
 	gasPrice     *big.Int
 	txFeed       event.Feed
+	dropFeed     event.Feed
+	queueFeed    event.Feed
 	scope        event.SubscriptionScope
 	chainHeadCh  chan ChainHeadEvent
 	chainHeadSub event.Subscription
@@ -199,8 +267,13 @@ type TxPool struct {
 	pendingState  *state.ManagedState // Pending state tracking virtual nonces
 	currentMaxGas uint64              // Current gas limit for transaction caps
 
-	locals  *accountSet // Set of local transaction to exempt from eviction rules
-	journal *txJournal  // Journal of local transaction to back up to disk
+	locals        *accountSet // Set of local transaction to exempt from eviction rules
+	policies      []TxPolicy  // Admission rules consulted for every new transaction
+	journal       *txJournal  // Journal of local transaction to back up to disk
+	remoteJournal *txJournal  // Journal of remote transactions to back up to disk
+
+	remoteJournaled uint64 // Number of transactions in the remote journal since its last rotation
+	reloading       bool   // Whether the journals are being loaded (skips policy accounting)
 
 	pending map[common.Address]*txList         // All currently processable transactions
 	queue   map[common.Address]*txList         // Queued but non-processable transactions
@@ -233,12 +306,16 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		gasPrice:    new(big.Int).SetUint64(config.PriceLimit),
 	}
 	pool.locals = newAccountSet(pool.signer)
+	pool.policies = config.policies()
 	pool.priced = newTxPricedList(&pool.all)
 	pool.reset(nil, chain.CurrentBlock().Header())
 
+	// Reload any journaled transactions without charging them to policy quotas
+	pool.reloading = true
+
 	// If local transactions and journaling is enabled, load from disk
 	if !config.NoLocals && config.Journal != "" {
-		pool.journal = newTxJournal(config.Journal)
+		pool.journal = newTxJournal(config.Journal, "local")
 
 		if err := pool.journal.load(pool.AddLocal); err != nil {
 			log.Warn("Failed to load transaction journal", "err", err)
@@ -247,6 +324,21 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 			log.Warn("Failed to rotate transaction journal", "err", err)
 		}
 	}
+	// If remote journaling is enabled, load from disk. The transactions go
+	// through the standard validation, dropping anything invalidated by the
+	// current head.
+	if config.RemoteJournal != "" {
+		pool.remoteJournal = newTxJournal(config.RemoteJournal, "remote")
+
+		if err := pool.remoteJournal.load(pool.AddRemote); err != nil {
+			log.Warn("Failed to load remote transaction journal", "err", err)
+		}
+		if err := pool.rotateRemoteJournal(); err != nil {
+			log.Warn("Failed to rotate remote transaction journal", "err", err)
+		}
+	}
+	pool.reloading = false
+
 	// Subscribe events from blockchain
 	pool.chainHeadSub = pool.chain.SubscribeChainHeadEvent(pool.chainHeadCh)
 
@@ -321,6 +413,7 @@ func (pool *TxPool) loop() {
 				if time.Since(pool.beats[addr]) > pool.config.Lifetime {
 					for _, tx := range pool.queue[addr].Flatten() {
 						pool.removeTx(tx.Hash())
+						pool.announceDrop(tx, TxDropExpired, common.Hash{})
 					}
 				}
 			}
@@ -335,6 +428,13 @@ func (pool *TxPool) loop() {
 				}
 				pool.mu.Unlock()
 			}
+			if pool.remoteJournal != nil {
+				pool.mu.Lock()
+				if err := pool.rotateRemoteJournal(); err != nil {
+					log.Warn("Failed to rotate remote tx journal", "err", err)
+				}
+				pool.mu.Unlock()
+			}
 		}
 	}
 }
@@ -443,6 +543,9 @@ func (pool *TxPool) Stop() {
 	if pool.journal != nil {
 		pool.journal.close()
 	}
+	if pool.remoteJournal != nil {
+		pool.remoteJournal.close()
+	}
 	log.Info("Transaction pool stopped")
 }
 
@@ -452,6 +555,18 @@ func (pool *TxPool) SubscribeTxPreEvent(ch chan<- TxPreEvent) event.Subscription
 	return pool.scope.Track(pool.txFeed.Subscribe(ch))
 }
 
+// SubscribeTxDropEvent registers a subscription of TxDropEvent and
+// starts sending event to the given channel.
+func (pool *TxPool) SubscribeTxDropEvent(ch chan<- TxDropEvent) event.Subscription {
+	return pool.scope.Track(pool.dropFeed.Subscribe(ch))
+}
+
+// SubscribeTxQueuedEvent registers a subscription of TxQueuedEvent and
+// starts sending event to the given channel.
+func (pool *TxPool) SubscribeTxQueuedEvent(ch chan<- TxQueuedEvent) event.Subscription {
+	return pool.scope.Track(pool.queueFeed.Subscribe(ch))
+}
+
 // GasPrice returns the current gas price enforced by the transaction pool.
 func (pool *TxPool) GasPrice() *big.Int {
 	pool.mu.RLock()
@@ -469,6 +584,7 @@ func (pool *TxPool) SetGasPrice(price *big.Int) {
 	pool.gasPrice = price
 	for _, tx := range pool.priced.Cap(price, pool.locals) {
 		pool.removeTx(tx.Hash())
+		pool.announceDrop(tx, TxDropUnderpriced, common.Hash{})
 	}
 	log.Info("Transaction pool price threshold updated", "price", price)
 }
@@ -551,6 +667,62 @@ func (pool *TxPool) local() map[common.Address]types.Transactions {
 	return txs
 }
 
+// remote retrieves the currently known remote transactions, groupped by origin
+// account and sorted by nonce, capped to the configured remote journal size.
+// When capping, pending transactions are preferred over queued ones, and within
+// each set the accounts whose next transaction pays the most go first, with ties
+// broken by address.
+func (pool *TxPool) remote() map[common.Address]types.Transactions {
+	var (
+		txs   = make(map[common.Address]types.Transactions)
+		limit = pool.config.RemoteJournalCap
+		count uint64
+	)
+	for _, lists := range []map[common.Address]*txList{pool.pending, pool.queue} {
+		// Order the remote accounts of the set deterministically
+		flat := make(map[common.Address]types.Transactions, len(lists))
+		addrs := make([]common.Address, 0, len(lists))
+		for addr, list := range lists {
+			if pool.locals.contains(addr) || list.Empty() {
+				continue
+			}
+			flat[addr] = list.Flatten()
+			addrs = append(addrs, addr)
+		}
+		sort.Slice(addrs, func(i, j int) bool {
+			if cmp := flat[addrs[i]][0].GasPrice().Cmp(flat[addrs[j]][0].GasPrice()); cmp != 0 {
+				return cmp > 0
+			}
+			return bytes.Compare(addrs[i][:], addrs[j][:]) < 0
+		})
+		// Collect the transactions until the cap is reached
+		for _, addr := range addrs {
+			for _, tx := range flat[addr] {
+				if limit > 0 && count >= limit {
+					return txs
+				}
+				txs[addr] = append(txs[addr], tx)
+				count++
+			}
+		}
+	}
+	return txs
+}
+
+// rotateRemoteJournal regenerates the remote transaction journal from the current
+// contents of the pool, resetting the count of journaled transactions.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) rotateRemoteJournal() error {
+	remotes := pool.remote()
+
+	pool.remoteJournaled = 0
+	for _, txs := range remotes {
+		pool.remoteJournaled += uint64(len(txs))
+	}
+	return pool.remoteJournal.rotate(remotes)
+}
+
 // validateTx checks whether a transaction is valid according to the consensus
 // rules and adheres to some heuristic limits of the local node (price and size).
 func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
@@ -617,6 +789,15 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 		invalidTxCounter.Inc(1)
 		return false, err
 	}
+	// If the transaction violates any admission policy, discard it
+	from, _ := types.Sender(pool.signer, tx) // already validated
+	for _, policy := range pool.policies {
+		if err := policy.Admit(tx, from, local); err != nil {
+			log.Trace("Discarding disallowed transaction", "hash", hash, "err", err)
+			rejectedTxCounter.Inc(1)
+			return false, err
+		}
+	}
 	// If the transaction pool is full, discard underpriced transactions
 	if uint64(len(pool.all)) >= pool.config.GlobalSlots+pool.config.GlobalQueue {
 		// If the new transaction is underpriced, don't accept it
@@ -631,10 +812,10 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 			log.Trace("Discarding freshly underpriced transaction", "hash", tx.Hash(), "price", tx.GasPrice())
 			underpricedTxCounter.Inc(1)
 			pool.removeTx(tx.Hash())
+			pool.announceDrop(tx, TxDropUnderpriced, common.Hash{})
 		}
 	}
 	// If the transaction is replacing an already pending one, do directly
-	from, _ := types.Sender(pool.signer, tx) // already validated
 	if list := pool.pending[from]; list != nil && list.Overlaps(tx) {
 		// Nonce already pending, check if required price bump is met
 		inserted, old := list.Add(tx, pool.config.PriceBump)
@@ -642,15 +823,19 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 			pendingDiscardCounter.Inc(1)
 			return false, ErrReplaceUnderpriced
 		}
+		pool.stampArrival(tx)
+
 		// New transaction is better, replace old one
 		if old != nil {
 			delete(pool.all, old.Hash())
 			pool.priced.Removed()
 			pendingReplaceCounter.Inc(1)
+			pool.announceDrop(old, TxDropReplaced, hash)
 		}
 		pool.all[tx.Hash()] = tx
 		pool.priced.Put(tx)
 		pool.journalTx(from, tx)
+		pool.recordAdmission(tx, from, local)
 
 		log.Trace("Pooled new executable transaction", "hash", hash, "from", from, "to", tx.To())
 
@@ -664,16 +849,45 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 	if err != nil {
 		return false, err
 	}
+	pool.stampArrival(tx)
+
 	// Mark local addresses and journal local transactions
 	if local {
 		pool.locals.add(from)
 	}
 	pool.journalTx(from, tx)
+	pool.recordAdmission(tx, from, local)
 
 	log.Trace("Pooled new future transaction", "hash", hash, "from", from, "to", tx.To())
 	return replace, nil
 }
 
+// stampArrival sets the arrival time of a newly accepted transaction. The time
+// of a transaction seen before, i.e. reinjected after a reorg or loaded from a
+// journal, is kept, so it doesn't lose its place in the arrival order.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) stampArrival(tx *types.Transaction) {
+	if tx.Time().IsZero() {
+		tx.SetTime(time.Now())
+	}
+}
+
+// recordAdmission notifies the stateful admission policies of a transaction that
+// entered the pool, unless it's being reloaded from a journal.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) recordAdmission(tx *types.Transaction, from common.Address, local bool) {
+	if pool.reloading {
+		return
+	}
+	for _, policy := range pool.policies {
+		if policy, ok := policy.(StatefulTxPolicy); ok {
+			policy.Admitted(tx, from, local)
+		}
+	}
+}
+
 // enqueueTx inserts a new transaction into the non-executable transaction queue.
 //
 // Note, this method assumes the pool lock is held!
@@ -694,6 +908,7 @@ func (pool *TxPool) enqueueTx(hash common.Hash, tx *types.Transaction) (bool, er
 		delete(pool.all, old.Hash())
 		pool.priced.Removed()
 		queuedReplaceCounter.Inc(1)
+		pool.announceDrop(old, TxDropReplaced, hash)
 	}
 	pool.all[hash] = tx
 	pool.priced.Put(tx)
@@ -701,15 +916,30 @@ func (pool *TxPool) enqueueTx(hash common.Hash, tx *types.Transaction) (bool, er
 }
 
 // journalTx adds the specified transaction to the local disk journal if it is
-// deemed to have been sent from a local account.
+// deemed to have been sent from a local account, or to the remote journal if
+// that is enabled and did not reach its cap since the last rotation.
 func (pool *TxPool) journalTx(from common.Address, tx *types.Transaction) {
-	// Only journal if it's enabled and the transaction is local
-	if pool.journal == nil || !pool.locals.contains(from) {
+	if pool.locals.contains(from) {
+		// Only journal if it's enabled and the transaction is local
+		if pool.journal == nil {
+			return
+		}
+		if err := pool.journal.insert(tx); err != nil {
+			log.Warn("Failed to journal local transaction", "err", err)
+		}
 		return
 	}
-	if err := pool.journal.insert(tx); err != nil {
-		log.Warn("Failed to journal local transaction", "err", err)
+	if pool.remoteJournal == nil {
+		return
+	}
+	if limit := pool.config.RemoteJournalCap; limit > 0 && pool.remoteJournaled >= limit {
+		return
+	}
+	if err := pool.remoteJournal.insert(tx); err != nil {
+		log.Warn("Failed to journal remote transaction", "err", err)
+		return
 	}
+	pool.remoteJournaled++
 }
 
 // promoteTx adds a transaction to the pending (processable) list of transactions.
@@ -729,6 +959,7 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 		pool.priced.Removed()
 
 		pendingDiscardCounter.Inc(1)
+		pool.announceDrop(tx, TxDropUnderpriced, common.Hash{})
 		return
 	}
 	// Otherwise discard any previous transaction and mark this
@@ -737,6 +968,7 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 		pool.priced.Removed()
 
 		pendingReplaceCounter.Inc(1)
+		pool.announceDrop(old, TxDropReplaced, hash)
 	}
 	// Failsafe to work around direct pending inserts (tests)
 	if pool.all[hash] == nil {
@@ -793,6 +1025,7 @@ func (pool *TxPool) addTx(tx *types.Transaction, local bool) error {
 		from, _ := types.Sender(pool.signer, tx) // already validated
 		pool.promoteExecutables([]common.Address{from})
 	}
+	pool.announceQueued([]*types.Transaction{tx})
 	return nil
 }
 
@@ -810,10 +1043,12 @@ func (pool *TxPool) addTxsLocked(txs []*types.Transaction, local bool) []error {
 	// Add the batch of transaction, tracking the accepted ones
 	dirty := make(map[common.Address]struct{})
 	errs := make([]error, len(txs))
+	added := make([]*types.Transaction, 0, len(txs))
 
 	for i, tx := range txs {
 		var replace bool
 		if replace, errs[i] = pool.add(tx, local); errs[i] == nil {
+			added = append(added, tx)
 			if !replace {
 				from, _ := types.Sender(pool.signer, tx) // already validated
 				dirty[from] = struct{}{}
@@ -828,9 +1063,23 @@ func (pool *TxPool) addTxsLocked(txs []*types.Transaction, local bool) []error {
 		}
 		pool.promoteExecutables(addrs)
 	}
+	pool.announceQueued(added)
 	return errs
 }
 
+// announceQueued notifies the subscribers of the freshly added transactions that
+// remained in the non-executable queue after the promotion checks.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) announceQueued(txs []*types.Transaction) {
+	for _, tx := range txs {
+		from, _ := types.Sender(pool.signer, tx) // already validated
+		if list := pool.queue[from]; list != nil && list.txs.Get(tx.Nonce()) == tx {
+			go pool.queueFeed.Send(TxQueuedEvent{tx})
+		}
+	}
+}
+
 // Status returns the status (unknown/pending/queued) of a batch of transactions
 // identified by their hashes.
 func (pool *TxPool) Status(hashes []common.Hash) []TxStatus {
@@ -860,6 +1109,11 @@ func (pool *TxPool) Get(hash common.Hash) *types.Transaction {
 	return pool.all[hash]
 }
 
+// announceDrop notifies any subsystems of a transaction dropped from the pool.
+func (pool *TxPool) announceDrop(tx *types.Transaction, reason TxDropReason, replacement common.Hash) {
+	go pool.dropFeed.Send(TxDropEvent{Tx: tx, Reason: reason, Replacement: replacement})
+}
+
 // removeTx removes a single transaction from the queue, moving all subsequent
 // transactions back to the future queue.
 func (pool *TxPool) removeTx(hash common.Hash) {
@@ -926,6 +1180,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			log.Trace("Removed old queued transaction", "hash", hash)
 			delete(pool.all, hash)
 			pool.priced.Removed()
+			pool.announceDrop(tx, TxDropStale, common.Hash{})
 		}
 		// Drop all transactions that are too costly (low balance or out of gas)
 		drops, _ := list.Filter(pool.currentState.GetBalance(addr), pool.currentMaxGas)
@@ -935,6 +1190,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			delete(pool.all, hash)
 			pool.priced.Removed()
 			queuedNofundsCounter.Inc(1)
+			pool.announceDrop(tx, TxDropUnpayable, common.Hash{})
 		}
 		// Gather all executable transactions and promote them
 		for _, tx := range list.Ready(pool.pendingState.GetNonce(addr)) {
@@ -949,6 +1205,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 				delete(pool.all, hash)
 				pool.priced.Removed()
 				queuedRateLimitCounter.Inc(1)
+				pool.announceDrop(tx, TxDropRateLimit, common.Hash{})
 				log.Trace("Removed cap-exceeding queued transaction", "hash", hash)
 			}
 		}
@@ -993,6 +1250,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 							hash := tx.Hash()
 							delete(pool.all, hash)
 							pool.priced.Removed()
+							pool.announceDrop(tx, TxDropRateLimit, common.Hash{})
 
 							// Update the account nonce to the dropped transaction
 							if nonce := tx.Nonce(); pool.pendingState.GetNonce(offenders[i]) > nonce {
@@ -1015,6 +1273,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 						hash := tx.Hash()
 						delete(pool.all, hash)
 						pool.priced.Removed()
+						pool.announceDrop(tx, TxDropRateLimit, common.Hash{})
 
 						// Update the account nonce to the dropped transaction
 						if nonce := tx.Nonce(); pool.pendingState.GetNonce(addr) > nonce {
@@ -1054,6 +1313,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			if size := uint64(list.Len()); size <= drop {
 				for _, tx := range list.Flatten() {
 					pool.removeTx(tx.Hash())
+					pool.announceDrop(tx, TxDropRateLimit, common.Hash{})
 				}
 				drop -= size
 				queuedRateLimitCounter.Inc(int64(size))
@@ -1063,6 +1323,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			txs := list.Flatten()
 			for i := len(txs) - 1; i >= 0 && drop > 0; i-- {
 				pool.removeTx(txs[i].Hash())
+				pool.announceDrop(txs[i], TxDropRateLimit, common.Hash{})
 				drop--
 				queuedRateLimitCounter.Inc(1)
 			}
@@ -1084,6 +1345,7 @@ func (pool *TxPool) demoteUnexecutables() {
 			log.Trace("Removed old pending transaction", "hash", hash)
 			delete(pool.all, hash)
 			pool.priced.Removed()
+			pool.announceDrop(tx, TxDropStale, common.Hash{})
 		}
 		// Drop all transactions that are too costly (low balance or out of gas), and queue any invalids back for later
 		drops, invalids := list.Filter(pool.currentState.GetBalance(addr), pool.currentMaxGas)
@@ -1093,18 +1355,23 @@ func (pool *TxPool) demoteUnexecutables() {
 			delete(pool.all, hash)
 			pool.priced.Removed()
 			pendingNofundsCounter.Inc(1)
+			pool.announceDrop(tx, TxDropUnpayable, common.Hash{})
 		}
 		for _, tx := range invalids {
 			hash := tx.Hash()
 			log.Trace("Demoting pending transaction", "hash", hash)
-			pool.enqueueTx(hash, tx)
+			if _, err := pool.enqueueTx(hash, tx); err == nil {
+				go pool.queueFeed.Send(TxQueuedEvent{tx})
+			}
 		}
 		// If there's a gap in front, warn (should never happen) and postpone all transactions
 		if list.Len() > 0 && list.txs.Get(nonce) == nil {
 			for _, tx := range list.Cap(0) {
 				hash := tx.Hash()
 				log.Error("Demoting invalidated transaction", "hash", hash)
-				pool.enqueueTx(hash, tx)
+				if _, err := pool.enqueueTx(hash, tx); err == nil {
+					go pool.queueFeed.Send(TxQueuedEvent{tx})
+				}
 			}
 		}
 		// Delete the entire queue entry if it became empty.
@@ -17,6 +17,7 @@
 package core
 
 import (
+	"bytes"
 	"crypto/ecdsa"
 	"fmt"
 	"io/ioutil"
@@ -33,6 +34,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 // testTxPoolConfig is a transaction pool configuration without stateful disk
@@ -1449,6 +1451,189 @@ func TestTransactionReplacement(t *testing.T) {
 	}
 }
 
+// Tests that transactions dropped from the pool are announced along with the
+// reason of the drop.
+func TestTransactionDropEvents(t *testing.T) {
+	t.Parallel()
+
+	// Create a test account and fund it
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	account, _ := deriveSender(transaction(0, 0, key))
+	pool.currentState.AddBalance(account, big.NewInt(1000000))
+
+	events := make(chan TxDropEvent, 32)
+	sub := pool.SubscribeTxDropEvent(events)
+	defer sub.Unsubscribe()
+
+	// Replace a pending transaction, add a future one and invalidate both
+	original := pricedTransaction(0, 100000, big.NewInt(1), key)
+	replacement := pricedTransaction(0, 100000, big.NewInt(2), key)
+	future := transaction(2, 100000, key)
+
+	for i, tx := range []*types.Transaction{original, replacement, future} {
+		if err := pool.AddRemote(tx); err != nil {
+			t.Fatalf("tx %d: failed to add transaction: %v", i, err)
+		}
+	}
+	pool.currentState.SetNonce(account, 1)
+	pool.currentState.SetBalance(account, big.NewInt(1000))
+	pool.lockedReset(nil, nil)
+
+	// Collect the drop events and ensure they are reported correctly
+	drops := make(map[common.Hash]TxDropEvent)
+	for i := 0; i < 3; i++ {
+		select {
+		case ev := <-events:
+			drops[ev.Tx.Hash()] = ev
+		case <-time.After(time.Second):
+			t.Fatalf("drop event #%d not fired", i)
+		}
+	}
+	if ev := drops[original.Hash()]; ev.Reason != TxDropReplaced || ev.Replacement != replacement.Hash() {
+		t.Errorf("replaced transaction event mismatch: have %v/%x, want %v/%x", ev.Reason, ev.Replacement, TxDropReplaced, replacement.Hash())
+	}
+	if ev := drops[replacement.Hash()]; ev.Reason != TxDropStale {
+		t.Errorf("stale transaction reason mismatch: have %v, want %v", ev.Reason, TxDropStale)
+	}
+	if ev := drops[future.Hash()]; ev.Reason != TxDropUnpayable {
+		t.Errorf("unpayable transaction reason mismatch: have %v, want %v", ev.Reason, TxDropUnpayable)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that transactions entering the queue, either as new future transactions
+// or demoted from the pending set, are announced.
+func TestTransactionQueuedEvents(t *testing.T) {
+	t.Parallel()
+
+	// Create a test account and fund it
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	account, _ := deriveSender(transaction(0, 0, key))
+	pool.currentState.AddBalance(account, big.NewInt(1000000))
+
+	events := make(chan TxQueuedEvent, 32)
+	sub := pool.SubscribeTxQueuedEvent(events)
+	defer sub.Unsubscribe()
+
+	// Add a future transaction, then fill the nonce gap to promote it
+	future := transaction(2, 100000, key)
+	if err := pool.AddRemote(future); err != nil {
+		t.Fatalf("failed to add future transaction: %v", err)
+	}
+	select {
+	case ev := <-events:
+		if ev.Tx.Hash() != future.Hash() {
+			t.Errorf("queued transaction mismatch: have %x, want %x", ev.Tx.Hash(), future.Hash())
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("queued event not fired")
+	}
+	expensive := transaction(1, 200000, key)
+	for i, tx := range []*types.Transaction{transaction(0, 100000, key), expensive} {
+		if err := pool.AddRemote(tx); err != nil {
+			t.Fatalf("tx %d: failed to add transaction: %v", i, err)
+		}
+	}
+	if pending, queued := pool.Stats(); pending != 3 || queued != 0 {
+		t.Fatalf("pool stats mismatch: have %d/%d, want 3/0", pending, queued)
+	}
+	// Make the middle transaction unpayable and ensure the last one is demoted
+	pool.currentState.SetBalance(account, big.NewInt(150000))
+	pool.lockedReset(nil, nil)
+
+	select {
+	case ev := <-events:
+		if ev.Tx.Hash() != future.Hash() {
+			t.Errorf("demoted transaction mismatch: have %x, want %x", ev.Tx.Hash(), future.Hash())
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("demotion event not fired")
+	}
+	select {
+	case ev := <-events:
+		t.Errorf("unexpected queued event: %x", ev.Tx.Hash())
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// Tests that transactions are stamped with their arrival time when accepted by
+// the pool, and that rejected duplicates don't change it.
+func TestTransactionArrivalTime(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	account, _ := deriveSender(transaction(0, 0, key))
+	pool.currentState.AddBalance(account, big.NewInt(1000000))
+
+	tx := transaction(0, 100000, key)
+	if !tx.Time().IsZero() {
+		t.Fatalf("fresh transaction has arrival time %v", tx.Time())
+	}
+	before := time.Now()
+	if err := pool.AddRemote(tx); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	arrival := tx.Time()
+	if arrival.Before(before) || arrival.After(time.Now()) {
+		t.Fatalf("arrival time %v out of range [%v, now]", arrival, before)
+	}
+	// Re-add a decoded copy and ensure the pooled one keeps its time
+	blob, _ := rlp.EncodeToBytes(tx)
+	dup := new(types.Transaction)
+	if err := rlp.DecodeBytes(blob, dup); err != nil {
+		t.Fatalf("failed to decode transaction: %v", err)
+	}
+	if err := pool.AddRemote(dup); err == nil {
+		t.Fatalf("duplicate transaction accepted")
+	}
+	if pooled := pool.Get(tx.Hash()); pooled.Time() != arrival {
+		t.Errorf("arrival time changed: have %v, want %v", pooled.Time(), arrival)
+	}
+	if !dup.Time().IsZero() {
+		t.Errorf("rejected duplicate stamped with %v", dup.Time())
+	}
+	// Ensure underpriced replacements are not stamped either
+	replacement := pricedTransaction(0, 90000, big.NewInt(1), key)
+	if err := pool.AddRemote(replacement); err != ErrReplaceUnderpriced {
+		t.Fatalf("replacement error mismatch: have %v, want %v", err, ErrReplaceUnderpriced)
+	}
+	if !replacement.Time().IsZero() {
+		t.Errorf("rejected replacement stamped with %v", replacement.Time())
+	}
+	// Ensure transactions seen before, e.g. reinjected after a reorg, keep their time
+	seen := transaction(1, 100000, key)
+	seen.SetTime(arrival.Add(-time.Hour))
+	if err := pool.AddRemote(seen); err != nil {
+		t.Fatalf("failed to add seen transaction: %v", err)
+	}
+	if have := seen.Time(); have != arrival.Add(-time.Hour) {
+		t.Errorf("seen transaction restamped: have %v, want %v", have, arrival.Add(-time.Hour))
+	}
+	// Ensure the journal preserves the arrival time, and still loads bare transactions
+	var journal bytes.Buffer
+	if err := encodeJournalEntry(&journal, tx); err != nil {
+		t.Fatalf("failed to journal transaction: %v", err)
+	}
+	loaded, err := decodeJournalEntry(journal.Bytes())
+	if err != nil {
+		t.Fatalf("failed to load journaled transaction: %v", err)
+	}
+	if loaded.Hash() != tx.Hash() || !loaded.Time().Equal(arrival) {
+		t.Errorf("journaled transaction mismatch: have %x at %v, want %x at %v", loaded.Hash(), loaded.Time(), tx.Hash(), arrival)
+	}
+	if loaded, err = decodeJournalEntry(blob); err != nil || loaded.Hash() != tx.Hash() || !loaded.Time().IsZero() {
+		t.Errorf("bare journaled transaction mismatch: have %v/%v", loaded, err)
+	}
+}
+
 // Tests that local transactions are journaled to disk, but remote transactions
 // get discarded between restarts.
 func TestTransactionJournaling(t *testing.T)         { testTransactionJournaling(t, false) }
@@ -1563,6 +1748,111 @@ func testTransactionJournaling(t *testing.T, nolocals bool) {
 	pool.Stop()
 }
 
+// Tests that remote transactions are journaled to disk if requested, capped to
+// the configured limit and revalidated against the current head on reload.
+func TestTransactionRemoteJournaling(t *testing.T) {
+	t.Parallel()
+
+	// Create a temporary file for the journal
+	file, err := ioutil.TempFile("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary journal: %v", err)
+	}
+	journal := file.Name()
+	defer os.Remove(journal)
+
+	// Clean up the temporary file, we only need the path for now
+	file.Close()
+	os.Remove(journal)
+
+	// Create the original pool to inject transaction into the journal
+	db, _ := ethdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.RemoteJournal = journal
+	config.RemoteJournalCap = 4
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+
+	// Add three pending and two queued remote transactions
+	remote, _ := crypto.GenerateKey()
+	account := crypto.PubkeyToAddress(remote.PublicKey)
+	pool.currentState.AddBalance(account, big.NewInt(1000000000))
+
+	for _, nonce := range []uint64{0, 1, 2, 4, 5} {
+		if err := pool.AddRemote(pricedTransaction(nonce, 100000, big.NewInt(1), remote)); err != nil {
+			t.Fatalf("failed to add remote transaction %d: %v", nonce, err)
+		}
+	}
+	pending, queued := pool.Stats()
+	if pending != 3 || queued != 2 {
+		t.Fatalf("pool content mismatch: have %d/%d, want %d/%d", pending, queued, 3, 2)
+	}
+	// Ensure the transactions hit the disk on arrival, so a crash doesn't lose them
+	journaled := 0
+	if err := newTxJournal(journal, "remote").load(func(*types.Transaction) error { journaled++; return nil }); err != nil {
+		t.Fatalf("failed to read journal: %v", err)
+	}
+	if journaled != 4 {
+		t.Fatalf("journaled transaction count mismatch: have %d, want %d", journaled, 4)
+	}
+	// Terminate the old pool, bump the nonce, create a new pool and ensure the
+	// capped set of still valid transactions survive
+	pool.Stop()
+	statedb.SetNonce(account, 1)
+	blockchain = &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	pool = NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	pending, queued = pool.Stats()
+	if pending != 2 || queued != 1 {
+		t.Fatalf("pool content mismatch: have %d/%d, want %d/%d", pending, queued, 2, 1)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that the remote transactions to journal are capped deterministically,
+// preferring pending transactions and better paying accounts.
+func TestTransactionRemoteJournalCap(t *testing.T) {
+	t.Parallel()
+
+	pool, _ := setupTxPool()
+	defer pool.Stop()
+	pool.config.RemoteJournalCap = 3
+
+	cheap, _ := crypto.GenerateKey()
+	pricey, _ := crypto.GenerateKey()
+	for _, key := range []*ecdsa.PrivateKey{cheap, pricey} {
+		pool.currentState.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+	}
+	txs := types.Transactions{
+		pricedTransaction(0, 100000, big.NewInt(1), cheap),
+		pricedTransaction(1, 100000, big.NewInt(1), cheap),
+		pricedTransaction(0, 100000, big.NewInt(2), pricey),
+		pricedTransaction(1, 100000, big.NewInt(2), pricey),
+		pricedTransaction(3, 100000, big.NewInt(3), pricey),
+	}
+	for i, err := range pool.AddRemotes(txs) {
+		if err != nil {
+			t.Fatalf("tx %d: failed to add transaction: %v", i, err)
+		}
+	}
+	for i := 0; i < 10; i++ {
+		remotes := pool.remote()
+		if have := remotes[crypto.PubkeyToAddress(pricey.PublicKey)]; len(have) != 2 || have[0] != txs[2] || have[1] != txs[3] {
+			t.Fatalf("run %d: pricey account transactions mismatch: have %v", i, have)
+		}
+		if have := remotes[crypto.PubkeyToAddress(cheap.PublicKey)]; len(have) != 1 || have[0] != txs[0] {
+			t.Fatalf("run %d: cheap account transactions mismatch: have %v", i, have)
+		}
+	}
+}
+
 // TestTransactionStatusCheck tests that the pool can correctly retrieve the
 // pending status of individual transactions.
 func TestTransactionStatusCheck(t *testing.T) {
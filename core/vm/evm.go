@@ -114,6 +114,10 @@ type EVM struct {
 // NewEVM retutrns a new EVM . The returned EVM is not thread safe and should
 // only ever be used *once*.
 func NewEVM(ctx Context, statedb StateDB, chainConfig *params.ChainConfig, vmConfig Config) *EVM {
+	// Report all state accesses if the tracer is interested in them
+	if tracer, ok := vmConfig.Tracer.(StateTracer); ok && vmConfig.Debug {
+		statedb = &tracingStateDB{StateDB: statedb, tracer: tracer}
+	}
 	evm := &EVM{
 		Context:     ctx,
 		StateDB:     statedb,
@@ -17,6 +17,7 @@
 package vm
 
 import (
+	"encoding/json"
 	"math/big"
 	"testing"
 
@@ -68,3 +69,23 @@ func TestStoreCapture(t *testing.T) {
 		t.Errorf("expected %x, got %x", exp, logger.changedValues[contract.Address()][index])
 	}
 }
+
+// Tests that the errors of the structured logs are reported as messages.
+func TestFormatLogsError(t *testing.T) {
+	logs := FormatLogs([]StructLog{{Op: STOP}, {Op: SSTORE, Err: ErrOutOfGas}})
+
+	blob, err := json.Marshal(logs)
+	if err != nil {
+		t.Fatalf("failed to encode logs: %v", err)
+	}
+	var decoded []map[string]interface{}
+	if err := json.Unmarshal(blob, &decoded); err != nil {
+		t.Fatalf("failed to decode logs: %v", err)
+	}
+	if _, ok := decoded[0]["error"]; ok {
+		t.Errorf("error reported for successful step: %v", decoded[0])
+	}
+	if msg := decoded[1]["error"]; msg != ErrOutOfGas.Error() {
+		t.Errorf("error mismatch: have %v, want %q", msg, ErrOutOfGas.Error())
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"compress/gzip"
+	"io"
+	"sort"
+	"time"
+)
+
+// Field numbers of the pprof profile.proto messages used by the profiler. For
+// the full format see https://github.com/google/pprof/blob/master/proto/profile.proto.
+const (
+	pprofProfileSampleType    = 1
+	pprofProfileSample        = 2
+	pprofProfileLocation      = 4
+	pprofProfileFunction      = 5
+	pprofProfileStringTable   = 6
+	pprofProfileTimeNanos     = 9
+	pprofProfileDurationNanos = 10
+
+	pprofValueTypeType = 1
+	pprofValueTypeUnit = 2
+
+	pprofSampleLocationID = 1
+	pprofSampleValue      = 2
+	pprofSampleLabel      = 3
+
+	pprofLabelKey = 1
+	pprofLabelStr = 2
+
+	pprofLocationID      = 1
+	pprofLocationAddress = 3
+	pprofLocationLine    = 4
+
+	pprofLineFunctionID = 1
+	pprofLineLine       = 2
+
+	pprofFunctionID       = 1
+	pprofFunctionName     = 2
+	pprofFunctionFilename = 4
+)
+
+// pprofBuffer is a minimal protocol buffer encoder, sufficient to assemble
+// pprof profiles without depending on the generated protobuf code.
+type pprofBuffer struct {
+	data []byte
+}
+
+func (b *pprofBuffer) varint(x uint64) {
+	for x >= 0x80 {
+		b.data = append(b.data, byte(x)|0x80)
+		x >>= 7
+	}
+	b.data = append(b.data, byte(x))
+}
+
+func (b *pprofBuffer) uint64(field int, x uint64) {
+	if x != 0 {
+		b.varint(uint64(field)<<3 | 0)
+		b.varint(x)
+	}
+}
+
+func (b *pprofBuffer) bytes(field int, data []byte) {
+	b.varint(uint64(field)<<3 | 2)
+	b.varint(uint64(len(data)))
+	b.data = append(b.data, data...)
+}
+
+func (b *pprofBuffer) packed(field int, xs []uint64) {
+	var packed pprofBuffer
+	for _, x := range xs {
+		packed.varint(x)
+	}
+	b.bytes(field, packed.data)
+}
+
+func (b *pprofBuffer) message(field int, build func(msg *pprofBuffer)) {
+	var msg pprofBuffer
+	build(&msg)
+	b.bytes(field, msg.data)
+}
+
+// WriteProfile writes the per program counter costs of the profiled executions
+// to w as a gzipped pprof profile, analysable with `go tool pprof`.
+//
+// Every sample's stack consists of a pseudo frame named after the executed
+// opcode, followed by the program counters of the call frames, with the code's
+// address as the function name and the program counter as the line number. The
+// samples are also labelled with the opcode, so they can be filtered by tags.
+func (p *OpcodeProfiler) WriteProfile(w io.Writer) error {
+	var (
+		prof    pprofBuffer
+		strings = map[string]uint64{"": 0}
+		table   = []string{""}
+
+		functions = make(map[string]uint64)
+		locations = make(map[interface{}]uint64)
+	)
+	str := func(s string) uint64 {
+		if id, ok := strings[s]; ok {
+			return id
+		}
+		strings[s] = uint64(len(table))
+		table = append(table, s)
+		return strings[s]
+	}
+	function := func(name string) uint64 {
+		if id, ok := functions[name]; ok {
+			return id
+		}
+		id := uint64(len(functions) + 1)
+		functions[name] = id
+		prof.message(pprofProfileFunction, func(msg *pprofBuffer) {
+			msg.uint64(pprofFunctionID, id)
+			msg.uint64(pprofFunctionName, str(name))
+			msg.uint64(pprofFunctionFilename, str(name))
+		})
+		return id
+	}
+	location := func(key interface{}, name string, pc uint64) uint64 {
+		if id, ok := locations[key]; ok {
+			return id
+		}
+		fn := function(name)
+
+		id := uint64(len(locations) + 1)
+		locations[key] = id
+		prof.message(pprofProfileLocation, func(msg *pprofBuffer) {
+			msg.uint64(pprofLocationID, id)
+			msg.uint64(pprofLocationAddress, pc)
+			msg.message(pprofLocationLine, func(line *pprofBuffer) {
+				line.uint64(pprofLineFunctionID, fn)
+				line.uint64(pprofLineLine, pc)
+			})
+		})
+		return id
+	}
+	// Declare the sample value types
+	for _, typ := range [][2]string{{"instructions", "count"}, {"gas", "gas"}, {"time", "nanoseconds"}} {
+		prof.message(pprofProfileSampleType, func(msg *pprofBuffer) {
+			msg.uint64(pprofValueTypeType, str(typ[0]))
+			msg.uint64(pprofValueTypeUnit, str(typ[1]))
+		})
+	}
+	// Emit the samples in a deterministic order, along with their locations
+	keys := make([]string, 0, len(p.samples))
+	for key := range p.samples {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		sample := p.samples[key]
+
+		ids := make([]uint64, 0, len(sample.stack)+1)
+		ids = append(ids, location(sample.op, sample.op.String(), 0))
+		for _, loc := range sample.stack {
+			ids = append(ids, location(loc, loc.code.Hex(), loc.pc))
+		}
+		prof.message(pprofProfileSample, func(msg *pprofBuffer) {
+			msg.packed(pprofSampleLocationID, ids)
+			msg.packed(pprofSampleValue, []uint64{sample.stat.Count, sample.stat.Gas, uint64(sample.stat.Time)})
+			msg.message(pprofSampleLabel, func(label *pprofBuffer) {
+				label.uint64(pprofLabelKey, str("opcode"))
+				label.uint64(pprofLabelStr, str(sample.op.String()))
+			})
+		})
+	}
+	prof.uint64(pprofProfileTimeNanos, uint64(time.Now().UnixNano()))
+	prof.uint64(pprofProfileDurationNanos, uint64(p.duration))
+
+	// String table must be emitted last, after all the strings were interned
+	for _, s := range table {
+		prof.bytes(pprofProfileStringTable, []byte(s))
+	}
+	zw := gzip.NewWriter(w)
+	if _, err := zw.Write(prof.data); err != nil {
+		return err
+	}
+	return zw.Close()
+}
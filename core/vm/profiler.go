@@ -0,0 +1,275 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ProfileStat is the aggregated execution cost of a set of EVM instructions.
+type ProfileStat struct {
+	Count uint64        `json:"count"` // Number of instructions executed
+	Gas   uint64        `json:"gas"`   // Gas consumed, excluding any nested calls
+	Time  time.Duration `json:"time"`  // Wall time spent (in nanoseconds), excluding any nested calls
+}
+
+// add accounts a single executed instruction into the statistic.
+func (s *ProfileStat) add(gas uint64, elapsed time.Duration) {
+	s.Count++
+	s.Gas += gas
+	s.Time += elapsed
+}
+
+// OpcodeProfile is the aggregated execution cost of a single opcode.
+type OpcodeProfile struct {
+	Op string `json:"op"`
+	ProfileStat
+}
+
+// ContractProfile is the aggregated execution cost of a single contract code.
+type ContractProfile struct {
+	Address common.Address `json:"address"`
+	ProfileStat
+}
+
+// ProfileResult is the summary of an execution profile.
+type ProfileResult struct {
+	Opcodes   []OpcodeProfile   `json:"opcodes"`   // Per opcode costs, most gas consuming first
+	Contracts []ContractProfile `json:"contracts"` // Per contract costs, most gas consuming first
+	Profile   hexutil.Bytes     `json:"profile"`   // Gzipped pprof profile with the per PC costs
+}
+
+// profileLocation is a single position in the executed bytecode.
+type profileLocation struct {
+	code common.Address // Address of the code being executed
+	pc   uint64         // Program counter within the code
+}
+
+// profileSample is the aggregated cost of an instruction executed with the same
+// call stack.
+type profileSample struct {
+	op    OpCode            // Opcode executed at the innermost location
+	stack []profileLocation // Call stack of the instruction, innermost first
+	stat  ProfileStat       // Aggregated cost of the executions
+}
+
+// profileFrame tracks the execution of a single call frame.
+type profileFrame struct {
+	code common.Address // Address of the code executed by the frame
+
+	pc    uint64    // Program counter of the pending instruction
+	op    OpCode    // Opcode of the pending instruction
+	gas   uint64    // Gas available before the pending instruction
+	cost  uint64    // Gas cost of the pending instruction
+	err   error     // Pre-execution failure of the pending instruction
+	start time.Time // Time when the pending instruction was started
+
+	childGas  uint64        // Gas consumed by nested calls of the pending instruction
+	childTime time.Duration // Time spent in nested calls of the pending instruction
+	totalGas  uint64        // Gas consumed by the frame, including nested calls
+	totalTime time.Duration // Time spent in the frame, including nested calls
+}
+
+// OpcodeProfiler is a lightweight EVM tracer which implements Tracer. Instead of
+// logging every step, it aggregates the gas consumed, the execution count and
+// the wall time spent per opcode, per contract code and per program counter.
+//
+// The costs of an instruction are only known when the next one is reached, so
+// each call frame keeps its last instruction pending until then. Nested calls
+// are accounted to the callee's instructions, not the calling one.
+type OpcodeProfiler struct {
+	frames []*profileFrame // Active call frames, outermost first
+
+	opcodes   map[OpCode]*ProfileStat
+	contracts map[common.Address]*ProfileStat
+	samples   map[string]*profileSample
+
+	duration time.Duration // Total time spent in the profiled executions
+}
+
+// NewOpcodeProfiler creates a new EVM execution profiler.
+func NewOpcodeProfiler() *OpcodeProfiler {
+	return &OpcodeProfiler{
+		opcodes:   make(map[OpCode]*ProfileStat),
+		contracts: make(map[common.Address]*ProfileStat),
+		samples:   make(map[string]*profileSample),
+	}
+}
+
+func (p *OpcodeProfiler) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	return nil
+}
+
+// CaptureState finalises the cost of the previous instruction of the current
+// call frame (and any returned frames) and makes the new one pending.
+func (p *OpcodeProfiler) CaptureState(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	now := time.Now()
+
+	// Finalise any nested frames returned from
+	for len(p.frames) > depth {
+		p.pop(now)
+	}
+	// Finalise the pending instruction of the current frame, or enter a new one
+	if len(p.frames) == depth {
+		frame := p.frames[len(p.frames)-1]
+
+		used := frame.gas - gas
+		if frame.gas < gas || used < frame.childGas {
+			used = frame.childGas
+		}
+		p.record(now, used-frame.childGas)
+	} else {
+		code := contract.Address()
+		if contract.CodeAddr != nil {
+			code = *contract.CodeAddr
+		}
+		p.frames = append(p.frames, &profileFrame{code: code})
+	}
+	frame := p.frames[len(p.frames)-1]
+	frame.pc, frame.op, frame.gas, frame.cost, frame.err, frame.start = pc, op, gas, cost, err, now
+	frame.childGas, frame.childTime = 0, 0
+
+	return nil
+}
+
+func (p *OpcodeProfiler) CaptureFault(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureEnd finalises all the frames still pending.
+func (p *OpcodeProfiler) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error {
+	now := time.Now()
+	for len(p.frames) > 0 {
+		p.pop(now)
+	}
+	p.duration += t
+	return nil
+}
+
+// pop finalises the pending instruction of the innermost call frame and leaves
+// it, accounting its total costs to the nested calls of its parent. As no later
+// instruction exists in the frame, the instruction's own gas cost is used, unless
+// it failed prior to execution, in which case all the gas available is consumed.
+func (p *OpcodeProfiler) pop(now time.Time) {
+	frame := p.frames[len(p.frames)-1]
+	if frame.err != nil {
+		p.record(now, frame.gas)
+	} else {
+		p.record(now, frame.cost)
+	}
+	p.frames = p.frames[:len(p.frames)-1]
+
+	if len(p.frames) > 0 {
+		parent := p.frames[len(p.frames)-1]
+		parent.childGas += frame.totalGas
+		parent.childTime += frame.totalTime
+	}
+}
+
+// record accounts the pending instruction of the innermost call frame with the
+// given gas consumption and the time elapsed since it started.
+func (p *OpcodeProfiler) record(now time.Time, gas uint64) {
+	frame := p.frames[len(p.frames)-1]
+
+	elapsed := now.Sub(frame.start) - frame.childTime
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	frame.totalGas += gas + frame.childGas
+	frame.totalTime += elapsed + frame.childTime
+
+	// Aggregate the costs per opcode and per contract code
+	if p.opcodes[frame.op] == nil {
+		p.opcodes[frame.op] = new(ProfileStat)
+	}
+	p.opcodes[frame.op].add(gas, elapsed)
+
+	if p.contracts[frame.code] == nil {
+		p.contracts[frame.code] = new(ProfileStat)
+	}
+	p.contracts[frame.code].add(gas, elapsed)
+
+	// Aggregate the costs per call stack
+	key := make([]byte, 1, 1+len(p.frames)*(common.AddressLength+8))
+	key[0] = byte(frame.op)
+	for i := len(p.frames) - 1; i >= 0; i-- {
+		key = append(key, p.frames[i].code[:]...)
+		key = append(key, make([]byte, 8)...)
+		binary.BigEndian.PutUint64(key[len(key)-8:], p.frames[i].pc)
+	}
+	sample := p.samples[string(key)]
+	if sample == nil {
+		sample = &profileSample{op: frame.op, stack: make([]profileLocation, 0, len(p.frames))}
+		for i := len(p.frames) - 1; i >= 0; i-- {
+			sample.stack = append(sample.stack, profileLocation{code: p.frames[i].code, pc: p.frames[i].pc})
+		}
+		p.samples[string(key)] = sample
+	}
+	sample.stat.add(gas, elapsed)
+}
+
+// Opcodes returns the aggregated costs per opcode, most gas consuming first.
+func (p *OpcodeProfiler) Opcodes() []OpcodeProfile {
+	profiles := make([]OpcodeProfile, 0, len(p.opcodes))
+	for op, stat := range p.opcodes {
+		profiles = append(profiles, OpcodeProfile{Op: op.String(), ProfileStat: *stat})
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		if profiles[i].Gas != profiles[j].Gas {
+			return profiles[i].Gas > profiles[j].Gas
+		}
+		return profiles[i].Op < profiles[j].Op
+	})
+	return profiles
+}
+
+// Contracts returns the aggregated costs per contract code, most gas consuming
+// first.
+func (p *OpcodeProfiler) Contracts() []ContractProfile {
+	profiles := make([]ContractProfile, 0, len(p.contracts))
+	for addr, stat := range p.contracts {
+		profiles = append(profiles, ContractProfile{Address: addr, ProfileStat: *stat})
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		if profiles[i].Gas != profiles[j].Gas {
+			return profiles[i].Gas > profiles[j].Gas
+		}
+		return bytes.Compare(profiles[i].Address[:], profiles[j].Address[:]) < 0
+	})
+	return profiles
+}
+
+// Result returns the summary of the profiled executions, including the pprof
+// profile with the per program counter costs.
+func (p *OpcodeProfiler) Result() (*ProfileResult, error) {
+	profile := new(bytes.Buffer)
+	if err := p.WriteProfile(profile); err != nil {
+		return nil, err
+	}
+	return &ProfileResult{
+		Opcodes:   p.Opcodes(),
+		Contracts: p.Contracts(),
+		Profile:   profile.Bytes(),
+	}, nil
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/ethdb"
 )
@@ -117,6 +118,156 @@ func TestCall(t *testing.T) {
 	}
 }
 
+// Tests that the opcode profiler attributes all the consumed gas to the executed
+// instructions, accounting nested calls to the callee.
+func TestOpcodeProfiler(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	state, _ := state.New(common.Hash{}, state.NewDatabase(db))
+
+	callee := common.HexToAddress("0x0b")
+	state.SetCode(callee, []byte{
+		byte(vm.PUSH1), 1,
+		byte(vm.PUSH1), 0,
+		byte(vm.SSTORE),
+		byte(vm.STOP),
+	})
+	caller := common.HexToAddress("0x0a")
+	state.SetCode(caller, []byte{
+		byte(vm.PUSH1), 0, // out size
+		byte(vm.DUP1), // out offset
+		byte(vm.DUP1), // in size
+		byte(vm.DUP1), // in offset
+		byte(vm.DUP1), // value
+		byte(vm.PUSH1), 0x0b,
+		byte(vm.GAS),
+		byte(vm.CALL),
+		byte(vm.STOP),
+	})
+	profiler := vm.NewOpcodeProfiler()
+
+	gasLimit := uint64(100000)
+	_, leftOver, err := Call(caller, nil, &Config{
+		State:     state,
+		GasLimit:  gasLimit,
+		EVMConfig: vm.Config{Debug: true, Tracer: profiler},
+	})
+	if err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	var total uint64
+	for _, stat := range profiler.Opcodes() {
+		total += stat.Gas
+	}
+	if total != gasLimit-leftOver {
+		t.Errorf("profiled gas mismatch: have %d, want %d", total, gasLimit-leftOver)
+	}
+	contracts := profiler.Contracts()
+	if len(contracts) != 2 {
+		t.Fatalf("contract count mismatch: have %d, want 2", len(contracts))
+	}
+	if contracts[0].Address != callee || contracts[0].Count != 4 {
+		t.Errorf("callee profile mismatch: have %x/%d, want %x/%d", contracts[0].Address, contracts[0].Count, callee, 4)
+	}
+	if contracts[1].Address != caller || contracts[1].Count != 9 {
+		t.Errorf("caller profile mismatch: have %x/%d, want %x/%d", contracts[1].Address, contracts[1].Count, caller, 9)
+	}
+	result, err := profiler.Result()
+	if err != nil {
+		t.Fatal("failed to assemble profile", err)
+	}
+	if len(result.Profile) == 0 {
+		t.Error("empty pprof profile")
+	}
+}
+
+// stateAccessTracer is a StateTracer recording all the reported state accesses.
+type stateAccessTracer struct {
+	*vm.StructLogger
+
+	reads    map[common.Address]bool
+	balances map[common.Address]*big.Int
+	storage  map[common.Hash]common.Hash
+	loads    int
+	logs     int
+	suicides map[common.Address]*big.Int
+	deleted  map[common.Address]bool
+}
+
+func (t *stateAccessTracer) CaptureAccountRead(addr common.Address) { t.reads[addr] = true }
+func (t *stateAccessTracer) CaptureBalanceChange(addr common.Address, prev, balance *big.Int) {
+	t.balances[addr] = balance
+}
+func (t *stateAccessTracer) CaptureNonceChange(addr common.Address, prev, nonce uint64) {}
+func (t *stateAccessTracer) CaptureCodeRead(addr common.Address)                        { t.reads[addr] = true }
+func (t *stateAccessTracer) CaptureCodeChange(addr common.Address, code []byte)         {}
+func (t *stateAccessTracer) CaptureStorageRead(addr common.Address, key, value common.Hash) {
+	t.loads++
+}
+func (t *stateAccessTracer) CaptureStorageWrite(addr common.Address, key, prev, value common.Hash) {
+	t.storage[key] = value
+}
+func (t *stateAccessTracer) CaptureLog(log *types.Log) { t.logs++ }
+func (t *stateAccessTracer) CaptureSelfDestruct(addr common.Address, balance *big.Int) {
+	t.suicides[addr] = balance
+}
+func (t *stateAccessTracer) CaptureAccountDelete(addr common.Address) { t.deleted[addr] = true }
+
+// Tests that tracers implementing StateTracer are notified of the state accesses.
+func TestStateTracer(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	state, _ := state.New(common.Hash{}, state.NewDatabase(db))
+
+	address := common.HexToAddress("0x0a")
+	state.SetBalance(address, big.NewInt(100))
+	state.SetCode(address, []byte{
+		byte(vm.PUSH1), 1,
+		byte(vm.SLOAD),
+		byte(vm.PUSH1), 1,
+		byte(vm.SSTORE),
+		byte(vm.PUSH1), 0,
+		byte(vm.DUP1),
+		byte(vm.LOG0),
+		byte(vm.PUSH1), 0x0b,
+		byte(vm.SELFDESTRUCT),
+	})
+	tracer := &stateAccessTracer{
+		StructLogger: vm.NewStructLogger(nil),
+		reads:        make(map[common.Address]bool),
+		balances:     make(map[common.Address]*big.Int),
+		storage:      make(map[common.Hash]common.Hash),
+		suicides:     make(map[common.Address]*big.Int),
+		deleted:      make(map[common.Address]bool),
+	}
+	if _, _, err := Call(address, nil, &Config{
+		State:     state,
+		EVMConfig: vm.Config{Debug: true, Tracer: tracer},
+	}); err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	beneficiary := common.HexToAddress("0x0b")
+	if !tracer.reads[address] || !tracer.reads[beneficiary] {
+		t.Errorf("account reads mismatch: have %v", tracer.reads)
+	}
+	if tracer.loads == 0 {
+		t.Errorf("storage read not reported")
+	}
+	if value := tracer.storage[common.BigToHash(big.NewInt(1))]; value != (common.Hash{}) {
+		t.Errorf("storage write mismatch: have %x, want %x", value, common.Hash{})
+	}
+	if len(tracer.storage) != 1 {
+		t.Errorf("storage write count mismatch: have %d, want 1", len(tracer.storage))
+	}
+	if tracer.logs != 1 {
+		t.Errorf("log count mismatch: have %d, want 1", tracer.logs)
+	}
+	if balance := tracer.balances[beneficiary]; balance == nil || balance.Int64() != 100 {
+		t.Errorf("beneficiary balance mismatch: have %v, want 100", balance)
+	}
+	if balance := tracer.suicides[address]; balance == nil || balance.Int64() != 100 {
+		t.Errorf("self-destruct mismatch: have %v, want 100", balance)
+	}
+}
+
 func BenchmarkCall(b *testing.B) {
 	var definition = `[{"constant":true,"inputs":[],"name":"seller","outputs":[{"name":"","type":"address"}],"type":"function"},{"constant":false,"inputs":[],"name":"abort","outputs":[],"type":"function"},{"constant":true,"inputs":[],"name":"value","outputs":[{"name":"","type":"uint256"}],"type":"function"},{"constant":false,"inputs":[],"name":"refund","outputs":[],"type":"function"},{"constant":true,"inputs":[],"name":"buyer","outputs":[{"name":"","type":"address"}],"type":"function"},{"constant":false,"inputs":[],"name":"confirmReceived","outputs":[],"type":"function"},{"constant":true,"inputs":[],"name":"state","outputs":[{"name":"","type":"uint8"}],"type":"function"},{"constant":false,"inputs":[],"name":"confirmPurchase","outputs":[],"type":"function"},{"inputs":[],"type":"constructor"},{"anonymous":false,"inputs":[],"name":"Aborted","type":"event"},{"anonymous":false,"inputs":[],"name":"PurchaseConfirmed","type":"event"},{"anonymous":false,"inputs":[],"name":"ItemReceived","type":"event"},{"anonymous":false,"inputs":[],"name":"Refunded","type":"event"}]`
 
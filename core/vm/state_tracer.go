@@ -0,0 +1,165 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// StateTracer is an optional extension of Tracer. If the configured tracer also
+// implements it, the EVM reports every state access made during the execution,
+// including the ones made by the state transition around it (e.g. gas purchase
+// and refunds). When transactions are applied through core.ApplyTransaction, the
+// accounts removed from the state while it is finalised are reported too.
+//
+// Modifications made directly on the state outside of transactions, such as the
+// block and uncle rewards credited by the consensus engine, are not reported.
+//
+// Changes are reported as they are made; a change that is later reverted is not
+// undone by any callback, so tracers interested in the final state diff need to
+// track the reverted call frames themselves.
+type StateTracer interface {
+	Tracer
+
+	// CaptureAccountRead is called when the existence, balance, nonce or code hash
+	// of an account is queried.
+	CaptureAccountRead(addr common.Address)
+
+	// CaptureBalanceChange is called when the balance of an account is modified.
+	CaptureBalanceChange(addr common.Address, prev, balance *big.Int)
+
+	// CaptureNonceChange is called when the nonce of an account is modified.
+	CaptureNonceChange(addr common.Address, prev, nonce uint64)
+
+	// CaptureCodeRead is called when the code (or code size) of an account is
+	// queried.
+	CaptureCodeRead(addr common.Address)
+
+	// CaptureCodeChange is called when the code of an account is deployed.
+	CaptureCodeChange(addr common.Address, code []byte)
+
+	// CaptureStorageRead is called when a storage slot of an account is loaded.
+	CaptureStorageRead(addr common.Address, key, value common.Hash)
+
+	// CaptureStorageWrite is called when a storage slot of an account is stored.
+	CaptureStorageWrite(addr common.Address, key, prev, value common.Hash)
+
+	// CaptureLog is called when a log is emitted.
+	CaptureLog(log *types.Log)
+
+	// CaptureSelfDestruct is called when an account is self-destructed, along
+	// with the balance it held before being cleared.
+	CaptureSelfDestruct(addr common.Address, balance *big.Int)
+
+	// CaptureAccountDelete is called by core/state when an account is removed
+	// from the state as it is finalised, either because it self-destructed or
+	// because it was touched and left empty.
+	CaptureAccountDelete(addr common.Address)
+}
+
+// tracingStateDB wraps a StateDB, reporting all the accesses to a StateTracer.
+type tracingStateDB struct {
+	StateDB
+	tracer StateTracer
+}
+
+func (db *tracingStateDB) SubBalance(addr common.Address, amount *big.Int) {
+	prev := new(big.Int).Set(db.StateDB.GetBalance(addr))
+	db.StateDB.SubBalance(addr, amount)
+	db.tracer.CaptureBalanceChange(addr, prev, new(big.Int).Set(db.StateDB.GetBalance(addr)))
+}
+
+func (db *tracingStateDB) AddBalance(addr common.Address, amount *big.Int) {
+	prev := new(big.Int).Set(db.StateDB.GetBalance(addr))
+	db.StateDB.AddBalance(addr, amount)
+	db.tracer.CaptureBalanceChange(addr, prev, new(big.Int).Set(db.StateDB.GetBalance(addr)))
+}
+
+func (db *tracingStateDB) GetBalance(addr common.Address) *big.Int {
+	db.tracer.CaptureAccountRead(addr)
+	return db.StateDB.GetBalance(addr)
+}
+
+func (db *tracingStateDB) GetNonce(addr common.Address) uint64 {
+	db.tracer.CaptureAccountRead(addr)
+	return db.StateDB.GetNonce(addr)
+}
+
+func (db *tracingStateDB) SetNonce(addr common.Address, nonce uint64) {
+	prev := db.StateDB.GetNonce(addr)
+	db.StateDB.SetNonce(addr, nonce)
+	db.tracer.CaptureNonceChange(addr, prev, nonce)
+}
+
+func (db *tracingStateDB) GetCodeHash(addr common.Address) common.Hash {
+	db.tracer.CaptureAccountRead(addr)
+	return db.StateDB.GetCodeHash(addr)
+}
+
+func (db *tracingStateDB) GetCode(addr common.Address) []byte {
+	db.tracer.CaptureCodeRead(addr)
+	return db.StateDB.GetCode(addr)
+}
+
+func (db *tracingStateDB) SetCode(addr common.Address, code []byte) {
+	db.StateDB.SetCode(addr, code)
+	db.tracer.CaptureCodeChange(addr, code)
+}
+
+func (db *tracingStateDB) GetCodeSize(addr common.Address) int {
+	db.tracer.CaptureCodeRead(addr)
+	return db.StateDB.GetCodeSize(addr)
+}
+
+func (db *tracingStateDB) GetState(addr common.Address, key common.Hash) common.Hash {
+	value := db.StateDB.GetState(addr, key)
+	db.tracer.CaptureStorageRead(addr, key, value)
+	return value
+}
+
+func (db *tracingStateDB) SetState(addr common.Address, key common.Hash, value common.Hash) {
+	prev := db.StateDB.GetState(addr, key)
+	db.StateDB.SetState(addr, key, value)
+	db.tracer.CaptureStorageWrite(addr, key, prev, value)
+}
+
+func (db *tracingStateDB) Suicide(addr common.Address) bool {
+	balance := new(big.Int).Set(db.StateDB.GetBalance(addr))
+	if !db.StateDB.Suicide(addr) {
+		return false
+	}
+	db.tracer.CaptureSelfDestruct(addr, balance)
+	return true
+}
+
+func (db *tracingStateDB) Exist(addr common.Address) bool {
+	db.tracer.CaptureAccountRead(addr)
+	return db.StateDB.Exist(addr)
+}
+
+func (db *tracingStateDB) Empty(addr common.Address) bool {
+	db.tracer.CaptureAccountRead(addr)
+	return db.StateDB.Empty(addr)
+}
+
+func (db *tracingStateDB) AddLog(log *types.Log) {
+	db.StateDB.AddLog(log)
+	db.tracer.CaptureLog(log)
+}
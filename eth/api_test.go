@@ -31,16 +31,16 @@ var dumper = spew.ConfigState{Indent: "    "}
 func TestStorageRangeAt(t *testing.T) {
 	// Create a state where account 0x010000... has a few storage entries.
 	var (
-		db, _    = ethdb.NewMemDatabase()
-		state, _ = state.New(common.Hash{}, state.NewDatabase(db))
-		addr     = common.Address{0x01}
-		keys     = []common.Hash{ // hashes of Keys of storage
+		db, _      = ethdb.NewMemDatabase()
+		statedb, _ = state.New(common.Hash{}, state.NewDatabase(db))
+		addr       = common.Address{0x01}
+		keys       = []common.Hash{ // hashes of Keys of storage
 			common.HexToHash("340dd630ad21bf010b4e676dbfa9ba9a02175262d1fa356232cfde6cb5b47ef2"),
 			common.HexToHash("426fcb404ab2d5d8e61a3d918108006bbb0a9be65e92235bb10eefbdb6dcd053"),
 			common.HexToHash("48078cfed56339ea54962e72c37c7f588fc4f8e5bc173827ba75cb10a63a96a5"),
 			common.HexToHash("5723d2c3a83af9b735e3b7f21531e5623d183a9095a56604ead41f3582fdfb75"),
 		}
-		storage = storageMap{
+		storage = state.StorageMap{
 			keys[0]: {Key: &common.Hash{0x02}, Value: common.Hash{0x01}},
 			keys[1]: {Key: &common.Hash{0x04}, Value: common.Hash{0x02}},
 			keys[2]: {Key: &common.Hash{0x01}, Value: common.Hash{0x03}},
@@ -48,38 +48,38 @@ func TestStorageRangeAt(t *testing.T) {
 		}
 	)
 	for _, entry := range storage {
-		state.SetState(addr, *entry.Key, entry.Value)
+		statedb.SetState(addr, *entry.Key, entry.Value)
 	}
 
 	// Check a few combinations of limit and start/end.
 	tests := []struct {
 		start []byte
 		limit int
-		want  StorageRangeResult
+		want  state.StorageRangeResult
 	}{
 		{
 			start: []byte{}, limit: 0,
-			want: StorageRangeResult{storageMap{}, &keys[0]},
+			want: state.StorageRangeResult{Storage: state.StorageMap{}, NextKey: &keys[0]},
 		},
 		{
 			start: []byte{}, limit: 100,
-			want: StorageRangeResult{storage, nil},
+			want: state.StorageRangeResult{Storage: storage},
 		},
 		{
 			start: []byte{}, limit: 2,
-			want: StorageRangeResult{storageMap{keys[0]: storage[keys[0]], keys[1]: storage[keys[1]]}, &keys[2]},
+			want: state.StorageRangeResult{Storage: state.StorageMap{keys[0]: storage[keys[0]], keys[1]: storage[keys[1]]}, NextKey: &keys[2]},
 		},
 		{
 			start: []byte{0x00}, limit: 4,
-			want: StorageRangeResult{storage, nil},
+			want: state.StorageRangeResult{Storage: storage},
 		},
 		{
 			start: []byte{0x40}, limit: 2,
-			want: StorageRangeResult{storageMap{keys[1]: storage[keys[1]], keys[2]: storage[keys[2]]}, &keys[3]},
+			want: state.StorageRangeResult{Storage: state.StorageMap{keys[1]: storage[keys[1]], keys[2]: storage[keys[2]]}, NextKey: &keys[3]},
 		},
 	}
 	for _, test := range tests {
-		result, err := storageRangeAt(state.StorageTrie(addr), test.start, test.limit)
+		result, err := storageRangeAt(statedb.StorageTrie(addr), test.start, test.limit)
 		if err != nil {
 			t.Error(err)
 		}
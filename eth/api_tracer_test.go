@@ -0,0 +1,207 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/miner"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	traceKey, _  = crypto.GenerateKey()
+	traceAddr    = crypto.PubkeyToAddress(traceKey.PublicKey)
+	traceCounter = common.Address{0xc0}
+
+	// traceCounterCode increments storage slot 0 and returns its new value.
+	traceCounterCode = []byte{
+		byte(vm.PUSH1), 0, byte(vm.SLOAD), byte(vm.PUSH1), 1, byte(vm.ADD), byte(vm.DUP1),
+		byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.PUSH1), 0, byte(vm.MSTORE),
+		byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN),
+	}
+)
+
+// newTraceTestBackend creates an archive chain of the given number of blocks,
+// each incrementing the counter contract once, along with a transaction pool
+// and a miner maintaining the pending block. The state of the missing block is
+// dropped from the database.
+func newTraceTestBackend(t *testing.T, blocks int, missing uint64) *Ethereum {
+	var (
+		db, _  = ethdb.NewMemDatabase()
+		config = params.TestChainConfig
+		engine = ethash.NewFaker()
+		signer = types.NewEIP155Signer(config.ChainId)
+		gspec  = &core.Genesis{
+			Config: config,
+			Alloc: core.GenesisAlloc{
+				traceAddr:    {Balance: big.NewInt(params.Ether)},
+				traceCounter: {Balance: new(big.Int), Code: traceCounterCode},
+			},
+		}
+		genesis = gspec.MustCommit(db)
+	)
+	chain, _ := core.GenerateChain(config, genesis, engine, db, blocks, func(i int, block *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(uint64(i), traceCounter, new(big.Int), 100000, big.NewInt(1), nil), signer, traceKey)
+		block.AddTx(tx)
+	})
+	blockchain, err := core.NewBlockChain(db, &core.CacheConfig{Disabled: true}, config, engine, vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create blockchain: %v", err)
+	}
+	if _, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatalf("failed to import chain: %v", err)
+	}
+	blockchain.Stop()
+
+	// Reopen the chain after dropping the state, so it isn't cached either
+	if err := db.Delete(chain[missing-1].Root().Bytes()); err != nil {
+		t.Fatalf("failed to delete state: %v", err)
+	}
+	if blockchain, err = core.NewBlockChain(db, &core.CacheConfig{Disabled: true}, config, engine, vm.Config{}); err != nil {
+		t.Fatalf("failed to reopen blockchain: %v", err)
+	}
+	poolConfig := core.DefaultTxPoolConfig
+	poolConfig.Journal = ""
+
+	eth := &Ethereum{
+		chainConfig:    config,
+		chainDb:        db,
+		blockchain:     blockchain,
+		txPool:         core.NewTxPool(poolConfig, config, blockchain),
+		engine:         engine,
+		eventMux:       new(event.TypeMux),
+		accountManager: accounts.NewManager(),
+	}
+	eth.miner = miner.New(eth, config, eth.eventMux, engine, nil)
+	return eth
+}
+
+// traceCount extracts the counter value returned by a traced call.
+func traceCount(t *testing.T, result interface{}) uint64 {
+	var output string
+	switch result := result.(type) {
+	case *vm.ExecutionResult:
+		output = result.ReturnValue
+	case json.RawMessage:
+		var call struct {
+			Output string `json:"output"`
+		}
+		if err := json.Unmarshal(result, &call); err != nil {
+			t.Fatalf("failed to decode tracer output %s: %v", result, err)
+		}
+		output = call.Output
+	default:
+		t.Fatalf("unexpected trace result type %T", result)
+	}
+	return new(big.Int).SetBytes(common.FromHex(output)).Uint64()
+}
+
+// Tests that calls are traced on top of the state of the requested block, be it
+// the pending one, the head, or a historical one whose state needs to be
+// regenerated, with both the default struct logger and named tracers.
+func TestTraceCall(t *testing.T) {
+	eth := newTraceTestBackend(t, 4, 3)
+	defer eth.blockchain.Stop()
+	defer eth.txPool.Stop()
+	defer eth.miner.Stop()
+
+	// Add a counter call to the pool and wait for the pending block to include it
+	tx, _ := types.SignTx(types.NewTransaction(4, traceCounter, new(big.Int), 100000, big.NewInt(1), nil), types.NewEIP155Signer(params.TestChainConfig.ChainId), traceKey)
+	if err := eth.txPool.AddLocal(tx); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		if block, _ := eth.miner.Pending(); block.Transactions().Len() == 1 {
+			break
+		}
+		if time.Since(start) > 5*time.Second {
+			t.Fatalf("pending block not updated")
+		}
+	}
+	var (
+		callTracer = "callTracer"
+		noReexec   = uint64(0)
+	)
+	tests := []struct {
+		number rpc.BlockNumber
+		config *vm.TraceConfig
+		count  uint64 // Counter value returned by the call, zero if failing
+	}{
+		{number: rpc.PendingBlockNumber, count: 6},
+		{number: rpc.LatestBlockNumber, count: 5},
+		{number: 2, count: 3},
+		{number: 2, config: &vm.TraceConfig{Tracer: &callTracer}, count: 3},
+		{number: 3, count: 4}, // State regenerated from the second block
+		{number: 3, config: &vm.TraceConfig{Tracer: &callTracer}, count: 4},
+		{number: 3, config: &vm.TraceConfig{Reexec: &noReexec}},
+		{number: 5},
+	}
+	api := NewPrivateDebugAPI(params.TestChainConfig, eth)
+	args := ethapi.CallArgs{From: common.Address{0xaa}, To: &traceCounter}
+
+	for i, tt := range tests {
+		result, err := api.TraceCall(context.Background(), args, tt.number, tt.config, nil)
+		if tt.count == 0 {
+			if err == nil {
+				t.Errorf("test %d: trace succeeded without state", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("test %d: failed to trace call: %v", i, err)
+			continue
+		}
+		if have := traceCount(t, result); have != tt.count {
+			t.Errorf("test %d: counter mismatch: have %d, want %d", i, have, tt.count)
+		}
+		if logs, ok := result.(*vm.ExecutionResult); ok && len(logs.StructLogs) != 12 {
+			t.Errorf("test %d: struct log count mismatch: have %d, want 12", i, len(logs.StructLogs))
+		}
+	}
+	// Ensure the sender is funded like in eth_call: unlimited, unless overridden
+	args.Value = hexutil.Big(*big.NewInt(params.Ether))
+	if _, err := api.TraceCall(context.Background(), args, rpc.LatestBlockNumber, nil, nil); err != nil {
+		t.Errorf("failed to trace value transfer from unfunded sender: %v", err)
+	}
+	overrides := &ethapi.StateOverride{args.From: ethapi.OverrideAccount{Balance: (*hexutil.Big)(big.NewInt(params.Ether - 1))}}
+	if _, err := api.TraceCall(context.Background(), args, rpc.LatestBlockNumber, nil, overrides); err == nil {
+		t.Errorf("traced value transfer exceeding overridden balance")
+	}
+	overrides = &ethapi.StateOverride{args.From: ethapi.OverrideAccount{Balance: (*hexutil.Big)(big.NewInt(params.Ether))}}
+	if result, err := api.TraceCall(context.Background(), args, rpc.LatestBlockNumber, nil, overrides); err != nil {
+		t.Errorf("failed to trace value transfer within overridden balance: %v", err)
+	} else if have := traceCount(t, result); have != 5 {
+		t.Errorf("counter mismatch with overrides: have %d, want 5", have)
+	}
+}
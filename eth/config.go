@@ -100,6 +100,10 @@ type Config struct {
 	ExtraData    []byte         `toml:",omitempty"`
 	GasPrice     *big.Int
 
+	// Transaction ordering of the mined blocks (price, fifo, fair or priority)
+	MinerOrdering        string           `toml:",omitempty"`
+	MinerPrioritySenders []common.Address `toml:",omitempty"`
+
 	// Ethash options
 	Ethash ethash.Config
 
@@ -58,7 +58,24 @@ type stateSyncStats struct {
 
 // syncState starts downloading state with the given root hash.
 func (d *Downloader) syncState(root common.Hash) *stateSync {
-	s := newStateSync(d, root)
+	return d.startStateSync(newStateSync(d, state.NewStateSync(root, d.stateDB)))
+}
+
+// RepairState retrieves the state entries scheduled by the given trie sync from
+// the connected peers, blocking until all of them are downloaded. The repair is
+// aborted if a chain synchronisation starts a state sync of its own.
+func (d *Downloader) RepairState(sched *trie.TrieSync) error {
+	if d.Synchronising() {
+		return errBusy
+	}
+	s := newStateSync(d, sched)
+	s.repair = true
+
+	return d.startStateSync(s).Wait()
+}
+
+// startStateSync hands a state sync over to the state fetcher to run.
+func (d *Downloader) startStateSync(s *stateSync) *stateSync {
 	select {
 	case d.stateSyncStart <- s:
 	case <-d.quitCh:
@@ -216,6 +233,7 @@ type stateSync struct {
 	sched  *trie.TrieSync             // State trie sync scheduler defining the tasks
 	keccak hash.Hash                  // Keccak256 hasher to verify deliveries with
 	tasks  map[common.Hash]*stateTask // Set of tasks currently queued for retrieval
+	repair bool                       // Whether the sync is a repair detached from chain syncs
 
 	numUncommitted   int
 	bytesUncommitted int
@@ -235,10 +253,10 @@ type stateTask struct {
 
 // newStateSync creates a new state trie download scheduler. This method does not
 // yet start the sync. The user needs to call run to initiate.
-func newStateSync(d *Downloader, root common.Hash) *stateSync {
+func newStateSync(d *Downloader, sched *trie.TrieSync) *stateSync {
 	return &stateSync{
 		d:       d,
-		sched:   state.NewStateSync(root, d.stateDB),
+		sched:   sched,
 		keccak:  sha3.NewKeccak256(),
 		tasks:   make(map[common.Hash]*stateTask),
 		deliver: make(chan *stateReq),
@@ -279,6 +297,11 @@ func (s *stateSync) loop() error {
 	peerSub := s.d.peers.SubscribeNewPeers(newPeer)
 	defer peerSub.Unsubscribe()
 
+	// Repairs are not tied to any chain sync, don't abort on their cancellation
+	chainCancel := s.d.cancelCh
+	if s.repair {
+		chainCancel = nil
+	}
 	// Keep assigning new tasks until the sync completes or aborts
 	for s.sched.Pending() > 0 {
 		if err := s.commit(false); err != nil {
@@ -293,7 +316,7 @@ func (s *stateSync) loop() error {
 		case <-s.cancel:
 			return errCancelStateFetch
 
-		case <-s.d.cancelCh:
+		case <-chainCancel:
 			return errCancelStateFetch
 
 		case req := <-s.deliver:
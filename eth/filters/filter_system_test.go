@@ -45,6 +45,7 @@ type testBackend struct {
 	rmLogsFeed *event.Feed
 	logsFeed   *event.Feed
 	chainFeed  *event.Feed
+	dropFeed   *event.Feed
 }
 
 func (b *testBackend) ChainDb() ethdb.Database {
@@ -77,6 +78,10 @@ func (b *testBackend) SubscribeTxPreEvent(ch chan<- core.TxPreEvent) event.Subsc
 	return b.txFeed.Subscribe(ch)
 }
 
+func (b *testBackend) SubscribeTxDropEvent(ch chan<- core.TxDropEvent) event.Subscription {
+	return b.dropFeed.Subscribe(ch)
+}
+
 func (b *testBackend) SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription {
 	return b.rmLogsFeed.Subscribe(ch)
 }
@@ -135,7 +140,7 @@ func TestBlockSubscription(t *testing.T) {
 		rmLogsFeed  = new(event.Feed)
 		logsFeed    = new(event.Feed)
 		chainFeed   = new(event.Feed)
-		backend     = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		backend     = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, new(event.Feed)}
 		api         = NewPublicFilterAPI(backend, false)
 		genesis     = new(core.Genesis).MustCommit(db)
 		chain, _    = core.GenerateChain(params.TestChainConfig, genesis, ethash.NewFaker(), db, 10, func(i int, gen *core.BlockGen) {})
@@ -192,7 +197,7 @@ func TestPendingTxFilter(t *testing.T) {
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, new(event.Feed)}
 		api        = NewPublicFilterAPI(backend, false)
 
 		transactions = []*types.Transaction{
@@ -245,6 +250,85 @@ func TestPendingTxFilter(t *testing.T) {
 	}
 }
 
+// TestPendingTxSubscription tests whether full pending transaction subscriptions
+// receive the entire transactions entering the pool.
+func TestPendingTxSubscription(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mux     = new(event.TypeMux)
+		db, _   = ethdb.NewMemDatabase()
+		txFeed  = new(event.Feed)
+		backend = &testBackend{mux, db, 0, txFeed, new(event.Feed), new(event.Feed), new(event.Feed), new(event.Feed)}
+		api     = NewPublicFilterAPI(backend, false)
+
+		transactions = []*types.Transaction{
+			types.NewTransaction(0, common.HexToAddress("0xb794f5ea0ba39494ce83a213fffba74279579268"), new(big.Int), 0, new(big.Int), nil),
+			types.NewTransaction(1, common.HexToAddress("0xb794f5ea0ba39494ce83a213fffba74279579268"), new(big.Int), 0, new(big.Int), nil),
+		}
+	)
+	txs := make(chan *types.Transaction)
+	sub := api.events.SubscribePendingTxs(txs)
+	defer sub.Unsubscribe()
+
+	go func() {
+		for _, tx := range transactions {
+			txFeed.Send(core.TxPreEvent{Tx: tx})
+		}
+	}()
+	for i, want := range transactions {
+		select {
+		case have := <-txs:
+			if have != want {
+				t.Errorf("tx %d: transaction mismatch: have %x, want %x", i, have.Hash(), want.Hash())
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("tx %d: transaction not received", i)
+		}
+	}
+}
+
+// TestDroppedTxSubscription tests whether dropped transaction subscriptions
+// receive all the transactions dropped from the pool.
+func TestDroppedTxSubscription(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mux      = new(event.TypeMux)
+		db, _    = ethdb.NewMemDatabase()
+		dropFeed = new(event.Feed)
+		backend  = &testBackend{mux, db, 0, new(event.Feed), new(event.Feed), new(event.Feed), new(event.Feed), dropFeed}
+		api      = NewPublicFilterAPI(backend, false)
+
+		replaced    = types.NewTransaction(0, common.Address{}, new(big.Int), 0, big.NewInt(1), nil)
+		replacement = types.NewTransaction(0, common.Address{}, new(big.Int), 0, big.NewInt(2), nil)
+		stale       = types.NewTransaction(1, common.Address{}, new(big.Int), 0, big.NewInt(1), nil)
+	)
+	drops := make(chan core.TxDropEvent)
+	sub := api.events.SubscribeDroppedTxEvents(drops)
+	defer sub.Unsubscribe()
+
+	sent := []core.TxDropEvent{
+		{Tx: replaced, Reason: core.TxDropReplaced, Replacement: replacement.Hash()},
+		{Tx: stale, Reason: core.TxDropStale},
+	}
+	go func() {
+		for _, ev := range sent {
+			dropFeed.Send(ev)
+		}
+	}()
+	for i, want := range sent {
+		select {
+		case have := <-drops:
+			if have.Tx.Hash() != want.Tx.Hash() || have.Reason != want.Reason || have.Replacement != want.Replacement {
+				t.Errorf("drop %d: event mismatch: have %x/%v/%x, want %x/%v/%x", i, have.Tx.Hash(), have.Reason, have.Replacement, want.Tx.Hash(), want.Reason, want.Replacement)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("drop %d: event not received", i)
+		}
+	}
+}
+
 // TestLogFilterCreation test whether a given filter criteria makes sense.
 // If not it must return an error.
 func TestLogFilterCreation(t *testing.T) {
@@ -255,7 +339,7 @@ func TestLogFilterCreation(t *testing.T) {
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, new(event.Feed)}
 		api        = NewPublicFilterAPI(backend, false)
 
 		testCases = []struct {
@@ -304,7 +388,7 @@ func TestInvalidLogFilterCreation(t *testing.T) {
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, new(event.Feed)}
 		api        = NewPublicFilterAPI(backend, false)
 	)
 
@@ -334,7 +418,7 @@ func TestLogFilter(t *testing.T) {
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, new(event.Feed)}
 		api        = NewPublicFilterAPI(backend, false)
 
 		firstAddr      = common.HexToAddress("0x1111111111111111111111111111111111111111")
@@ -453,7 +537,7 @@ func TestPendingLogsSubscription(t *testing.T) {
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, new(event.Feed)}
 		api        = NewPublicFilterAPI(backend, false)
 
 		firstAddr      = common.HexToAddress("0x1111111111111111111111111111111111111111")
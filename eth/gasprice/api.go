@@ -0,0 +1,54 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package gasprice
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// PublicGasPriceAPI offers gas price statistics and confirmation time estimates
+// based on the recent blocks and the transaction pool.
+type PublicGasPriceAPI struct {
+	gpo *Oracle
+}
+
+// NewPublicGasPriceAPI creates a new gas price API backed by an oracle.
+func NewPublicGasPriceAPI(gpo *Oracle) *PublicGasPriceAPI {
+	return &PublicGasPriceAPI{gpo: gpo}
+}
+
+// GasPriceHistogram returns the distribution of the gas prices across the given
+// number of recent blocks and the pending transaction pool.
+func (api *PublicGasPriceAPI) GasPriceHistogram(ctx context.Context, blocks *hexutil.Uint) (*PriceHistogram, error) {
+	var n int
+	if blocks != nil {
+		n = int(*blocks)
+	}
+	return api.gpo.Histogram(ctx, n)
+}
+
+// FeeEstimate returns several gas price levels along with the estimated number
+// of blocks until a transaction paying them is included.
+func (api *PublicGasPriceAPI) FeeEstimate(ctx context.Context, blocks *hexutil.Uint) (*FeeEstimate, error) {
+	var n int
+	if blocks != nil {
+		n = int(*blocks)
+	}
+	return api.gpo.FeeEstimate(ctx, n)
+}
@@ -104,7 +104,7 @@ func (gpo *Oracle) SuggestPrice(ctx context.Context) (*big.Int, error) {
 	exp := 0
 	var blockPrices []*big.Int
 	for sent < gpo.checkBlocks && blockNum > 0 {
-		go gpo.getBlockPrices(ctx, types.MakeSigner(gpo.backend.ChainConfig(), big.NewInt(int64(blockNum))), blockNum, ch)
+		go gpo.getBlockPrices(ctx, types.MakeSigner(gpo.backend.ChainConfig(), big.NewInt(int64(blockNum))), blockNum, false, ch)
 		sent++
 		exp++
 		blockNum--
@@ -125,7 +125,7 @@ func (gpo *Oracle) SuggestPrice(ctx context.Context) (*big.Int, error) {
 			continue
 		}
 		if blockNum > 0 && sent < gpo.maxBlocks {
-			go gpo.getBlockPrices(ctx, types.MakeSigner(gpo.backend.ChainConfig(), big.NewInt(int64(blockNum))), blockNum, ch)
+			go gpo.getBlockPrices(ctx, types.MakeSigner(gpo.backend.ChainConfig(), big.NewInt(int64(blockNum))), blockNum, false, ch)
 			sent++
 			exp++
 			blockNum--
@@ -148,8 +148,10 @@ func (gpo *Oracle) SuggestPrice(ctx context.Context) (*big.Int, error) {
 }
 
 type getBlockPricesResult struct {
-	price *big.Int
-	err   error
+	number uint64
+	price  *big.Int   // Lowest price in the block, nil if empty
+	prices []*big.Int // All prices in the block in ascending order, if requested
+	err    error
 }
 
 type transactionsByGasPrice []*types.Transaction
@@ -158,12 +160,14 @@ func (t transactionsByGasPrice) Len() int           { return len(t) }
 func (t transactionsByGasPrice) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
 func (t transactionsByGasPrice) Less(i, j int) bool { return t[i].GasPrice().Cmp(t[j].GasPrice()) < 0 }
 
-// getBlockPrices calculates the lowest transaction gas price in a given block
-// and sends it to the result channel. If the block is empty, price is nil.
-func (gpo *Oracle) getBlockPrices(ctx context.Context, signer types.Signer, blockNum uint64, ch chan getBlockPricesResult) {
+// getBlockPrices calculates the lowest transaction gas price in a given block,
+// not counting the ones sent by the miner, and sends it to the result channel.
+// If the block is empty, price is nil. If all is set, every price of the block
+// is collected too, otherwise the senders are only recovered up to the lowest.
+func (gpo *Oracle) getBlockPrices(ctx context.Context, signer types.Signer, blockNum uint64, all bool, ch chan getBlockPricesResult) {
 	block, err := gpo.backend.BlockByNumber(ctx, rpc.BlockNumber(blockNum))
 	if block == nil {
-		ch <- getBlockPricesResult{nil, err}
+		ch <- getBlockPricesResult{number: blockNum, err: err}
 		return
 	}
 
@@ -172,14 +176,21 @@ func (gpo *Oracle) getBlockPrices(ctx context.Context, signer types.Signer, bloc
 	copy(txs, blockTxs)
 	sort.Sort(transactionsByGasPrice(txs))
 
+	res := getBlockPricesResult{number: blockNum}
 	for _, tx := range txs {
 		sender, err := types.Sender(signer, tx)
-		if err == nil && sender != block.Coinbase() {
-			ch <- getBlockPricesResult{tx.GasPrice(), nil}
-			return
+		if err != nil || sender == block.Coinbase() {
+			continue
+		}
+		if res.price == nil {
+			res.price = tx.GasPrice()
+		}
+		if !all {
+			break
 		}
+		res.prices = append(res.prices, tx.GasPrice())
 	}
-	ch <- getBlockPricesResult{nil, nil}
+	ch <- res
 }
 
 type bigIntArray []*big.Int
@@ -0,0 +1,271 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package gasprice
+
+import (
+	"context"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// maxHistogramBlocks is the maximum number of blocks sampled for a histogram or
+// a fee estimate.
+const maxHistogramBlocks = 1024
+
+// histogramPercentiles are the percentiles reported for each price distribution,
+// also used as the price levels of the fee estimates.
+var histogramPercentiles = []int{10, 25, 50, 75, 90}
+
+// histogramBuckets are the lower bounds of the histogram buckets, in gwei.
+var histogramBuckets = []int64{0, 1, 2, 5, 10, 20, 50, 100, 200, 500}
+
+// PriceBucket is the number of transactions paying at least a gas price, but
+// less than the minimum of the next bucket.
+type PriceBucket struct {
+	Min   *hexutil.Big `json:"min"`
+	Count hexutil.Uint `json:"count"`
+}
+
+// PriceDistribution describes a set of gas prices by its percentiles and by a
+// histogram of fixed price buckets.
+type PriceDistribution struct {
+	Count       hexutil.Uint   `json:"count"`
+	Percentiles []*hexutil.Big `json:"percentiles"` // Price at each of the histogram percentiles, nil if empty
+	Buckets     []PriceBucket  `json:"buckets"`
+}
+
+// PriceHistogram is the distribution of gas prices across a range of recent
+// blocks and the transaction pool. Transactions sent by the miner of a block are
+// not counted.
+type PriceHistogram struct {
+	OldestBlock hexutil.Uint64    `json:"oldestBlock"`
+	NewestBlock hexutil.Uint64    `json:"newestBlock"`
+	Percentiles []int             `json:"percentiles"`
+	Included    PriceDistribution `json:"included"` // Prices of all transactions in the sampled blocks
+	Minimum     PriceDistribution `json:"minimum"`  // Lowest price of each non-empty sampled block
+	Pending     PriceDistribution `json:"pending"`  // Prices of the pending pool transactions
+}
+
+// FeeLevel is a gas price along with the estimated number of blocks until a
+// transaction paying it is included in the chain.
+type FeeLevel struct {
+	Percentile int             `json:"percentile"`
+	Price      *hexutil.Big    `json:"price"`
+	Blocks     *hexutil.Uint64 `json:"blocks"` // Nil if the price is not expected to be included
+}
+
+// FeeEstimate is a set of gas price levels with their confirmation estimates,
+// calculated on top of a specific head block.
+type FeeEstimate struct {
+	Block  hexutil.Uint64 `json:"block"`
+	Levels []FeeLevel     `json:"levels"`
+}
+
+// Histogram returns the distribution of the gas prices in the given number of
+// most recent blocks and in the transaction pool. If blocks is zero, the sample
+// size of the oracle is used.
+func (gpo *Oracle) Histogram(ctx context.Context, blocks int) (*PriceHistogram, error) {
+	head, samples, err := gpo.sampleBlocks(ctx, blocks)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := gpo.backend.GetPoolTransactions()
+	if err != nil {
+		return nil, err
+	}
+	var included, minimum, pool []*big.Int
+	for _, sample := range samples {
+		included = append(included, sample.prices...)
+		if sample.price != nil {
+			minimum = append(minimum, sample.price)
+		}
+	}
+	for _, tx := range pending {
+		pool = append(pool, tx.GasPrice())
+	}
+	histogram := &PriceHistogram{
+		OldestBlock: hexutil.Uint64(head.Number.Uint64()),
+		NewestBlock: hexutil.Uint64(head.Number.Uint64()),
+		Percentiles: histogramPercentiles,
+		Included:    newPriceDistribution(included),
+		Minimum:     newPriceDistribution(minimum),
+		Pending:     newPriceDistribution(pool),
+	}
+	if len(samples) > 0 {
+		histogram.OldestBlock = hexutil.Uint64(samples[0].number)
+	}
+	return histogram, nil
+}
+
+// FeeEstimate returns the gas prices at the histogram percentiles of the lowest
+// prices in the given number of most recent blocks, along with the estimated
+// number of blocks until a transaction paying them gets included. If blocks is
+// zero, the sample size of the oracle is used.
+//
+// Every block is assumed to include a transaction paying a price with the same
+// probability as the ratio of sampled blocks whose lowest price was not above it,
+// after all the pending transactions paying more have been mined.
+func (gpo *Oracle) FeeEstimate(ctx context.Context, blocks int) (*FeeEstimate, error) {
+	head, samples, err := gpo.sampleBlocks(ctx, blocks)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := gpo.backend.GetPoolTransactions()
+	if err != nil {
+		return nil, err
+	}
+	var minimum []*big.Int
+	for _, sample := range samples {
+		if sample.price != nil {
+			minimum = append(minimum, sample.price)
+		}
+	}
+	sort.Sort(bigIntArray(minimum))
+
+	// If there were no transactions to sample, fall back to the suggested price
+	var suggested *big.Int
+	if len(minimum) == 0 {
+		if suggested, err = gpo.SuggestPrice(ctx); err != nil {
+			return nil, err
+		}
+	}
+	estimate := &FeeEstimate{
+		Block:  hexutil.Uint64(head.Number.Uint64()),
+		Levels: make([]FeeLevel, len(histogramPercentiles)),
+	}
+	for i, percentile := range histogramPercentiles {
+		price := suggested
+		if len(minimum) > 0 {
+			price = minimum[(len(minimum)-1)*percentile/100]
+		}
+		if price.Cmp(maxPrice) > 0 {
+			price = maxPrice
+		}
+		estimate.Levels[i] = FeeLevel{
+			Percentile: percentile,
+			Price:      (*hexutil.Big)(new(big.Int).Set(price)),
+			Blocks:     estimateInclusion(price, samples, pending, head.GasLimit),
+		}
+	}
+	return estimate, nil
+}
+
+// sampleBlocks retrieves the gas prices of the given number of most recent
+// blocks, ordered from oldest to newest. The genesis block is never sampled.
+func (gpo *Oracle) sampleBlocks(ctx context.Context, blocks int) (*types.Header, []getBlockPricesResult, error) {
+	head, err := gpo.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if head == nil {
+		return nil, nil, err
+	}
+	if blocks <= 0 {
+		blocks = gpo.checkBlocks
+	}
+	if blocks > maxHistogramBlocks {
+		blocks = maxHistogramBlocks
+	}
+	number := head.Number.Uint64()
+	if uint64(blocks) > number {
+		blocks = int(number)
+	}
+	// Keep at most as many retrievals in flight as SuggestPrice does, starting a
+	// new one whenever a result arrives
+	ch := make(chan getBlockPricesResult, blocks)
+	sent := 0
+	fetch := func() {
+		blockNum := number - uint64(sent)
+		go gpo.getBlockPrices(ctx, types.MakeSigner(gpo.backend.ChainConfig(), new(big.Int).SetUint64(blockNum)), blockNum, true, ch)
+		sent++
+	}
+	for sent < blocks && sent < gpo.checkBlocks {
+		fetch()
+	}
+	samples := make([]getBlockPricesResult, 0, blocks)
+	for len(samples) < sent {
+		res := <-ch
+		if res.err != nil {
+			return nil, nil, res.err
+		}
+		samples = append(samples, res)
+		if sent < blocks {
+			fetch()
+		}
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].number < samples[j].number })
+	return head, samples, nil
+}
+
+// newPriceDistribution calculates the percentiles and the histogram of a set of
+// gas prices.
+func newPriceDistribution(prices []*big.Int) PriceDistribution {
+	sorted := make([]*big.Int, len(prices))
+	copy(sorted, prices)
+	sort.Sort(bigIntArray(sorted))
+
+	dist := PriceDistribution{
+		Count:       hexutil.Uint(len(sorted)),
+		Percentiles: make([]*hexutil.Big, len(histogramPercentiles)),
+		Buckets:     make([]PriceBucket, len(histogramBuckets)),
+	}
+	for i, gwei := range histogramBuckets {
+		dist.Buckets[i].Min = (*hexutil.Big)(new(big.Int).Mul(big.NewInt(gwei), big.NewInt(params.Shannon)))
+	}
+	if len(sorted) == 0 {
+		return dist
+	}
+	for i, percentile := range histogramPercentiles {
+		dist.Percentiles[i] = (*hexutil.Big)(sorted[(len(sorted)-1)*percentile/100])
+	}
+	for _, price := range sorted {
+		bucket := sort.Search(len(dist.Buckets), func(i int) bool {
+			return dist.Buckets[i].Min.ToInt().Cmp(price) > 0
+		}) - 1
+		dist.Buckets[bucket].Count++
+	}
+	return dist
+}
+
+// estimateInclusion estimates the number of blocks until a transaction paying
+// the given price is included, based on the ratio of sampled blocks accepting it
+// and on the gas of the pending transactions paying more. Empty blocks are deemed
+// to accept any price. Nil is returned if none of the blocks accepted it.
+func estimateInclusion(price *big.Int, samples []getBlockPricesResult, pending types.Transactions, gasLimit uint64) *hexutil.Uint64 {
+	accepted := uint64(0)
+	for _, sample := range samples {
+		if sample.price == nil || sample.price.Cmp(price) <= 0 {
+			accepted++
+		}
+	}
+	if accepted == 0 {
+		return nil
+	}
+	var ahead uint64
+	for _, tx := range pending {
+		if tx.GasPrice().Cmp(price) > 0 {
+			ahead += tx.Gas()
+		}
+	}
+	blocks := (uint64(len(samples)) + accepted - 1) / accepted
+	if gasLimit > 0 {
+		blocks += ahead / gasLimit
+	}
+	return (*hexutil.Uint64)(&blocks)
+}
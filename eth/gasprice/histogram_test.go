@@ -0,0 +1,274 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package gasprice
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// testBackend is an ethapi.Backend serving a fixed chain and transaction pool,
+// panicking on every method not needed by the oracle.
+type testBackend struct {
+	ethapi.Backend
+
+	blocks []*types.Block // Chain indexed by block number, nil entries are missing
+	pool   types.Transactions
+
+	lock     sync.Mutex
+	inflight int // Number of block retrievals currently running
+	peak     int // Highest number of concurrent block retrievals
+}
+
+func (b *testBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	if number == rpc.LatestBlockNumber {
+		number = rpc.BlockNumber(len(b.blocks) - 1)
+	}
+	return b.blocks[number].Header(), nil
+}
+
+func (b *testBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Block, error) {
+	b.lock.Lock()
+	if b.inflight++; b.inflight > b.peak {
+		b.peak = b.inflight
+	}
+	b.lock.Unlock()
+
+	time.Sleep(time.Millisecond) // Give other retrievals a chance to overlap
+
+	b.lock.Lock()
+	b.inflight--
+	b.lock.Unlock()
+
+	if block := b.blocks[number]; block != nil {
+		return block, nil
+	}
+	return nil, errors.New("block not found")
+}
+
+func (b *testBackend) GetPoolTransactions() (types.Transactions, error) {
+	return b.pool, nil
+}
+
+func (b *testBackend) ChainConfig() *params.ChainConfig {
+	return params.TestChainConfig
+}
+
+var (
+	testKey, _   = crypto.GenerateKey()
+	minerKey, _  = crypto.GenerateKey()
+	testCoinbase = crypto.PubkeyToAddress(minerKey.PublicKey)
+	testSigner   = types.NewEIP155Signer(params.TestChainConfig.ChainId)
+)
+
+// gwei returns the given amount of gwei in wei.
+func gwei(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), big.NewInt(params.Shannon))
+}
+
+// pricedTx creates a transaction signed by key paying the given gas price.
+func pricedTx(nonce uint64, price *big.Int, key *ecdsa.PrivateKey) *types.Transaction {
+	tx, _ := types.SignTx(types.NewTransaction(nonce, common.Address{}, new(big.Int), 21000, price, nil), testSigner, key)
+	return tx
+}
+
+// newTestBackend creates a backend with a chain of four blocks on top of the
+// genesis, including transactions paying 1 and 10, 2 and 20, nothing and 4 and
+// 40 gwei respectively. The third block only contains a transaction sent by its
+// miner, which is not counted.
+func newTestBackend(pool types.Transactions) *testBackend {
+	contents := [][]*types.Transaction{
+		nil,
+		{pricedTx(0, gwei(1), testKey), pricedTx(1, gwei(10), testKey)},
+		{pricedTx(2, gwei(2), testKey), pricedTx(3, gwei(20), testKey)},
+		{pricedTx(0, big.NewInt(1), minerKey)},
+		{pricedTx(4, gwei(40), testKey), pricedTx(5, gwei(4), testKey)},
+	}
+	backend := &testBackend{pool: pool}
+	for i, txs := range contents {
+		header := &types.Header{
+			Number:   big.NewInt(int64(i)),
+			GasLimit: 100000,
+			Coinbase: testCoinbase,
+		}
+		backend.blocks = append(backend.blocks, types.NewBlock(header, txs, nil, nil))
+	}
+	return backend
+}
+
+// Tests that the histogram samples the requested number of most recent blocks
+// and summarises the included, minimum and pending prices.
+func TestHistogram(t *testing.T) {
+	tests := []struct {
+		blocks   int
+		pool     types.Transactions
+		oldest   uint64
+		included []int64 // Gwei percentiles of the included prices
+		buckets  []uint  // Bucket counts of the included prices
+		minimum  uint    // Number of non-empty sampled blocks
+		pending  uint
+	}{
+		// Default sample size of the oracle, only covering an empty block and the head
+		{
+			blocks:   0,
+			oldest:   3,
+			included: []int64{4, 4, 4, 4, 4},
+			buckets:  []uint{0, 0, 1, 0, 0, 1, 0, 0, 0, 0},
+			minimum:  1,
+		},
+		// Sample larger than the chain, the genesis is skipped
+		{
+			blocks:   100,
+			pool:     types.Transactions{pricedTx(6, gwei(3), testKey)},
+			oldest:   1,
+			included: []int64{1, 2, 4, 10, 20},
+			buckets:  []uint{0, 1, 2, 0, 1, 2, 0, 0, 0, 0},
+			minimum:  3,
+			pending:  1,
+		},
+	}
+	for i, tt := range tests {
+		backend := newTestBackend(tt.pool)
+		oracle := NewOracle(backend, Config{Blocks: 2, Percentile: 60})
+
+		histogram, err := oracle.Histogram(context.Background(), tt.blocks)
+		if err != nil {
+			t.Errorf("test %d: failed to create histogram: %v", i, err)
+			continue
+		}
+		if histogram.OldestBlock != hexutil.Uint64(tt.oldest) || histogram.NewestBlock != 4 {
+			t.Errorf("test %d: range mismatch: have [%d, %d], want [%d, 4]", i, histogram.OldestBlock, histogram.NewestBlock, tt.oldest)
+		}
+		for j, want := range tt.included {
+			if have := histogram.Included.Percentiles[j].ToInt(); have.Cmp(gwei(want)) != 0 {
+				t.Errorf("test %d: percentile %d mismatch: have %v, want %v", i, histogramPercentiles[j], have, gwei(want))
+			}
+		}
+		for j, want := range tt.buckets {
+			if have := uint(histogram.Included.Buckets[j].Count); have != want {
+				t.Errorf("test %d: bucket %d count mismatch: have %d, want %d", i, j, have, want)
+			}
+		}
+		if have := uint(histogram.Minimum.Count); have != tt.minimum {
+			t.Errorf("test %d: minimum count mismatch: have %d, want %d", i, have, tt.minimum)
+		}
+		if have := uint(histogram.Pending.Count); have != tt.pending {
+			t.Errorf("test %d: pending count mismatch: have %d, want %d", i, have, tt.pending)
+		}
+	}
+}
+
+// Tests that the fee estimates take the price levels from the sampled block
+// minimums, and delay them by the gas of the pending transactions paying more.
+func TestFeeEstimate(t *testing.T) {
+	// Pending transactions paying 3 gwei and filling more than a block
+	var crowded types.Transactions
+	for i := 0; i < 5; i++ {
+		crowded = append(crowded, pricedTx(uint64(6+i), gwei(3), testKey))
+	}
+	tests := []struct {
+		blocks int
+		pool   types.Transactions
+		prices []int64  // Gwei price of each level
+		delays []uint64 // Estimated blocks to inclusion of each level
+	}{
+		// Only the head has a minimum price, the empty block accepts any price
+		{blocks: 2, prices: []int64{4, 4, 4, 4, 4}, delays: []uint64{1, 1, 1, 1, 1}},
+
+		// Minimums of 1, 2 and 4 gwei, the lower levels are not accepted by all blocks
+		{blocks: 4, prices: []int64{1, 1, 2, 2, 2}, delays: []uint64{2, 2, 2, 2, 2}},
+		{blocks: 4, pool: crowded, prices: []int64{1, 1, 2, 2, 2}, delays: []uint64{3, 3, 3, 3, 3}},
+	}
+	for i, tt := range tests {
+		backend := newTestBackend(tt.pool)
+		oracle := NewOracle(backend, Config{Blocks: 2, Percentile: 60})
+
+		estimate, err := oracle.FeeEstimate(context.Background(), tt.blocks)
+		if err != nil {
+			t.Errorf("test %d: failed to estimate fees: %v", i, err)
+			continue
+		}
+		if estimate.Block != 4 {
+			t.Errorf("test %d: head mismatch: have %d, want 4", i, estimate.Block)
+		}
+		for j, level := range estimate.Levels {
+			if level.Price.ToInt().Cmp(gwei(tt.prices[j])) != 0 {
+				t.Errorf("test %d, level %d: price mismatch: have %v, want %v", i, j, level.Price.ToInt(), gwei(tt.prices[j]))
+			}
+			if level.Blocks == nil || uint64(*level.Blocks) != tt.delays[j] {
+				t.Errorf("test %d, level %d: delay mismatch: have %v, want %d", i, j, level.Blocks, tt.delays[j])
+			}
+		}
+	}
+}
+
+// Tests that block sampling keeps a bounded number of retrievals in flight and
+// fails if any of the blocks is unavailable.
+func TestSampleBlocks(t *testing.T) {
+	backend := newTestBackend(nil)
+	oracle := NewOracle(backend, Config{Blocks: 2, Percentile: 60})
+
+	_, samples, err := oracle.sampleBlocks(context.Background(), 4)
+	if err != nil {
+		t.Fatalf("failed to sample blocks: %v", err)
+	}
+	for i, sample := range samples {
+		if sample.number != uint64(i+1) {
+			t.Errorf("sample %d: number mismatch: have %d, want %d", i, sample.number, i+1)
+		}
+	}
+	if backend.peak > oracle.checkBlocks {
+		t.Errorf("too many concurrent retrievals: have %d, want at most %d", backend.peak, oracle.checkBlocks)
+	}
+	backend.blocks[2] = nil
+	if _, _, err := oracle.sampleBlocks(context.Background(), 4); err == nil {
+		t.Errorf("sampled a missing block")
+	}
+}
+
+// Tests that the block prices are only collected in full if requested, sparing
+// the sender recoveries of SuggestPrice.
+func TestBlockPrices(t *testing.T) {
+	gpo := NewOracle(newTestBackend(nil), Config{Blocks: 1})
+	ch := make(chan getBlockPricesResult, 1)
+
+	gpo.getBlockPrices(context.Background(), testSigner, 4, false, ch)
+	if res := <-ch; res.price.Cmp(gwei(4)) != 0 || res.prices != nil {
+		t.Errorf("lowest price mismatch: have %v/%v, want %v/nil", res.price, res.prices, gwei(4))
+	}
+	gpo.getBlockPrices(context.Background(), testSigner, 4, true, ch)
+	if res := <-ch; res.price.Cmp(gwei(4)) != 0 || len(res.prices) != 2 || res.prices[1].Cmp(gwei(40)) != 0 {
+		t.Errorf("all prices mismatch: have %v/%v, want %v/[%v %v]", res.price, res.prices, gwei(4), gwei(4), gwei(40))
+	}
+	gpo.getBlockPrices(context.Background(), testSigner, 3, true, ch)
+	if res := <-ch; res.price != nil || len(res.prices) != 0 {
+		t.Errorf("miner block prices mismatch: have %v/%v, want none", res.price, res.prices)
+	}
+}
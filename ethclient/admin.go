@@ -0,0 +1,82 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethclient
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// AdminClient defines typed wrappers for the admin RPC API.
+type AdminClient struct {
+	c *rpc.Client
+}
+
+// Admin returns a client for the admin RPC API of the node.
+func (ec *Client) Admin() *AdminClient {
+	return &AdminClient{ec.c}
+}
+
+// NodeInfo retrieves the networking information of the node.
+func (ac *AdminClient) NodeInfo(ctx context.Context) (*p2p.NodeInfo, error) {
+	var info *p2p.NodeInfo
+	err := ac.c.CallContext(ctx, &info, "admin_nodeInfo")
+	return info, err
+}
+
+// Peers retrieves the networking information of all the connected peers.
+func (ac *AdminClient) Peers(ctx context.Context) ([]*p2p.PeerInfo, error) {
+	var peers []*p2p.PeerInfo
+	err := ac.c.CallContext(ctx, &peers, "admin_peers")
+	return peers, err
+}
+
+// Datadir retrieves the data directory of the node.
+func (ac *AdminClient) Datadir(ctx context.Context) (string, error) {
+	var datadir string
+	err := ac.c.CallContext(ctx, &datadir, "admin_datadir")
+	return datadir, err
+}
+
+// AddPeer requests the node to connect to the given enode URL, maintaining the
+// connection at all times.
+func (ac *AdminClient) AddPeer(ctx context.Context, url string) error {
+	var ok bool
+	return ac.c.CallContext(ctx, &ok, "admin_addPeer", url)
+}
+
+// RemovePeer requests the node to disconnect from the given enode URL.
+func (ac *AdminClient) RemovePeer(ctx context.Context, url string) error {
+	var ok bool
+	return ac.c.CallContext(ctx, &ok, "admin_removePeer", url)
+}
+
+// ExportChain requests the node to export the blockchain into the given file on
+// its own filesystem.
+func (ac *AdminClient) ExportChain(ctx context.Context, file string) error {
+	var ok bool
+	return ac.c.CallContext(ctx, &ok, "admin_exportChain", file)
+}
+
+// ImportChain requests the node to import a blockchain from the given file on
+// its own filesystem.
+func (ac *AdminClient) ImportChain(ctx context.Context, file string) error {
+	var ok bool
+	return ac.c.CallContext(ctx, &ok, "admin_importChain", file)
+}
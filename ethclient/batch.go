@@ -0,0 +1,232 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// errInvalidRange is returned if a block range is requested whose start is past
+// its end.
+var errInvalidRange = errors.New("invalid block range")
+
+// BatchConfig contains the settings for fetching data in batched RPC calls.
+type BatchConfig struct {
+	BatchSize   int // Maximum number of requests packed into a single batch call
+	Concurrency int // Maximum number of batch calls in flight at the same time
+}
+
+// DefaultBatchConfig contains the default settings for batched fetching.
+var DefaultBatchConfig = BatchConfig{
+	BatchSize:   100,
+	Concurrency: 4,
+}
+
+// sanitize returns a copy of the config with invalid fields replaced by their
+// default values.
+func (config *BatchConfig) sanitize() BatchConfig {
+	if config == nil {
+		return DefaultBatchConfig
+	}
+	conf := *config
+	if conf.BatchSize < 1 {
+		conf.BatchSize = DefaultBatchConfig.BatchSize
+	}
+	if conf.Concurrency < 1 {
+		conf.Concurrency = DefaultBatchConfig.Concurrency
+	}
+	return conf
+}
+
+// BlockResult is a block retrieved through a batched fetch, together with the
+// receipts of all its transactions. If the block or any of its receipts could not
+// be retrieved, Err is set and the remaining fields may be partially filled.
+type BlockResult struct {
+	Number   uint64
+	Block    *types.Block
+	Receipts types.Receipts
+	Err      error
+}
+
+// BlocksWithReceipts retrieves the canonical blocks in the inclusive range from
+// the first to the last number, along with the receipts of all their transactions.
+// Blocks are retrieved in batch calls of the configured size, with multiple batch
+// calls running concurrently. If config is nil, DefaultBatchConfig is used.
+//
+// The results are ordered by block number. Failures are reported through the
+// Err field of the individual results; the returned error is only set if the
+// range itself is invalid or the context got cancelled.
+func (ec *Client) BlocksWithReceipts(ctx context.Context, first, last uint64, config *BatchConfig) ([]*BlockResult, error) {
+	if first > last {
+		return nil, errInvalidRange
+	}
+	conf := config.sanitize()
+
+	results := make([]*BlockResult, last-first+1)
+	for i := range results {
+		results[i] = &BlockResult{Number: first + uint64(i)}
+	}
+	var (
+		pend sync.WaitGroup
+		sem  = make(chan struct{}, conf.Concurrency)
+	)
+	for start := 0; start < len(results); start += conf.BatchSize {
+		end := start + conf.BatchSize
+		if end > len(results) {
+			end = len(results)
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			pend.Wait()
+			return nil, ctx.Err()
+		}
+		pend.Add(1)
+		go func(batch []*BlockResult) {
+			defer func() { <-sem; pend.Done() }()
+			ec.fetchBlocks(ctx, batch, conf)
+		}(results[start:end])
+	}
+	pend.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// fetchBlocks retrieves the blocks of a single batch and fills in their receipts.
+func (ec *Client) fetchBlocks(ctx context.Context, batch []*BlockResult, conf BatchConfig) {
+	raws := make([]*json.RawMessage, len(batch))
+	reqs := make([]rpc.BatchElem, len(batch))
+	for i, result := range batch {
+		reqs[i] = rpc.BatchElem{
+			Method: "eth_getBlockByNumber",
+			Args:   []interface{}{hexutil.EncodeUint64(result.Number), true},
+			Result: &raws[i],
+		}
+	}
+	if err := ec.c.BatchCallContext(ctx, reqs); err != nil {
+		for _, result := range batch {
+			result.Err = err
+		}
+		return
+	}
+	var blocks []*BlockResult
+	for i, result := range batch {
+		switch {
+		case reqs[i].Error != nil:
+			result.Err = reqs[i].Error
+		case raws[i] == nil:
+			result.Err = ethereum.NotFound
+		default:
+			result.Block, result.Err = ec.decodeBlock(ctx, *raws[i])
+		}
+		if result.Err == nil {
+			blocks = append(blocks, result)
+		}
+	}
+	// Retrieve the receipts of all the successfully fetched blocks together
+	var (
+		hashes []common.Hash
+		owners []*BlockResult
+	)
+	for _, result := range blocks {
+		for _, tx := range result.Block.Transactions() {
+			hashes = append(hashes, tx.Hash())
+			owners = append(owners, result)
+		}
+	}
+	receipts, errs := ec.fetchReceipts(ctx, hashes, conf.BatchSize)
+	for i, receipt := range receipts {
+		owner := owners[i]
+		if owner.Err != nil {
+			continue
+		}
+		if errs[i] != nil {
+			owner.Err = errs[i]
+			continue
+		}
+		owner.Receipts = append(owner.Receipts, receipt)
+	}
+}
+
+// BlockReceipts retrieves the receipts of all the transactions in the given block
+// using batched calls. If config is nil, DefaultBatchConfig is used.
+func (ec *Client) BlockReceipts(ctx context.Context, block *types.Block, config *BatchConfig) (types.Receipts, error) {
+	conf := config.sanitize()
+
+	hashes := make([]common.Hash, len(block.Transactions()))
+	for i, tx := range block.Transactions() {
+		hashes[i] = tx.Hash()
+	}
+	receipts, errs := ec.fetchReceipts(ctx, hashes, conf.BatchSize)
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return receipts, nil
+}
+
+// fetchReceipts retrieves the receipts of the given transactions in sequential
+// batch calls of the given size, returning the individual error of each.
+func (ec *Client) fetchReceipts(ctx context.Context, hashes []common.Hash, size int) (types.Receipts, []error) {
+	var (
+		receipts = make(types.Receipts, len(hashes))
+		errs     = make([]error, len(hashes))
+	)
+	for start := 0; start < len(hashes); start += size {
+		end := start + size
+		if end > len(hashes) {
+			end = len(hashes)
+		}
+		reqs := make([]rpc.BatchElem, end-start)
+		for i := range reqs {
+			reqs[i] = rpc.BatchElem{
+				Method: "eth_getTransactionReceipt",
+				Args:   []interface{}{hashes[start+i]},
+				Result: &receipts[start+i],
+			}
+		}
+		if err := ec.c.BatchCallContext(ctx, reqs); err != nil {
+			for i := start; i < end; i++ {
+				errs[i] = err
+			}
+			continue
+		}
+		for i, req := range reqs {
+			switch {
+			case req.Error != nil:
+				errs[start+i] = req.Error
+			case receipts[start+i] == nil:
+				errs[start+i] = fmt.Errorf("missing receipt for transaction %x", hashes[start+i])
+			}
+		}
+	}
+	return receipts, errs
+}
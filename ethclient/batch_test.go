@@ -0,0 +1,125 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethclient
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that block ranges are retrieved together with their receipts, and that
+// missing blocks are reported individually.
+func TestBlocksWithReceipts(t *testing.T) {
+	faucet, _ := crypto.GenerateKey()
+
+	stack, backend, ec := newTestNode(t, faucet)
+	defer stack.Stop()
+
+	ctx := context.Background()
+	if _, err := ec.Personal().ImportRawKey(ctx, faucet, ""); err != nil {
+		t.Fatalf("failed to import faucet: %v", err)
+	}
+	if err := ec.Personal().UnlockAccount(ctx, crypto.PubkeyToAddress(faucet.PublicKey), "", 0); err != nil {
+		t.Fatalf("failed to unlock faucet: %v", err)
+	}
+	if err := backend.StartMining(true); err != nil {
+		t.Fatalf("failed to start mining: %v", err)
+	}
+	// Create a few blocks with a varying number of transactions
+	signer := types.NewEIP155Signer(params.AllCliqueProtocolChanges.ChainId)
+
+	var (
+		nonce  uint64
+		hashes = make(map[common.Hash]bool)
+	)
+	for _, count := range []int{1, 3, 2} {
+		var last common.Hash
+		for i := 0; i < count; i++ {
+			tx, _ := types.SignTx(types.NewTransaction(nonce, common.Address{0xff}, big.NewInt(1), 21000, big.NewInt(1), nil), signer, faucet)
+			if err := ec.SendTransaction(ctx, tx); err != nil {
+				t.Fatalf("failed to send transaction %d: %v", nonce, err)
+			}
+			nonce++
+			last, hashes[tx.Hash()] = tx.Hash(), true
+		}
+		waitReceipt(t, ec, last)
+	}
+	head, err := ec.HeaderByNumber(ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve head header: %v", err)
+	}
+	// Retrieve the whole chain plus a missing block in small batches
+	results, err := ec.BlocksWithReceipts(ctx, 0, head.Number.Uint64()+1, &BatchConfig{BatchSize: 2, Concurrency: 2})
+	if err != nil {
+		t.Fatalf("failed to retrieve blocks: %v", err)
+	}
+	if len(results) != int(head.Number.Uint64())+2 {
+		t.Fatalf("result count mismatch: have %d, want %d", len(results), head.Number.Uint64()+2)
+	}
+	for i, result := range results[:len(results)-1] {
+		if result.Err != nil {
+			t.Fatalf("block %d: failed to retrieve: %v", i, result.Err)
+		}
+		if result.Number != uint64(i) || result.Block.NumberU64() != uint64(i) {
+			t.Errorf("block %d: number mismatch: have %d/%d", i, result.Number, result.Block.NumberU64())
+		}
+		txs := result.Block.Transactions()
+		if len(result.Receipts) != len(txs) {
+			t.Fatalf("block %d: receipt count mismatch: have %d, want %d", i, len(result.Receipts), len(txs))
+		}
+		for j, receipt := range result.Receipts {
+			if receipt.TxHash != txs[j].Hash() {
+				t.Errorf("block %d, receipt %d: hash mismatch: have %x, want %x", i, j, receipt.TxHash, txs[j].Hash())
+			}
+			if receipt.Status != types.ReceiptStatusSuccessful {
+				t.Errorf("block %d, receipt %d: status mismatch: have %d", i, j, receipt.Status)
+			}
+			delete(hashes, receipt.TxHash)
+		}
+	}
+	if len(hashes) != 0 {
+		t.Errorf("receipts missing for %d transactions", len(hashes))
+	}
+	if missing := results[len(results)-1]; missing.Err != ethereum.NotFound || missing.Block != nil {
+		t.Errorf("missing block mismatch: have %v/%v, want %v", missing.Block, missing.Err, ethereum.NotFound)
+	}
+	// Retrieve the receipts of the head block directly
+	block := results[len(results)-2].Block
+	receipts, err := ec.BlockReceipts(ctx, block, nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve head receipts: %v", err)
+	}
+	if len(receipts) != len(block.Transactions()) || types.DeriveSha(receipts) != block.ReceiptHash() {
+		t.Errorf("head receipts mismatch: have %d receipts", len(receipts))
+	}
+	// Check the invalid range and cancellation failures
+	if _, err := ec.BlocksWithReceipts(ctx, 2, 1, nil); err != errInvalidRange {
+		t.Errorf("invalid range error mismatch: have %v, want %v", err, errInvalidRange)
+	}
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := ec.BlocksWithReceipts(cancelled, 0, 1, nil); err != context.Canceled {
+		t.Errorf("cancellation error mismatch: have %v, want %v", err, context.Canceled)
+	}
+}
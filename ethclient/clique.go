@@ -0,0 +1,85 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethclient
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/clique"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// CliqueClient defines typed wrappers for the clique proof-of-authority RPC API.
+type CliqueClient struct {
+	c *rpc.Client
+}
+
+// Clique returns a client for the clique RPC API of the node.
+func (ec *Client) Clique() *CliqueClient {
+	return &CliqueClient{ec.c}
+}
+
+// Snapshot retrieves the authorization state at the given block. If number is
+// nil, the latest known block is used.
+func (cc *CliqueClient) Snapshot(ctx context.Context, number *big.Int) (*clique.Snapshot, error) {
+	var snap *clique.Snapshot
+	err := cc.c.CallContext(ctx, &snap, "clique_getSnapshot", toBlockNumArg(number))
+	return snap, err
+}
+
+// SnapshotAtHash retrieves the authorization state at the given block.
+func (cc *CliqueClient) SnapshotAtHash(ctx context.Context, hash common.Hash) (*clique.Snapshot, error) {
+	var snap *clique.Snapshot
+	err := cc.c.CallContext(ctx, &snap, "clique_getSnapshotAtHash", hash)
+	return snap, err
+}
+
+// Signers retrieves the list of authorized signers at the given block. If number
+// is nil, the latest known block is used.
+func (cc *CliqueClient) Signers(ctx context.Context, number *big.Int) ([]common.Address, error) {
+	var signers []common.Address
+	err := cc.c.CallContext(ctx, &signers, "clique_getSigners", toBlockNumArg(number))
+	return signers, err
+}
+
+// SignersAtHash retrieves the list of authorized signers at the given block.
+func (cc *CliqueClient) SignersAtHash(ctx context.Context, hash common.Hash) ([]common.Address, error) {
+	var signers []common.Address
+	err := cc.c.CallContext(ctx, &signers, "clique_getSignersAtHash", hash)
+	return signers, err
+}
+
+// Proposals retrieves the current proposals the node is voting on, mapping the
+// proposed signers to whether they are to be authorized or deauthorized.
+func (cc *CliqueClient) Proposals(ctx context.Context) (map[common.Address]bool, error) {
+	var proposals map[common.Address]bool
+	err := cc.c.CallContext(ctx, &proposals, "clique_proposals")
+	return proposals, err
+}
+
+// Propose requests the node to start voting on authorizing or deauthorizing the
+// given signer.
+func (cc *CliqueClient) Propose(ctx context.Context, signer common.Address, auth bool) error {
+	return cc.c.CallContext(ctx, nil, "clique_propose", signer, auth)
+}
+
+// Discard requests the node to stop voting on the given signer.
+func (cc *CliqueClient) Discard(ctx context.Context, signer common.Address) error {
+	return cc.c.CallContext(ctx, nil, "clique_discard", signer)
+}
@@ -0,0 +1,122 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethclient
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// DebugClient defines typed wrappers for the debug RPC API.
+type DebugClient struct {
+	c *rpc.Client
+}
+
+// Debug returns a client for the debug RPC API of the node.
+func (ec *Client) Debug() *DebugClient {
+	return &DebugClient{ec.c}
+}
+
+// TraceTransaction replays the given transaction with the default structured
+// logger, returning the executed opcodes. Use TraceTransactionWithTracer to run
+// custom tracers.
+func (dc *DebugClient) TraceTransaction(ctx context.Context, hash common.Hash, config *vm.TraceConfig) (*vm.ExecutionResult, error) {
+	var result *vm.ExecutionResult
+	if err := dc.c.CallContext(ctx, &result, "debug_traceTransaction", hash, config); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// TraceTransactionWithTracer replays the given transaction with a named or
+// JavaScript tracer, returning its raw output.
+func (dc *DebugClient) TraceTransactionWithTracer(ctx context.Context, hash common.Hash, tracer string, config *vm.TraceConfig) (json.RawMessage, error) {
+	var traced vm.TraceConfig
+	if config != nil {
+		traced = *config
+	}
+	traced.Tracer = &tracer
+
+	var result json.RawMessage
+	err := dc.c.CallContext(ctx, &result, "debug_traceTransaction", hash, &traced)
+	return result, err
+}
+
+// TraceCall executes the given call on top of the state of the given block with
+// the default structured logger. If number is nil, the latest known block is used.
+func (dc *DebugClient) TraceCall(ctx context.Context, msg ethereum.CallMsg, number *big.Int, config *vm.TraceConfig) (*vm.ExecutionResult, error) {
+	var result *vm.ExecutionResult
+	if err := dc.c.CallContext(ctx, &result, "debug_traceCall", toCallArg(msg), toBlockNumArg(number), config); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DumpBlock retrieves the entire state of the database at the given block.
+func (dc *DebugClient) DumpBlock(ctx context.Context, number *big.Int) (*state.Dump, error) {
+	var dump *state.Dump
+	err := dc.c.CallContext(ctx, &dump, "debug_dumpBlock", toBlockNumArg(number))
+	return dump, err
+}
+
+// Preimage retrieves the preimage of the given sha3 hash, if known.
+func (dc *DebugClient) Preimage(ctx context.Context, hash common.Hash) ([]byte, error) {
+	var preimage hexutil.Bytes
+	err := dc.c.CallContext(ctx, &preimage, "debug_preimage", hash)
+	return preimage, err
+}
+
+// BadBlocks retrieves the last bad blocks the node has seen on the network.
+func (dc *DebugClient) BadBlocks(ctx context.Context) ([]core.BadBlockArgs, error) {
+	var blocks []core.BadBlockArgs
+	err := dc.c.CallContext(ctx, &blocks, "debug_getBadBlocks")
+	return blocks, err
+}
+
+// StorageRangeAt retrieves at most maxResult storage slots of the given contract,
+// starting at keyStart, as they were after executing the given transaction of a
+// block.
+func (dc *DebugClient) StorageRangeAt(ctx context.Context, blockHash common.Hash, txIndex int, contract common.Address, keyStart []byte, maxResult int) (*state.StorageRangeResult, error) {
+	var result *state.StorageRangeResult
+	err := dc.c.CallContext(ctx, &result, "debug_storageRangeAt", blockHash, txIndex, contract, hexutil.Bytes(keyStart), maxResult)
+	return result, err
+}
+
+// ModifiedAccountsByNumber retrieves the accounts modified between the two given
+// blocks, or in the start block only if end is nil.
+func (dc *DebugClient) ModifiedAccountsByNumber(ctx context.Context, start uint64, end *uint64) ([]common.Address, error) {
+	var accounts []common.Address
+	err := dc.c.CallContext(ctx, &accounts, "debug_getModifiedAccountsByNumber", start, end)
+	return accounts, err
+}
+
+// ModifiedAccountsByHash retrieves the accounts modified between the two given
+// blocks, or in the start block only if end is nil.
+func (dc *DebugClient) ModifiedAccountsByHash(ctx context.Context, start common.Hash, end *common.Hash) ([]common.Address, error) {
+	var accounts []common.Address
+	err := dc.c.CallContext(ctx, &accounts, "debug_getModifiedAccountsByHash", start, end)
+	return accounts, err
+}
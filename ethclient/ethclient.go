@@ -84,6 +84,12 @@ func (ec *Client) getBlock(ctx context.Context, method string, args ...interface
 	} else if len(raw) == 0 {
 		return nil, ethereum.NotFound
 	}
+	return ec.decodeBlock(ctx, raw)
+}
+
+// decodeBlock assembles a block from its RPC representation, retrieving its uncle
+// headers from the server.
+func (ec *Client) decodeBlock(ctx context.Context, raw json.RawMessage) (*types.Block, error) {
 	// Decode header and transactions.
 	var head *types.Header
 	var body rpcBlock
@@ -430,6 +436,18 @@ func (ec *Client) CallContract(ctx context.Context, msg ethereum.CallMsg, blockN
 	return hex, nil
 }
 
+// CallContractWithOverride executes a message call transaction like CallContract,
+// but with the code, balance, nonce or storage of some accounts replaced in the
+// state seen by the contract call.
+func (ec *Client) CallContractWithOverride(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int, overrides ethereum.StateOverride) ([]byte, error) {
+	var hex hexutil.Bytes
+	err := ec.c.CallContext(ctx, &hex, "eth_call", toCallArg(msg), toBlockNumArg(blockNumber), toOverrideArg(overrides))
+	if err != nil {
+		return nil, err
+	}
+	return hex, nil
+}
+
 // PendingCallContract executes a message call transaction using the EVM.
 // The state seen by the contract call is the pending state.
 func (ec *Client) PendingCallContract(ctx context.Context, msg ethereum.CallMsg) ([]byte, error) {
@@ -464,6 +482,18 @@ func (ec *Client) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64
 	return uint64(hex), nil
 }
 
+// EstimateGasWithOverride tries to estimate the gas needed to execute a specific
+// transaction like EstimateGas, but with the code, balance, nonce or storage of
+// some accounts replaced in the pending state.
+func (ec *Client) EstimateGasWithOverride(ctx context.Context, msg ethereum.CallMsg, overrides ethereum.StateOverride) (uint64, error) {
+	var hex hexutil.Uint64
+	err := ec.c.CallContext(ctx, &hex, "eth_estimateGas", toCallArg(msg), toOverrideArg(overrides))
+	if err != nil {
+		return 0, err
+	}
+	return uint64(hex), nil
+}
+
 // SendTransaction injects a signed transaction into the pending pool for execution.
 //
 // If the transaction was a contract creation use the TransactionReceipt method to get the
@@ -495,3 +525,30 @@ func toCallArg(msg ethereum.CallMsg) interface{} {
 	}
 	return arg
 }
+
+func toOverrideArg(overrides ethereum.StateOverride) interface{} {
+	if overrides == nil {
+		return nil
+	}
+	arg := make(map[common.Address]interface{}, len(overrides))
+	for addr, account := range overrides {
+		fields := make(map[string]interface{})
+		if account.Nonce != nil {
+			fields["nonce"] = hexutil.Uint64(*account.Nonce)
+		}
+		if account.Code != nil {
+			fields["code"] = hexutil.Bytes(account.Code)
+		}
+		if account.Balance != nil {
+			fields["balance"] = (*hexutil.Big)(account.Balance)
+		}
+		if account.State != nil {
+			fields["state"] = account.State
+		}
+		if account.StateDiff != nil {
+			fields["stateDiff"] = account.StateDiff
+		}
+		arg[addr] = fields
+	}
+	return arg
+}
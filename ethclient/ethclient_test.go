@@ -26,6 +26,7 @@ var (
 	_ = ethereum.ChainSyncReader(&Client{})
 	_ = ethereum.ContractCaller(&Client{})
 	_ = ethereum.GasEstimator(&Client{})
+	_ = ethereum.OverrideContractCaller(&Client{})
 	_ = ethereum.GasPricer(&Client{})
 	_ = ethereum.LogFilterer(&Client{})
 	_ = ethereum.PendingStateReader(&Client{})
@@ -0,0 +1,251 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethclient
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p"
+)
+
+// newTestNode creates an in-process, in-memory node running a developer chain
+// sealed by the given faucet account.
+func newTestNode(t *testing.T, faucet *ecdsa.PrivateKey) (*node.Node, *eth.Ethereum, *Client) {
+	stack, err := node.New(&node.Config{
+		P2P:               p2p.Config{NoDiscovery: true},
+		UseLightweightKDF: true,
+		NoUSB:             true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	config := eth.DefaultConfig
+	config.Genesis = core.DeveloperGenesisBlock(0, crypto.PubkeyToAddress(faucet.PublicKey))
+	config.Etherbase = crypto.PubkeyToAddress(faucet.PublicKey)
+	config.TxPool.Journal = ""
+	config.TxPool.RemoteJournal = ""
+	config.NoPruning = true // Keep historical states around for the debug API
+
+	if err := stack.Register(func(ctx *node.ServiceContext) (node.Service, error) { return eth.New(ctx, &config) }); err != nil {
+		t.Fatalf("failed to register ethereum service: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start node: %v", err)
+	}
+	var backend *eth.Ethereum
+	if err := stack.Service(&backend); err != nil {
+		t.Fatalf("failed to retrieve ethereum service: %v", err)
+	}
+	client, err := stack.Attach()
+	if err != nil {
+		t.Fatalf("failed to attach to node: %v", err)
+	}
+	return stack, backend, NewClient(client)
+}
+
+// waitReceipt waits for the given transaction to be included in the chain.
+func waitReceipt(t *testing.T, ec *Client, hash common.Hash) *types.Receipt {
+	for i := 0; i < 100; i++ {
+		if receipt, err := ec.TransactionReceipt(context.Background(), hash); err == nil && receipt != nil {
+			return receipt
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Fatalf("transaction %x not mined", hash)
+	return nil
+}
+
+// Tests the typed clients of the personal, txpool, debug, clique and admin RPC
+// namespaces against an in-process node.
+func TestNamespaceClients(t *testing.T) {
+	faucet, _ := crypto.GenerateKey()
+	faucetAddr := crypto.PubkeyToAddress(faucet.PublicKey)
+
+	stack, backend, ec := newTestNode(t, faucet)
+	defer stack.Stop()
+
+	ctx := context.Background()
+
+	// Import the faucet key and create a second account via the personal API
+	if addr, err := ec.Personal().ImportRawKey(ctx, faucet, "faucet"); err != nil || addr != faucetAddr {
+		t.Fatalf("faucet import mismatch: have %x/%v, want %x", addr, err, faucetAddr)
+	}
+	other, err := ec.Personal().NewAccount(ctx, "other")
+	if err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+	// The account manager picks up new wallets asynchronously
+	var addrs []common.Address
+	for i := 0; i < 100 && len(addrs) != 2; i++ {
+		if addrs, err = ec.Personal().ListAccounts(ctx); err != nil {
+			t.Fatalf("failed to list accounts: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(addrs) != 2 {
+		t.Fatalf("account listing mismatch: have %v, want 2 accounts", addrs)
+	}
+	wallets, err := ec.Personal().ListWallets(ctx)
+	if err != nil || len(wallets) != 2 {
+		t.Fatalf("wallet listing mismatch: have %v/%v, want 2 wallets", wallets, err)
+	}
+	if len(wallets[0].Accounts) != 1 || wallets[0].Accounts[0].URL.Scheme != "keystore" {
+		t.Errorf("wallet account mismatch: have %v", wallets[0].Accounts)
+	}
+	if err := ec.Personal().UnlockAccount(ctx, faucetAddr, "wrong", 0); err == nil {
+		t.Errorf("account unlocked with wrong passphrase")
+	}
+	if err := ec.Personal().UnlockAccount(ctx, faucetAddr, "faucet", 0); err != nil {
+		t.Fatalf("failed to unlock faucet: %v", err)
+	}
+	sig, err := ec.Personal().Sign(ctx, []byte("hello"), other, "other")
+	if err != nil {
+		t.Fatalf("failed to sign data: %v", err)
+	}
+	if addr, err := ec.Personal().EcRecover(ctx, []byte("hello"), sig); err != nil || addr != other {
+		t.Errorf("recovered signer mismatch: have %x/%v, want %x", addr, err, other)
+	}
+	// Submit a contract creation while not mining and check the pool content
+	code := hexutil.Bytes(common.FromHex("0x60006000f3")) // PUSH1 0 PUSH1 0 RETURN
+	gas := hexutil.Uint64(100000)
+
+	hash, err := ec.Personal().SendTransaction(ctx, SendTxArgs{From: faucetAddr, Gas: &gas, Data: &code}, "faucet")
+	if err != nil {
+		t.Fatalf("failed to send transaction: %v", err)
+	}
+	if pending, queued, err := ec.TxPool().Status(ctx); err != nil || pending != 1 || queued != 0 {
+		t.Errorf("pool status mismatch: have %d/%d/%v, want 1/0", pending, queued, err)
+	}
+	content, err := ec.TxPool().Content(ctx)
+	if err != nil {
+		t.Fatalf("failed to retrieve pool content: %v", err)
+	}
+	if tx := content.Pending[faucetAddr][0]; tx == nil || tx.Hash() != hash {
+		t.Errorf("pool content mismatch: have %v", content.Pending)
+	}
+	inspection, err := ec.TxPool().Inspect(ctx)
+	if err != nil {
+		t.Fatalf("failed to inspect pool: %v", err)
+	}
+	if summary := inspection.Pending[faucetAddr][0]; !strings.HasPrefix(summary, "contract creation") {
+		t.Errorf("pool inspection mismatch: have %q", summary)
+	}
+	// Seal the transaction and trace it
+	if err := backend.StartMining(true); err != nil {
+		t.Fatalf("failed to start mining: %v", err)
+	}
+	waitReceipt(t, ec, hash)
+
+	head, err := ec.HeaderByNumber(ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve head header: %v", err)
+	}
+
+	trace, err := ec.Debug().TraceTransaction(ctx, hash, nil)
+	if err != nil {
+		t.Fatalf("failed to trace transaction: %v", err)
+	}
+	var ops []string
+	for _, log := range trace.StructLogs {
+		ops = append(ops, log.Op)
+	}
+	if want := []string{"PUSH1", "PUSH1", "RETURN"}; trace.Failed || !reflect.DeepEqual(ops, want) {
+		t.Errorf("trace mismatch: have %v/%v, want %v", trace.Failed, ops, want)
+	}
+	call, err := ec.Debug().TraceCall(ctx, ethereum.CallMsg{From: faucetAddr, Gas: 100000, Data: code}, head.Number, &vm.TraceConfig{LogConfig: &vm.LogConfig{DisableStack: true}})
+	if err != nil {
+		t.Fatalf("failed to trace call: %v", err)
+	}
+	if len(call.StructLogs) != len(ops) || call.StructLogs[0].Stack != nil {
+		t.Errorf("call trace mismatch: have %v", call.StructLogs)
+	}
+	tracer := `{data: [], step: function(log) { this.data.push(log.op.toString()); }, fault: function() {}, result: function() { return this.data; }}`
+	raw, err := ec.Debug().TraceTransactionWithTracer(ctx, hash, tracer, nil)
+	if err != nil {
+		t.Fatalf("failed to trace transaction with tracer: %v", err)
+	}
+	var traced []string
+	if err := json.Unmarshal(raw, &traced); err != nil || !reflect.DeepEqual(traced, ops) {
+		t.Errorf("custom trace mismatch: have %s/%v, want %v", raw, err, ops)
+	}
+	modified, err := ec.Debug().ModifiedAccountsByNumber(ctx, head.Number.Uint64(), nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve modified accounts: %v", err)
+	}
+	found := false
+	for _, addr := range modified {
+		found = found || addr == faucetAddr
+	}
+	if !found {
+		t.Errorf("faucet missing from modified accounts: %v", modified)
+	}
+	if dump, err := ec.Debug().DumpBlock(ctx, head.Number); err != nil || dump.Root == "" || len(dump.Accounts) == 0 {
+		t.Errorf("state dump mismatch: have %v/%v", dump, err)
+	}
+	if blocks, err := ec.Debug().BadBlocks(ctx); err != nil || len(blocks) != 0 {
+		t.Errorf("bad blocks mismatch: have %v/%v, want none", blocks, err)
+	}
+	// Check the clique signers and voting
+	if signers, err := ec.Clique().Signers(ctx, nil); err != nil || len(signers) != 1 || signers[0] != faucetAddr {
+		t.Errorf("signers mismatch: have %v/%v, want [%x]", signers, err, faucetAddr)
+	}
+	snap, err := ec.Clique().SnapshotAtHash(ctx, head.Hash())
+	if err != nil {
+		t.Fatalf("failed to retrieve snapshot: %v", err)
+	}
+	if _, ok := snap.Signers[faucetAddr]; !ok || snap.Number != head.Number.Uint64() {
+		t.Errorf("snapshot mismatch: have #%d %v", snap.Number, snap.Signers)
+	}
+	if err := ec.Clique().Propose(ctx, other, true); err != nil {
+		t.Fatalf("failed to propose signer: %v", err)
+	}
+	if proposals, err := ec.Clique().Proposals(ctx); err != nil || !reflect.DeepEqual(proposals, map[common.Address]bool{other: true}) {
+		t.Errorf("proposals mismatch: have %v/%v", proposals, err)
+	}
+	if err := ec.Clique().Discard(ctx, other); err != nil {
+		t.Fatalf("failed to discard proposal: %v", err)
+	}
+	if proposals, err := ec.Clique().Proposals(ctx); err != nil || len(proposals) != 0 {
+		t.Errorf("proposals mismatch after discard: have %v/%v", proposals, err)
+	}
+	// Check the node administration
+	info, err := ec.Admin().NodeInfo(ctx)
+	if err != nil || info.ID != stack.Server().NodeInfo().ID {
+		t.Errorf("node info mismatch: have %v/%v", info, err)
+	}
+	if peers, err := ec.Admin().Peers(ctx); err != nil || len(peers) != 0 {
+		t.Errorf("peers mismatch: have %v/%v, want none", peers, err)
+	}
+	if err := ec.Admin().AddPeer(ctx, "invalid"); err == nil {
+		t.Errorf("invalid peer added")
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethclient
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// PersonalClient defines typed wrappers for the personal RPC API, managing the
+// accounts of the node.
+type PersonalClient struct {
+	c *rpc.Client
+}
+
+// Personal returns a client for the personal RPC API of the node.
+func (ec *Client) Personal() *PersonalClient {
+	return &PersonalClient{ec.c}
+}
+
+// Wallet is a wallet managed by the node, along with its accounts.
+type Wallet struct {
+	URL      string             `json:"url"`
+	Status   string             `json:"status"`
+	Failure  string             `json:"failure,omitempty"`
+	Accounts []accounts.Account `json:"accounts,omitempty"`
+}
+
+// SendTxArgs are the arguments of a transaction to be signed by the node. The
+// fields left nil are filled in by the node.
+type SendTxArgs struct {
+	From     common.Address  `json:"from"`
+	To       *common.Address `json:"to"`
+	Gas      *hexutil.Uint64 `json:"gas"`
+	GasPrice *hexutil.Big    `json:"gasPrice"`
+	Value    *hexutil.Big    `json:"value"`
+	Nonce    *hexutil.Uint64 `json:"nonce"`
+	Data     *hexutil.Bytes  `json:"data"`
+}
+
+// SignTransactionResult is a transaction signed by the node, along with its
+// RLP encoding.
+type SignTransactionResult struct {
+	Raw hexutil.Bytes      `json:"raw"`
+	Tx  *types.Transaction `json:"tx"`
+}
+
+// ListAccounts retrieves the addresses of all the accounts the node manages.
+func (pc *PersonalClient) ListAccounts(ctx context.Context) ([]common.Address, error) {
+	var addresses []common.Address
+	err := pc.c.CallContext(ctx, &addresses, "personal_listAccounts")
+	return addresses, err
+}
+
+// ListWallets retrieves all the wallets the node manages.
+func (pc *PersonalClient) ListWallets(ctx context.Context) ([]Wallet, error) {
+	var wallets []Wallet
+	err := pc.c.CallContext(ctx, &wallets, "personal_listWallets")
+	return wallets, err
+}
+
+// OpenWallet requests the node to open the given wallet, using the passphrase
+// if the wallet requires one.
+func (pc *PersonalClient) OpenWallet(ctx context.Context, url string, passphrase string) error {
+	return pc.c.CallContext(ctx, nil, "personal_openWallet", url, passphrase)
+}
+
+// DeriveAccount requests the given hierarchical deterministic wallet to derive
+// the account at the given path, optionally pinning it for later reuse.
+func (pc *PersonalClient) DeriveAccount(ctx context.Context, url string, path string, pin bool) (accounts.Account, error) {
+	var account accounts.Account
+	err := pc.c.CallContext(ctx, &account, "personal_deriveAccount", url, path, pin)
+	return account, err
+}
+
+// NewAccount creates a new key in the keystore of the node, encrypted with the
+// given passphrase.
+func (pc *PersonalClient) NewAccount(ctx context.Context, passphrase string) (common.Address, error) {
+	var address common.Address
+	err := pc.c.CallContext(ctx, &address, "personal_newAccount", passphrase)
+	return address, err
+}
+
+// ImportRawKey stores the given private key in the keystore of the node, encrypted
+// with the given passphrase.
+func (pc *PersonalClient) ImportRawKey(ctx context.Context, key *ecdsa.PrivateKey, passphrase string) (common.Address, error) {
+	var address common.Address
+	err := pc.c.CallContext(ctx, &address, "personal_importRawKey", hex.EncodeToString(crypto.FromECDSA(key)), passphrase)
+	return address, err
+}
+
+// UnlockAccount unlocks the given account for the given duration, rounded down
+// to seconds. A zero duration unlocks the account until the node exits.
+func (pc *PersonalClient) UnlockAccount(ctx context.Context, address common.Address, passphrase string, duration time.Duration) error {
+	var ok bool
+	return pc.c.CallContext(ctx, &ok, "personal_unlockAccount", address, passphrase, uint64(duration/time.Second))
+}
+
+// LockAccount locks the given account, removing its key from memory.
+func (pc *PersonalClient) LockAccount(ctx context.Context, address common.Address) error {
+	var ok bool
+	return pc.c.CallContext(ctx, &ok, "personal_lockAccount", address)
+}
+
+// SendTransaction signs the transaction with the key of its sender, decrypted
+// with the given passphrase, and submits it to the network.
+func (pc *PersonalClient) SendTransaction(ctx context.Context, args SendTxArgs, passphrase string) (common.Hash, error) {
+	var hash common.Hash
+	err := pc.c.CallContext(ctx, &hash, "personal_sendTransaction", args, passphrase)
+	return hash, err
+}
+
+// SignTransaction signs the transaction with the key of its sender, decrypted
+// with the given passphrase, without submitting it.
+func (pc *PersonalClient) SignTransaction(ctx context.Context, args SendTxArgs, passphrase string) (*SignTransactionResult, error) {
+	var result *SignTransactionResult
+	err := pc.c.CallContext(ctx, &result, "personal_signTransaction", args, passphrase)
+	return result, err
+}
+
+// Sign calculates an Ethereum specific signature of the data with the key of the
+// given account, decrypted with the given passphrase.
+func (pc *PersonalClient) Sign(ctx context.Context, data []byte, address common.Address, passphrase string) ([]byte, error) {
+	var signature hexutil.Bytes
+	err := pc.c.CallContext(ctx, &signature, "personal_sign", hexutil.Bytes(data), address, passphrase)
+	return signature, err
+}
+
+// EcRecover returns the address of the account which created the given signature
+// over the data with Sign.
+func (pc *PersonalClient) EcRecover(ctx context.Context, data, signature []byte) (common.Address, error) {
+	var address common.Address
+	err := pc.c.CallContext(ctx, &address, "personal_ecRecover", hexutil.Bytes(data), hexutil.Bytes(signature))
+	return address, err
+}
@@ -0,0 +1,74 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethclient
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// TxPoolClient defines typed wrappers for the txpool RPC API.
+type TxPoolClient struct {
+	c *rpc.Client
+}
+
+// TxPool returns a client for the txpool RPC API of the node.
+func (ec *Client) TxPool() *TxPoolClient {
+	return &TxPoolClient{ec.c}
+}
+
+// TxPoolContent is the content of the transaction pool, grouped by account and
+// ordered by nonce.
+type TxPoolContent struct {
+	Pending map[common.Address]map[uint64]*types.Transaction `json:"pending"`
+	Queued  map[common.Address]map[uint64]*types.Transaction `json:"queued"`
+}
+
+// TxPoolInspection is the content of the transaction pool flattened into textual
+// summaries, grouped by account and ordered by nonce.
+type TxPoolInspection struct {
+	Pending map[common.Address]map[uint64]string `json:"pending"`
+	Queued  map[common.Address]map[uint64]string `json:"queued"`
+}
+
+// Content retrieves the transactions contained within the transaction pool.
+func (tc *TxPoolClient) Content(ctx context.Context) (*TxPoolContent, error) {
+	var content *TxPoolContent
+	err := tc.c.CallContext(ctx, &content, "txpool_content")
+	return content, err
+}
+
+// Inspect retrieves the textual summaries of the transactions contained within
+// the transaction pool.
+func (tc *TxPoolClient) Inspect(ctx context.Context) (*TxPoolInspection, error) {
+	var inspection *TxPoolInspection
+	err := tc.c.CallContext(ctx, &inspection, "txpool_inspect")
+	return inspection, err
+}
+
+// Status retrieves the number of pending and queued transactions in the pool.
+func (tc *TxPoolClient) Status(ctx context.Context) (pending uint, queued uint, err error) {
+	var status map[string]hexutil.Uint
+	if err := tc.c.CallContext(ctx, &status, "txpool_status"); err != nil {
+		return 0, 0, err
+	}
+	return uint(status["pending"]), uint(status["queued"]), nil
+}
@@ -129,6 +129,28 @@ type ContractCaller interface {
 	CallContract(ctx context.Context, call CallMsg, blockNumber *big.Int) ([]byte, error)
 }
 
+// OverrideAccount specifies the fields of an account to replace in the state a
+// call is executed on. Nil fields are left unchanged. State replaces the entire
+// storage of the account, whereas StateDiff only the given slots; at most one of
+// them may be set.
+type OverrideAccount struct {
+	Nonce     *uint64
+	Code      []byte
+	Balance   *big.Int
+	State     map[common.Hash]common.Hash
+	StateDiff map[common.Hash]common.Hash
+}
+
+// StateOverride is the set of accounts to replace in the state a call is executed on.
+type StateOverride map[common.Address]OverrideAccount
+
+// OverrideContractCaller can be used to perform calls and gas estimations as if
+// some accounts had a different code, balance, nonce or storage.
+type OverrideContractCaller interface {
+	CallContractWithOverride(ctx context.Context, call CallMsg, blockNumber *big.Int, overrides StateOverride) ([]byte, error)
+	EstimateGasWithOverride(ctx context.Context, call CallMsg, overrides StateOverride) (uint64, error)
+}
+
 // FilterQuery contains options for contract log filtering.
 type FilterQuery struct {
 	FromBlock *big.Int         // beginning of the queried range, nil means genesis block
@@ -0,0 +1,369 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+var (
+	errNoABI         = errors.New("no ABI registered")
+	errUnknownMethod = errors.New("unknown method")
+	errUnknownEvent  = errors.New("unknown event")
+)
+
+// abiRegistry is the set of contract ABIs registered in the database, caching
+// their parsed forms to avoid reparsing them on every decoding request.
+type abiRegistry struct {
+	db    ethdb.Database
+	cache map[common.Address]*abi.ABI
+	lock  sync.RWMutex
+}
+
+// newABIRegistry creates a contract ABI registry backed by the given database.
+func newABIRegistry(db ethdb.Database) *abiRegistry {
+	return &abiRegistry{
+		db:    db,
+		cache: make(map[common.Address]*abi.ABI),
+	}
+}
+
+// get retrieves the parsed ABI registered for a contract.
+func (r *abiRegistry) get(address common.Address) (*abi.ABI, error) {
+	r.lock.RLock()
+	parsed, ok := r.cache[address]
+	r.lock.RUnlock()
+
+	if ok {
+		return parsed, nil
+	}
+	blob := core.GetContractABI(r.db, address)
+	if blob == nil {
+		return nil, errNoABI
+	}
+	parsed = new(abi.ABI)
+	if err := json.Unmarshal(blob, parsed); err != nil {
+		return nil, err
+	}
+	r.lock.Lock()
+	r.cache[address] = parsed
+	r.lock.Unlock()
+
+	return parsed, nil
+}
+
+// register validates and stores the ABI definition of a contract.
+func (r *abiRegistry) register(address common.Address, blob []byte) error {
+	parsed := new(abi.ABI)
+	if err := json.Unmarshal(blob, parsed); err != nil {
+		return fmt.Errorf("invalid ABI: %v", err)
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if err := core.WriteContractABI(r.db, address, blob); err != nil {
+		return err
+	}
+	r.cache[address] = parsed
+	return nil
+}
+
+// unregister removes the ABI definition of a contract, returning whether there
+// was any registered.
+func (r *abiRegistry) unregister(address common.Address) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if core.GetContractABI(r.db, address) == nil {
+		return false
+	}
+	core.DeleteContractABI(r.db, address)
+	delete(r.cache, address)
+	return true
+}
+
+// DecodedArgument is a single decoded method argument or event field.
+type DecodedArgument struct {
+	Name    string      `json:"name"`
+	Type    string      `json:"type"`
+	Indexed bool        `json:"indexed,omitempty"`
+	Value   interface{} `json:"value"`
+}
+
+// DecodedCall is a contract method invocation decoded from transaction input.
+type DecodedCall struct {
+	To        common.Address    `json:"to"`
+	Method    string            `json:"method"`
+	Signature string            `json:"signature"`
+	Arguments []DecodedArgument `json:"arguments"`
+}
+
+// DecodedLog is a contract event decoded from a log. Logs which can't be decoded
+// have their failure reported in the error field instead.
+type DecodedLog struct {
+	Address   common.Address    `json:"address"`
+	LogIndex  hexutil.Uint      `json:"logIndex"`
+	Event     string            `json:"event,omitempty"`
+	Signature string            `json:"signature,omitempty"`
+	Arguments []DecodedArgument `json:"arguments,omitempty"`
+	Error     string            `json:"error,omitempty"`
+}
+
+// PublicABIAPI provides an API to decode transactions and logs of contracts
+// with registered ABIs.
+type PublicABIAPI struct {
+	b        Backend
+	registry *abiRegistry
+}
+
+// newPublicABIAPI creates a new ABI decoding API.
+func newPublicABIAPI(b Backend, registry *abiRegistry) *PublicABIAPI {
+	return &PublicABIAPI{b: b, registry: registry}
+}
+
+// GetABI returns the ABI definition registered for the given contract, or nil
+// if there is none.
+func (api *PublicABIAPI) GetABI(address common.Address) json.RawMessage {
+	return core.GetContractABI(api.b.ChainDb(), address)
+}
+
+// DecodeInput decodes the input data of a call to the given contract.
+func (api *PublicABIAPI) DecodeInput(address common.Address, input hexutil.Bytes) (*DecodedCall, error) {
+	parsed, err := api.registry.get(address)
+	if err != nil {
+		return nil, err
+	}
+	return decodeCall(parsed, address, input)
+}
+
+// DecodeTransaction decodes the contract method invocation of the transaction
+// with the given hash, looking it up in the chain or the transaction pool.
+func (api *PublicABIAPI) DecodeTransaction(hash common.Hash) (*DecodedCall, error) {
+	tx, _, _, _ := core.GetTransaction(api.b.ChainDb(), hash)
+	if tx == nil {
+		if tx = api.b.GetPoolTransaction(hash); tx == nil {
+			return nil, errors.New("unknown transaction")
+		}
+	}
+	if tx.To() == nil {
+		return nil, errors.New("contract creation transaction")
+	}
+	return api.DecodeInput(*tx.To(), tx.Data())
+}
+
+// DecodeLogs decodes the events of all the logs in the receipt of the transaction
+// with the given hash.
+func (api *PublicABIAPI) DecodeLogs(hash common.Hash) ([]*DecodedLog, error) {
+	receipt, _, _, _ := core.GetReceipt(api.b.ChainDb(), hash)
+	if receipt == nil {
+		return nil, errors.New("unknown receipt")
+	}
+	decoded := make([]*DecodedLog, len(receipt.Logs))
+	for i, log := range receipt.Logs {
+		decoded[i] = &DecodedLog{Address: log.Address, LogIndex: hexutil.Uint(log.Index)}
+
+		parsed, err := api.registry.get(log.Address)
+		if err == nil {
+			err = decodeLog(parsed, log, decoded[i])
+		}
+		if err != nil {
+			decoded[i].Error = err.Error()
+		}
+	}
+	return decoded, nil
+}
+
+// PrivateABIAPI provides an API to manage the registered contract ABIs. As it
+// modifies the database, it lives in its own namespace, apart from the public
+// decoding methods.
+type PrivateABIAPI struct {
+	registry *abiRegistry
+}
+
+// newPrivateABIAPI creates a new ABI registry management API.
+func newPrivateABIAPI(registry *abiRegistry) *PrivateABIAPI {
+	return &PrivateABIAPI{registry: registry}
+}
+
+// RegisterABI stores the ABI definition of a contract, replacing any previously
+// registered one. The definition may be given either as a JSON array or as a
+// string containing it.
+func (api *PrivateABIAPI) RegisterABI(address common.Address, definition json.RawMessage) error {
+	var blob string
+	if err := json.Unmarshal(definition, &blob); err == nil {
+		definition = json.RawMessage(blob)
+	}
+	compact := new(bytes.Buffer)
+	if err := json.Compact(compact, definition); err != nil {
+		return fmt.Errorf("invalid ABI: %v", err)
+	}
+	return api.registry.register(address, compact.Bytes())
+}
+
+// UnregisterABI removes the ABI definition of a contract, returning whether
+// there was any registered.
+func (api *PrivateABIAPI) UnregisterABI(address common.Address) bool {
+	return api.registry.unregister(address)
+}
+
+// decodeCall decodes the method invocation of a contract from its input data.
+func decodeCall(parsed *abi.ABI, address common.Address, input []byte) (*DecodedCall, error) {
+	if len(input) < 4 {
+		return nil, errors.New("input too short for method selector")
+	}
+	method := parsed.MethodById(input)
+	if method == nil {
+		return nil, errUnknownMethod
+	}
+	values, err := method.Inputs.UnpackValues(input[4:])
+	if err != nil {
+		return nil, err
+	}
+	call := &DecodedCall{
+		To:        address,
+		Method:    method.Name,
+		Signature: method.Sig(),
+		Arguments: make([]DecodedArgument, len(method.Inputs)),
+	}
+	for i, arg := range method.Inputs {
+		call.Arguments[i] = DecodedArgument{
+			Name:  arg.Name,
+			Type:  arg.Type.String(),
+			Value: formatABIValue(arg.Type, values[i]),
+		}
+	}
+	return call, nil
+}
+
+// decodeLog decodes the event of a log, matching its first topic against the
+// events of the contract ABI. Indexed fields are decoded from the topics, where
+// dynamic types are only available as the hash of their value.
+func decodeLog(parsed *abi.ABI, log *types.Log, decoded *DecodedLog) error {
+	if len(log.Topics) == 0 {
+		return errUnknownEvent
+	}
+	var event *abi.Event
+	for _, candidate := range parsed.Events {
+		if !candidate.Anonymous && candidate.Id() == log.Topics[0] {
+			event = &candidate
+			break
+		}
+	}
+	if event == nil {
+		return errUnknownEvent
+	}
+	values, err := event.Inputs.UnpackValues(log.Data)
+	if err != nil {
+		return err
+	}
+	var (
+		args  = make([]DecodedArgument, len(event.Inputs))
+		kinds = make([]string, len(event.Inputs))
+		topic = 1
+	)
+	for i, arg := range event.Inputs {
+		args[i] = DecodedArgument{Name: arg.Name, Type: arg.Type.String(), Indexed: arg.Indexed}
+		kinds[i] = arg.Type.String()
+
+		if !arg.Indexed {
+			args[i].Value = formatABIValue(arg.Type, values[0])
+			values = values[1:]
+			continue
+		}
+		if topic >= len(log.Topics) {
+			return errors.New("insufficient topics for indexed fields")
+		}
+		args[i].Value = log.Topics[topic]
+		if isStaticTopic(arg.Type) {
+			value, err := abi.Arguments{{Type: arg.Type}}.UnpackValues(log.Topics[topic].Bytes())
+			if err != nil {
+				return err
+			}
+			args[i].Value = formatABIValue(arg.Type, value[0])
+		}
+		topic++
+	}
+	decoded.Event = event.Name
+	decoded.Signature = fmt.Sprintf("%v(%v)", event.Name, strings.Join(kinds, ","))
+	decoded.Arguments = args
+	return nil
+}
+
+// isStaticTopic returns whether an indexed field of the given type is stored in
+// its topic as is, rather than hashed.
+func isStaticTopic(t abi.Type) bool {
+	switch t.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		return false
+	}
+	return true
+}
+
+// formatABIValue converts a decoded ABI value into its JSON-RPC representation,
+// encoding big numbers and byte arrays as hex strings and tuples as objects.
+func formatABIValue(t abi.Type, value interface{}) interface{} {
+	switch t.T {
+	case abi.IntTy, abi.UintTy:
+		if n, ok := value.(*big.Int); ok {
+			return (*hexutil.Big)(n)
+		}
+	case abi.BytesTy:
+		if b, ok := value.([]byte); ok {
+			return hexutil.Bytes(b)
+		}
+	case abi.FixedBytesTy, abi.FunctionTy:
+		v := reflect.ValueOf(value)
+		if v.Kind() == reflect.Array && v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			return hexutil.Bytes(b)
+		}
+	case abi.SliceTy, abi.ArrayTy:
+		v := reflect.ValueOf(value)
+		if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+			items := make([]interface{}, v.Len())
+			for i := 0; i < v.Len(); i++ {
+				items[i] = formatABIValue(*t.Elem, v.Index(i).Interface())
+			}
+			return items
+		}
+	case abi.TupleTy:
+		v := reflect.ValueOf(value)
+		if v.Kind() == reflect.Struct && v.NumField() == len(t.TupleElems) {
+			fields := make(map[string]interface{}, len(t.TupleElems))
+			for i, elem := range t.TupleElems {
+				fields[t.TupleRawNames[i]] = formatABIValue(*elem, v.Field(i).Interface())
+			}
+			return fields
+		}
+	}
+	return value
+}
@@ -0,0 +1,187 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"encoding/json"
+	"math/big"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+const testABI = `[
+	{"type": "function", "name": "transfer", "inputs": [{"name": "to", "type": "address"}, {"name": "amount", "type": "uint256"}]},
+	{"type": "function", "name": "static", "inputs": [{"name": "s", "type": "tuple", "components": [{"name": "a", "type": "uint256"}, {"name": "b", "type": "bool"}]}, {"name": "c", "type": "uint256"}]},
+	{"type": "function", "name": "list", "inputs": [{"name": "s", "type": "tuple[]", "components": [{"name": "text", "type": "string"}]}]},
+	{"type": "event", "name": "Transfer", "inputs": [{"name": "from", "type": "address", "indexed": true}, {"name": "memo", "type": "string", "indexed": true}, {"name": "amount", "type": "uint256"}]}
+]`
+
+// mustParseABI parses the test ABI, failing the test on error.
+func mustParseABI(t *testing.T) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(testABI))
+	if err != nil {
+		t.Fatalf("failed to parse ABI: %v", err)
+	}
+	return parsed
+}
+
+// Tests that method invocations are decoded with their arguments formatted for
+// JSON, including tuples and lists of them.
+func TestDecodeCall(t *testing.T) {
+	parsed := mustParseABI(t)
+	contract := common.Address{0xc0}
+
+	type staticTuple struct {
+		A *big.Int
+		B bool
+	}
+	type textTuple struct {
+		Text string
+	}
+	tests := []struct {
+		method string
+		args   []interface{}
+		want   string // JSON encoded decoded call
+	}{
+		{
+			method: "transfer",
+			args:   []interface{}{common.Address{0xaa}, big.NewInt(1000)},
+			want:   `{"to":"0xc000000000000000000000000000000000000000","method":"transfer","signature":"transfer(address,uint256)","arguments":[{"name":"to","type":"address","value":"0xaa00000000000000000000000000000000000000"},{"name":"amount","type":"uint256","value":"0x3e8"}]}`,
+		},
+		{
+			method: "static",
+			args:   []interface{}{staticTuple{big.NewInt(1), true}, big.NewInt(2)},
+			want:   `{"to":"0xc000000000000000000000000000000000000000","method":"static","signature":"static((uint256,bool),uint256)","arguments":[{"name":"s","type":"(uint256,bool)","value":{"a":"0x1","b":true}},{"name":"c","type":"uint256","value":"0x2"}]}`,
+		},
+		{
+			method: "list",
+			args:   []interface{}{[]textTuple{{"foo"}, {"bar"}}},
+			want:   `{"to":"0xc000000000000000000000000000000000000000","method":"list","signature":"list((string)[])","arguments":[{"name":"s","type":"(string)[]","value":[{"text":"foo"},{"text":"bar"}]}]}`,
+		},
+	}
+	for _, tt := range tests {
+		input, err := parsed.Pack(tt.method, tt.args...)
+		if err != nil {
+			t.Fatalf("%s: failed to pack input: %v", tt.method, err)
+		}
+		call, err := decodeCall(&parsed, contract, input)
+		if err != nil {
+			t.Errorf("%s: failed to decode call: %v", tt.method, err)
+			continue
+		}
+		if blob, _ := json.Marshal(call); string(blob) != tt.want {
+			t.Errorf("%s: decoded call mismatch:\nhave %s\nwant %s", tt.method, blob, tt.want)
+		}
+	}
+	// Ensure malformed inputs are rejected
+	if _, err := decodeCall(&parsed, contract, []byte{0x01, 0x02}); err == nil {
+		t.Errorf("decoded input without method selector")
+	}
+	if _, err := decodeCall(&parsed, contract, []byte{0x01, 0x02, 0x03, 0x04}); err != errUnknownMethod {
+		t.Errorf("error mismatch for unknown method: have %v, want %v", err, errUnknownMethod)
+	}
+}
+
+// Tests that events are decoded from logs, with indexed static fields taken from
+// their topics as is and indexed dynamic ones reported as their hash.
+func TestDecodeLog(t *testing.T) {
+	parsed := mustParseABI(t)
+	event := parsed.Events["Transfer"]
+
+	data, err := abi.Arguments{{Type: event.Inputs[2].Type}}.Pack(big.NewInt(7))
+	if err != nil {
+		t.Fatalf("failed to pack log data: %v", err)
+	}
+	memo := crypto.Keccak256Hash([]byte("hello"))
+	log := &types.Log{
+		Address: common.Address{0xc0},
+		Topics:  []common.Hash{event.Id(), common.BytesToHash(common.Address{0xaa}.Bytes()), memo},
+		Data:    data,
+	}
+	decoded := new(DecodedLog)
+	if err := decodeLog(&parsed, log, decoded); err != nil {
+		t.Fatalf("failed to decode log: %v", err)
+	}
+	want := `{"address":"0x0000000000000000000000000000000000000000","logIndex":"0x0","event":"Transfer","signature":"Transfer(address,string,uint256)","arguments":[` +
+		`{"name":"from","type":"address","indexed":true,"value":"0xaa00000000000000000000000000000000000000"},` +
+		`{"name":"memo","type":"string","indexed":true,"value":"` + memo.Hex() + `"},` +
+		`{"name":"amount","type":"uint256","value":"0x7"}]}`
+	if blob, _ := json.Marshal(decoded); string(blob) != want {
+		t.Errorf("decoded log mismatch:\nhave %s\nwant %s", blob, want)
+	}
+	// Ensure logs of unknown events or missing indexed fields are rejected
+	if err := decodeLog(&parsed, &types.Log{Topics: []common.Hash{{0x01}}}, new(DecodedLog)); err != errUnknownEvent {
+		t.Errorf("error mismatch for unknown event: have %v, want %v", err, errUnknownEvent)
+	}
+	log.Topics = log.Topics[:2]
+	if err := decodeLog(&parsed, log, new(DecodedLog)); err == nil {
+		t.Errorf("decoded log with missing topics")
+	}
+}
+
+// Tests that ABIs can be registered either as JSON arrays or as strings holding
+// them, and that the registered ones are used for decoding.
+func TestRegisterABI(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	registry := newABIRegistry(db)
+	private, public := newPrivateABIAPI(registry), newPublicABIAPI(nil, registry)
+
+	input, err := mustParseABI(t).Pack("transfer", common.Address{0xaa}, big.NewInt(1))
+	if err != nil {
+		t.Fatalf("failed to pack input: %v", err)
+	}
+	definitions := []json.RawMessage{
+		json.RawMessage(testABI),
+		json.RawMessage(strconv.Quote(testABI)),
+	}
+	for i, definition := range definitions {
+		contract := common.Address{byte(i + 1)}
+		if _, err := public.DecodeInput(contract, input); err != errNoABI {
+			t.Errorf("definition %d: error mismatch before registration: have %v, want %v", i, err, errNoABI)
+		}
+		if err := private.RegisterABI(contract, definition); err != nil {
+			t.Errorf("definition %d: failed to register ABI: %v", i, err)
+			continue
+		}
+		if call, err := public.DecodeInput(contract, input); err != nil || call.Method != "transfer" {
+			t.Errorf("definition %d: failed to decode with registered ABI: %v, %v", i, call, err)
+		}
+		// Ensure a fresh registry loads the ABI from the database
+		if _, err := newABIRegistry(db).get(contract); err != nil {
+			t.Errorf("definition %d: failed to load registered ABI: %v", i, err)
+		}
+		if !private.UnregisterABI(contract) || private.UnregisterABI(contract) {
+			t.Errorf("definition %d: unregistration mismatch", i)
+		}
+		if _, err := public.DecodeInput(contract, input); err != errNoABI {
+			t.Errorf("definition %d: error mismatch after unregistration: have %v, want %v", i, err, errNoABI)
+		}
+	}
+	// Ensure invalid definitions are rejected
+	for i, definition := range []string{`{"type": "function"`, `"not an abi"`, `[{"type": "function", "inputs": [{"type": "foo"}]}]`} {
+		if err := private.RegisterABI(common.Address{0xff}, json.RawMessage(definition)); err == nil {
+			t.Errorf("invalid definition %d accepted", i)
+		}
+	}
+}
@@ -25,13 +25,16 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -46,6 +49,9 @@ import (
 
 const (
 	defaultGasPrice = 50 * params.Shannon
+
+	maxBundleCalls = 100        // Maximum number of calls simulated by a single CallMany
+	maxBundleGas   = 1000000000 // Maximum total gas allowance of the calls simulated by a CallMany
 )
 
 // PublicEthereumAPI provides an API to access Ethereum related information.
@@ -93,6 +99,10 @@ func (s *PublicEthereumAPI) Syncing() (interface{}, error) {
 	}, nil
 }
 
+// txStreamChanSize is the size of the channels buffering the transaction pool
+// events of a content stream while the snapshot is assembled.
+const txStreamChanSize = 4096
+
 // PublicTxPoolAPI offers and API for the transaction pool. It only operates on data that is non confidential.
 type PublicTxPoolAPI struct {
 	b Backend
@@ -115,7 +125,7 @@ func (s *PublicTxPoolAPI) Content() map[string]map[string]map[string]*RPCTransac
 	for account, txs := range pending {
 		dump := make(map[string]*RPCTransaction)
 		for _, tx := range txs {
-			dump[fmt.Sprintf("%d", tx.Nonce())] = newRPCPendingTransaction(tx)
+			dump[fmt.Sprintf("%d", tx.Nonce())] = NewRPCPendingTransaction(tx)
 		}
 		content["pending"][account.Hex()] = dump
 	}
@@ -123,13 +133,109 @@ func (s *PublicTxPoolAPI) Content() map[string]map[string]map[string]*RPCTransac
 	for account, txs := range queue {
 		dump := make(map[string]*RPCTransaction)
 		for _, tx := range txs {
-			dump[fmt.Sprintf("%d", tx.Nonce())] = newRPCPendingTransaction(tx)
+			dump[fmt.Sprintf("%d", tx.Nonce())] = NewRPCPendingTransaction(tx)
 		}
 		content["queued"][account.Hex()] = dump
 	}
 	return content
 }
 
+// TxPoolUpdate is a single notification of a transaction pool content stream.
+type TxPoolUpdate struct {
+	Type        string                                           `json:"type"`                  // One of snapshot, pending, queued or dropped
+	Content     map[string]map[string]map[string]*RPCTransaction `json:"content,omitempty"`     // Pool content for snapshots
+	Transaction *RPCTransaction                                  `json:"transaction,omitempty"` // Transaction becoming pending or queued
+	Hash        *common.Hash                                     `json:"hash,omitempty"`        // Hash of the dropped transaction
+	Reason      string                                           `json:"reason,omitempty"`      // Reason of the drop
+	Replacement *common.Hash                                     `json:"replacedBy,omitempty"`  // Hash of the replacing transaction
+}
+
+// ContentStream creates a subscription which first sends a snapshot of the
+// transaction pool content, in the same format as Content, followed by updates
+// for every transaction becoming pending (newly added or promoted), becoming
+// queued (newly added or demoted) and leaving the pool. Transactions removed
+// because they were included in a block are reported as stale drops.
+func (s *PublicTxPoolAPI) ContentStream(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	// Subscribe to the pool events before taking the snapshot to avoid gaps
+	var (
+		pends  = make(chan core.TxPreEvent, txStreamChanSize)
+		queues = make(chan core.TxQueuedEvent, txStreamChanSize)
+		drops  = make(chan core.TxDropEvent, txStreamChanSize)
+	)
+	pendSub := s.b.SubscribeTxPreEvent(pends)
+	queueSub := s.b.SubscribeTxQueuedEvent(queues)
+	dropSub := s.b.SubscribeTxDropEvent(drops)
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		defer pendSub.Unsubscribe()
+		defer queueSub.Unsubscribe()
+		defer dropSub.Unsubscribe()
+
+		// Send the snapshot, tracking the section of each transaction to avoid
+		// announcing them again if their events raced with the subscription
+		content := s.Content()
+		sections := make(map[common.Hash]string)
+		for section, accounts := range content {
+			for _, txs := range accounts {
+				for _, tx := range txs {
+					sections[tx.Hash] = section
+				}
+			}
+		}
+		notifier.Notify(rpcSub.ID, &TxPoolUpdate{Type: "snapshot", Content: content})
+
+		// announce sends a transaction entering a section, unless it already
+		// was in that section in the snapshot
+		announce := func(section string, tx *types.Transaction) {
+			hash := tx.Hash()
+			if known, ok := sections[hash]; ok {
+				delete(sections, hash)
+				if known == section {
+					return
+				}
+			}
+			notifier.Notify(rpcSub.ID, &TxPoolUpdate{Type: section, Transaction: NewRPCPendingTransaction(tx)})
+		}
+		for {
+			select {
+			case ev := <-pends:
+				announce("pending", ev.Tx)
+
+			case ev := <-queues:
+				announce("queued", ev.Tx)
+
+			case ev := <-drops:
+				hash := ev.Tx.Hash()
+				delete(sections, hash)
+
+				update := &TxPoolUpdate{Type: "dropped", Hash: &hash, Reason: ev.Reason.String()}
+				if ev.Reason == core.TxDropReplaced {
+					update.Replacement = &ev.Replacement
+				}
+				notifier.Notify(rpcSub.ID, update)
+
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			case <-pendSub.Err():
+				return
+			case <-queueSub.Err():
+				return
+			case <-dropSub.Err():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
 // Status returns the number of pending and queued transaction in the pool.
 func (s *PublicTxPoolAPI) Status() map[string]hexutil.Uint {
 	pending, queue := s.b.Stats()
@@ -300,6 +406,24 @@ func fetchKeystore(am *accounts.Manager) *keystore.KeyStore {
 	return am.Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
 }
 
+// accountUnlocker is a keystore able to unlock and lock its accounts.
+type accountUnlocker interface {
+	TimedUnlock(a accounts.Account, passphrase string, timeout time.Duration) error
+	Lock(addr common.Address) error
+}
+
+// fetchUnlocker retrieves the keystore holding the given address from the
+// account manager, falling back to the encrypted keystore if the address is not
+// derived from any HD wallet.
+func fetchUnlocker(am *accounts.Manager, addr common.Address) accountUnlocker {
+	for _, backend := range am.Backends(keystore.HDKeyStoreType) {
+		if hks := backend.(*keystore.HDKeyStore); hks.HasAddress(addr) {
+			return hks
+		}
+	}
+	return fetchKeystore(am)
+}
+
 // ImportRawKey stores the given hex encoded ECDSA key into the key directory,
 // encrypting it with the passphrase.
 func (s *PrivateAccountAPI) ImportRawKey(privkey string, password string) (common.Address, error) {
@@ -324,13 +448,13 @@ func (s *PrivateAccountAPI) UnlockAccount(addr common.Address, password string,
 	} else {
 		d = time.Duration(*duration) * time.Second
 	}
-	err := fetchKeystore(s.am).TimedUnlock(accounts.Account{Address: addr}, password, d)
+	err := fetchUnlocker(s.am, addr).TimedUnlock(accounts.Account{Address: addr}, password, d)
 	return err == nil, err
 }
 
 // LockAccount will lock the account associated with the given address when it's unlocked.
 func (s *PrivateAccountAPI) LockAccount(addr common.Address) bool {
-	return fetchKeystore(s.am).Lock(addr) == nil
+	return fetchUnlocker(s.am, addr).Lock(addr) == nil
 }
 
 // signTransactions sets defaults and signs the given transaction
@@ -611,17 +735,14 @@ type CallArgs struct {
 	Data     hexutil.Bytes   `json:"data"`
 }
 
-func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, vmCfg vm.Config) ([]byte, uint64, bool, error) {
-	defer func(start time.Time) { log.Debug("Executing EVM call finished", "runtime", time.Since(start)) }(time.Now())
-
-	state, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
-	if state == nil || err != nil {
-		return nil, 0, false, err
-	}
+// ToMessage converts the call arguments into a message, filling in the first
+// account of the manager as the sender, and a default gas allowance and gas
+// price for the ones not specified.
+func (args *CallArgs) ToMessage(am *accounts.Manager) types.Message {
 	// Set sender address or use a default if none specified
 	addr := args.From
 	if addr == (common.Address{}) {
-		if wallets := s.b.AccountManager().Wallets(); len(wallets) > 0 {
+		if wallets := am.Wallets(); len(wallets) > 0 {
 			if accounts := wallets[0].Accounts(); len(accounts) > 0 {
 				addr = accounts[0].Address
 			}
@@ -635,27 +756,118 @@ func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr
 	if gasPrice.Sign() == 0 {
 		gasPrice = new(big.Int).SetUint64(defaultGasPrice)
 	}
+	return types.NewMessage(addr, args.To, 0, args.Value.ToInt(), gas, gasPrice, args.Data, false)
+}
 
-	// Create new call message
-	msg := types.NewMessage(addr, args.To, 0, args.Value.ToInt(), gas, gasPrice, args.Data, false)
+// OverrideAccount specifies the fields of an account to replace in the state a
+// call is executed on. Nil fields are left unchanged; State replaces the entire
+// storage of the account, while StateDiff only patches the given slots.
+type OverrideAccount struct {
+	Nonce     *hexutil.Uint64              `json:"nonce"`
+	Code      *hexutil.Bytes               `json:"code"`
+	Balance   *hexutil.Big                 `json:"balance"`
+	State     *map[common.Hash]common.Hash `json:"state"`
+	StateDiff *map[common.Hash]common.Hash `json:"stateDiff"`
+}
 
-	// Setup context so it may be cancelled the call has completed
-	// or, in case of unmetered gas, setup a context with a timeout.
-	var cancel context.CancelFunc
-	if vmCfg.DisableGasMetering {
-		ctx, cancel = context.WithTimeout(ctx, time.Second*5)
-	} else {
-		ctx, cancel = context.WithCancel(ctx)
+// StateOverride is the set of accounts to replace in the state a call is
+// executed on.
+type StateOverride map[common.Address]OverrideAccount
+
+// Apply overrides the fields of the specified accounts in the given state.
+func (diff *StateOverride) Apply(state *state.StateDB) error {
+	if diff == nil {
+		return nil
 	}
-	// Make sure the context is cancelled when the call has completed
-	// this makes sure resources are cleaned up.
-	defer func() { cancel() }()
+	overrides := make(ethereum.StateOverride, len(*diff))
+	for addr, account := range *diff {
+		var override ethereum.OverrideAccount
+		if account.Nonce != nil {
+			override.Nonce = (*uint64)(account.Nonce)
+		}
+		if account.Code != nil {
+			override.Code = append([]byte{}, *account.Code...) // Non-nil even if empty
+		}
+		override.Balance = (*big.Int)(account.Balance)
+		if account.State != nil {
+			override.State = *account.State
+		}
+		if account.StateDiff != nil {
+			override.StateDiff = *account.StateDiff
+		}
+		overrides[addr] = override
+	}
+	return core.ApplyStateOverride(state, overrides)
+}
 
-	// Get a new instance of the EVM.
+// BlockOverrides is a set of block context fields replacing the ones of the
+// block calls are executed on.
+type BlockOverrides struct {
+	Number   *hexutil.Big    `json:"number"`
+	Time     *hexutil.Big    `json:"timestamp"`
+	Coinbase *common.Address `json:"coinbase"`
+}
+
+// apply replaces the fields of an EVM block context with the overridden ones.
+func (overrides *BlockOverrides) apply(context *vm.Context) {
+	if overrides == nil {
+		return
+	}
+	if overrides.Number != nil {
+		context.BlockNumber = new(big.Int).Set(overrides.Number.ToInt())
+	}
+	if overrides.Time != nil {
+		context.Time = new(big.Int).Set(overrides.Time.ToInt())
+	}
+	if overrides.Coinbase != nil {
+		context.Coinbase = *overrides.Coinbase
+	}
+}
+
+// PrepareCall applies the state overrides and converts the call arguments into
+// a message, funding its sender in the state the way eth_call executes it. The
+// sender is given an unlimited balance, unless its balance is overridden, in
+// which case it pays with that and only the gas is paid for by a temporary
+// credit.
+func PrepareCall(args CallArgs, state *state.StateDB, overrides *StateOverride, am *accounts.Manager) (types.Message, error) {
+	if err := overrides.Apply(state); err != nil {
+		return types.Message{}, err
+	}
+	msg := args.ToMessage(am)
+
+	if overrides != nil {
+		if account, ok := (*overrides)[msg.From()]; ok && account.Balance != nil {
+			creditGas(state, msg)
+			return msg, nil
+		}
+	}
+	state.SetBalance(msg.From(), math.MaxBig256)
+	return msg, nil
+}
+
+// creditGas tops up the balance of the sender of a message with its entire gas
+// allowance, so it needs no funds to pay for the gas.
+func creditGas(state *state.StateDB, msg types.Message) {
+	state.AddBalance(msg.From(), new(big.Int).Mul(new(big.Int).SetUint64(msg.Gas()), msg.GasPrice()))
+}
+
+// applyMessage executes a message on top of the given state, in the context of
+// the given header with the block overrides applied. The execution is aborted
+// if the context is cancelled.
+//
+// The sender pays with its balance in the state, which the caller is expected to
+// have funded, instead of the unlimited balance provided by the backend.
+func (s *PublicBlockChainAPI) applyMessage(ctx context.Context, msg types.Message, state *state.StateDB, header *types.Header, overrides *BlockOverrides, vmCfg vm.Config) ([]byte, uint64, bool, error) {
+	// Get a new instance of the EVM, keeping the balance of the sender
+	balance := new(big.Int).Set(state.GetBalance(msg.From()))
 	evm, vmError, err := s.b.GetEVM(ctx, msg, state, header, vmCfg)
 	if err != nil {
 		return nil, 0, false, err
 	}
+	state.SetBalance(msg.From(), balance)
+
+	overrides.apply(&evm.Context)
+
 	// Wait for the context to be done and cancel the evm. Even if the
 	// EVM has finished, cancelling may be done (repeatedly)
 	go func() {
@@ -673,16 +885,176 @@ func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr
 	return res, gas, failed, err
 }
 
+func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, overrides *StateOverride, vmCfg vm.Config) ([]byte, uint64, bool, error) {
+	defer func(start time.Time) { log.Debug("Executing EVM call finished", "runtime", time.Since(start)) }(time.Now())
+
+	state, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if state == nil || err != nil {
+		return nil, 0, false, err
+	}
+	// Create new call message
+	msg, err := PrepareCall(args, state, overrides, s.b.AccountManager())
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	// Setup context so it may be cancelled the call has completed
+	// or, in case of unmetered gas, setup a context with a timeout.
+	var cancel context.CancelFunc
+	if vmCfg.DisableGasMetering {
+		ctx, cancel = context.WithTimeout(ctx, time.Second*5)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	// Make sure the context is cancelled when the call has completed
+	// this makes sure resources are cleaned up.
+	defer func() { cancel() }()
+
+	return s.applyMessage(ctx, msg, state, header, nil, vmCfg)
+}
+
+// revertError is an API error carrying the return data of a reverted execution,
+// along with the decoded revert reason in the message if available.
+type revertError struct {
+	error
+	reason string // Hex encoded return data of the revert
+}
+
+// newRevertError creates a revertError from the return data of a reverted
+// execution, decoding its reason if it was reverted with one.
+func newRevertError(ret []byte) *revertError {
+	err := errors.New("execution reverted")
+	if reason, errUnpack := abi.UnpackRevert(ret); errUnpack == nil {
+		err = fmt.Errorf("execution reverted: %v", reason)
+	}
+	return &revertError{
+		error:  err,
+		reason: hexutil.Encode(ret),
+	}
+}
+
+// ErrorCode returns the JSON error code for a revertal.
+// See: https://github.com/ethereum/wiki/wiki/JSON-RPC-Error-Codes-Improvement-Proposal
+func (e *revertError) ErrorCode() int {
+	return 3
+}
+
+// ErrorData returns the hex encoded revert reason.
+func (e *revertError) ErrorData() interface{} {
+	return e.reason
+}
+
+// RevertReason decodes the reason of a reverted execution from its return data,
+// returning an empty string if it didn't revert with one.
+func RevertReason(ret []byte) string {
+	reason, _ := abi.UnpackRevert(ret)
+	return reason
+}
+
 // Call executes the given transaction on the state for the given block number.
 // It doesn't make and changes in the state/blockchain and is useful to execute and retrieve values.
-func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber) (hexutil.Bytes, error) {
-	result, _, _, err := s.doCall(ctx, args, blockNr, vm.Config{DisableGasMetering: true})
-	return (hexutil.Bytes)(result), err
+//
+// Optionally, the caller can specify a batch of account overrides, replacing the
+// code, balance, nonce or storage of accounts before the execution.
+func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, overrides *StateOverride) (hexutil.Bytes, error) {
+	result, _, failed, err := s.doCall(ctx, args, blockNr, overrides, vm.Config{DisableGasMetering: true})
+	if err != nil {
+		return nil, err
+	}
+	// If the execution reverted with some data, surface it as an error
+	if failed && len(result) > 0 {
+		return nil, newRevertError(result)
+	}
+	return (hexutil.Bytes)(result), nil
+}
+
+// CallResult is the outcome of a single call of a simulated bundle.
+type CallResult struct {
+	ReturnData hexutil.Bytes  `json:"returnData"`
+	GasUsed    hexutil.Uint64 `json:"gasUsed"`
+	Logs       []*types.Log   `json:"logs"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// CallMany executes an ordered list of calls on the state of the given block,
+// each of them seeing the state changes made by the previous ones, optionally
+// replacing fields of the block context. It doesn't make any changes in the
+// state/blockchain and is useful to simulate bundles of dependent transactions.
+//
+// A call failing validation (e.g. intrinsic gas too low) leaves the state intact
+// and is reported along with the failed executions, without aborting the rest.
+// The senders only need funds for the transferred values, the gas is free.
+func (s *PublicBlockChainAPI) CallMany(ctx context.Context, calls []CallArgs, blockNr rpc.BlockNumber, overrides *BlockOverrides) ([]*CallResult, error) {
+	defer func(start time.Time) { log.Debug("Executing EVM call bundle finished", "runtime", time.Since(start)) }(time.Now())
+
+	if len(calls) > maxBundleCalls {
+		return nil, fmt.Errorf("too many calls: have %d, max %d", len(calls), maxBundleCalls)
+	}
+	msgs := make([]types.Message, len(calls))
+	gas := uint64(0)
+	for i, args := range calls {
+		msgs[i] = args.ToMessage(s.b.AccountManager())
+		if gas += msgs[i].Gas(); gas > maxBundleGas {
+			return nil, fmt.Errorf("bundle gas allowance exceeds %d", maxBundleGas)
+		}
+	}
+	state, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	// Make sure the context is cancelled when the calls have completed
+	// this makes sure resources are cleaned up.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]*CallResult, len(calls))
+	for i, msg := range msgs {
+		// Derive a unique hash for the call to collect its logs with
+		var tx *types.Transaction
+		if msg.To() == nil {
+			tx = types.NewContractCreation(state.GetNonce(msg.From()), msg.Value(), msg.Gas(), msg.GasPrice(), msg.Data())
+		} else {
+			tx = types.NewTransaction(state.GetNonce(msg.From()), *msg.To(), msg.Value(), msg.Gas(), msg.GasPrice(), msg.Data())
+		}
+		state.Prepare(tx.Hash(), common.Hash{}, i)
+
+		// Pay for the gas with a temporary credit, withdrawing the unused part
+		snapshot := state.Snapshot()
+		creditGas(state, msg)
+
+		res, gas, failed, err := s.applyMessage(ctx, msg, state, header, overrides, vm.Config{})
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err == nil {
+			state.SubBalance(msg.From(), new(big.Int).Mul(new(big.Int).SetUint64(msg.Gas()-gas), msg.GasPrice()))
+		}
+		result := &CallResult{
+			ReturnData: res,
+			GasUsed:    hexutil.Uint64(gas),
+			Logs:       state.GetLogs(tx.Hash()),
+		}
+		switch {
+		case err != nil:
+			state.RevertToSnapshot(snapshot)
+			result.Logs, result.Error = nil, err.Error()
+		case failed && len(res) > 0:
+			result.Error = newRevertError(res).Error()
+		case failed:
+			result.Error = "execution failed"
+		}
+		if result.Logs == nil {
+			result.Logs = []*types.Log{}
+		}
+		results[i] = result
+	}
+	return results, nil
 }
 
 // EstimateGas returns an estimate of the amount of gas needed to execute the
-// given transaction against the current pending block.
-func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (hexutil.Uint64, error) {
+// given transaction against the current pending block, with the optional
+// account overrides applied.
+func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs, overrides *StateOverride) (hexutil.Uint64, error) {
 	// Binary search the gas requirement, as it may be higher than the amount used
 	var (
 		lo  uint64 = params.TxGas - 1
@@ -702,19 +1074,19 @@ func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (h
 	cap = hi
 
 	// Create a helper to check if a gas allowance results in an executable transaction
-	executable := func(gas uint64) bool {
+	executable := func(gas uint64) (bool, []byte) {
 		args.Gas = hexutil.Uint64(gas)
 
-		_, _, failed, err := s.doCall(ctx, args, rpc.PendingBlockNumber, vm.Config{})
+		res, _, failed, err := s.doCall(ctx, args, rpc.PendingBlockNumber, overrides, vm.Config{})
 		if err != nil || failed {
-			return false
+			return false, res
 		}
-		return true
+		return true, nil
 	}
 	// Execute the binary search and hone in on an executable gas limit
 	for lo+1 < hi {
 		mid := (hi + lo) / 2
-		if !executable(mid) {
+		if ok, _ := executable(mid); !ok {
 			lo = mid
 		} else {
 			hi = mid
@@ -722,74 +1094,16 @@ func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (h
 	}
 	// Reject the transaction as invalid if it still fails at the highest allowance
 	if hi == cap {
-		if !executable(hi) {
+		if ok, res := executable(hi); !ok {
+			if len(res) > 0 {
+				return 0, newRevertError(res)
+			}
 			return 0, fmt.Errorf("gas required exceeds allowance or always failing transaction")
 		}
 	}
 	return hexutil.Uint64(hi), nil
 }
 
-// ExecutionResult groups all structured logs emitted by the EVM
-// while replaying a transaction in debug mode as well as transaction
-// execution status, the amount of gas used and the return value
-type ExecutionResult struct {
-	Gas         uint64         `json:"gas"`
-	Failed      bool           `json:"failed"`
-	ReturnValue string         `json:"returnValue"`
-	StructLogs  []StructLogRes `json:"structLogs"`
-}
-
-// StructLogRes stores a structured log emitted by the EVM while replaying a
-// transaction in debug mode
-type StructLogRes struct {
-	Pc      uint64             `json:"pc"`
-	Op      string             `json:"op"`
-	Gas     uint64             `json:"gas"`
-	GasCost uint64             `json:"gasCost"`
-	Depth   int                `json:"depth"`
-	Error   error              `json:"error,omitempty"`
-	Stack   *[]string          `json:"stack,omitempty"`
-	Memory  *[]string          `json:"memory,omitempty"`
-	Storage *map[string]string `json:"storage,omitempty"`
-}
-
-// formatLogs formats EVM returned structured logs for json output
-func FormatLogs(logs []vm.StructLog) []StructLogRes {
-	formatted := make([]StructLogRes, len(logs))
-	for index, trace := range logs {
-		formatted[index] = StructLogRes{
-			Pc:      trace.Pc,
-			Op:      trace.Op.String(),
-			Gas:     trace.Gas,
-			GasCost: trace.GasCost,
-			Depth:   trace.Depth,
-			Error:   trace.Err,
-		}
-		if trace.Stack != nil {
-			stack := make([]string, len(trace.Stack))
-			for i, stackValue := range trace.Stack {
-				stack[i] = fmt.Sprintf("%x", math.PaddedBigBytes(stackValue, 32))
-			}
-			formatted[index].Stack = &stack
-		}
-		if trace.Memory != nil {
-			memory := make([]string, 0, (len(trace.Memory)+31)/32)
-			for i := 0; i+32 <= len(trace.Memory); i += 32 {
-				memory = append(memory, fmt.Sprintf("%x", trace.Memory[i:i+32]))
-			}
-			formatted[index].Memory = &memory
-		}
-		if trace.Storage != nil {
-			storage := make(map[string]string)
-			for i, storageValue := range trace.Storage {
-				storage[fmt.Sprintf("%x", i)] = fmt.Sprintf("%x", storageValue)
-			}
-			formatted[index].Storage = &storage
-		}
-	}
-	return formatted
-}
-
 // rpcOutputBlock converts the given block to the RPC output which depends on fullTx. If inclTx is true transactions are
 // returned. When fullTx is true the returned block contains full transaction details, otherwise it will only contain
 // transaction hashes.
@@ -897,8 +1211,8 @@ func newRPCTransaction(tx *types.Transaction, blockHash common.Hash, blockNumber
 	return result
 }
 
-// newRPCPendingTransaction returns a pending transaction that will serialize to the RPC representation
-func newRPCPendingTransaction(tx *types.Transaction) *RPCTransaction {
+// NewRPCPendingTransaction returns a pending transaction that will serialize to the RPC representation
+func NewRPCPendingTransaction(tx *types.Transaction) *RPCTransaction {
 	return newRPCTransaction(tx, common.Hash{}, 0, 0)
 }
 
@@ -1010,7 +1324,7 @@ func (s *PublicTransactionPoolAPI) GetTransactionByHash(ctx context.Context, has
 	}
 	// No finalized transaction, try to retrieve it from the pool
 	if tx := s.b.GetPoolTransaction(hash); tx != nil {
-		return newRPCPendingTransaction(tx)
+		return NewRPCPendingTransaction(tx)
 	}
 	// Transaction unknown, return as such
 	return nil
@@ -1242,6 +1556,39 @@ func (s *PublicTransactionPoolAPI) Sign(addr common.Address, data hexutil.Bytes)
 	return signature, err
 }
 
+// SignTypedData calculates an ECDSA signature for the EIP-712 typed structured
+// data message:
+// keccak256("\x19\x01" || domainSeparator || hashStruct(message)).
+//
+// Wallets able to show the typed data to the user sign it directly, otherwise
+// its hash is signed. The V value of the signature will be 27 or 28.
+//
+// The account associated with addr must be unlocked.
+func (s *PublicTransactionPoolAPI) SignTypedData(addr common.Address, typedData accounts.TypedData) (hexutil.Bytes, error) {
+	// Look up the wallet containing the requested signer
+	account := accounts.Account{Address: addr}
+
+	wallet, err := s.b.AccountManager().Find(account)
+	if err != nil {
+		return nil, err
+	}
+	// Sign the typed data with the wallet, falling back to signing its hash
+	var signature []byte
+	if signer, ok := wallet.(accounts.TypedDataSigner); ok {
+		signature, err = signer.SignTypedData(account, &typedData)
+	} else {
+		var hash []byte
+		if hash, err = typedData.SignHash(); err != nil {
+			return nil, err
+		}
+		signature, err = wallet.SignHash(account, hash)
+	}
+	if err == nil {
+		signature[64] += 27 // Transform V from 0/1 to 27/28 according to the yellow paper
+	}
+	return signature, err
+}
+
 // SignTransactionResult represents a RLP encoded signed transaction.
 type SignTransactionResult struct {
 	Raw hexutil.Bytes      `json:"raw"`
@@ -1291,7 +1638,7 @@ func (s *PublicTransactionPoolAPI) PendingTransactions() ([]*RPCTransaction, err
 		}
 		from, _ := types.Sender(signer, tx)
 		if _, err := s.b.AccountManager().Find(accounts.Account{Address: from}); err == nil {
-			transactions = append(transactions, newRPCPendingTransaction(tx))
+			transactions = append(transactions, NewRPCPendingTransaction(tx))
 		}
 	}
 	return transactions, nil
@@ -0,0 +1,281 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// testBackend is a Backend implementing only the methods needed by the tests,
+// panicking on everything else.
+type testBackend struct {
+	Backend
+
+	pending map[common.Address]types.Transactions
+	queued  map[common.Address]types.Transactions
+
+	pendFeed  event.Feed
+	queueFeed event.Feed
+	dropFeed  event.Feed
+
+	state  *state.StateDB // State returned for every block, modified in place
+	header *types.Header
+}
+
+// newTestState creates an empty state on top of an in-memory database.
+func newTestState() *state.StateDB {
+	db, _ := ethdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	return statedb
+}
+
+func (b *testBackend) AccountManager() *accounts.Manager { return nil }
+
+func (b *testBackend) StateAndHeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
+	return b.state, b.header, nil
+}
+
+// GetEVM mirrors the full node backend, funding the sender with an unlimited balance.
+func (b *testBackend) GetEVM(ctx context.Context, msg core.Message, state *state.StateDB, header *types.Header, vmCfg vm.Config) (*vm.EVM, func() error, error) {
+	state.SetBalance(msg.From(), math.MaxBig256)
+	context := core.NewEVMContext(msg, header, nil, &header.Coinbase)
+	return vm.NewEVM(context, state, params.TestChainConfig, vmCfg), state.Error, nil
+}
+
+func (b *testBackend) TxPoolContent() (map[common.Address]types.Transactions, map[common.Address]types.Transactions) {
+	return b.pending, b.queued
+}
+
+func (b *testBackend) SubscribeTxPreEvent(ch chan<- core.TxPreEvent) event.Subscription {
+	return b.pendFeed.Subscribe(ch)
+}
+
+func (b *testBackend) SubscribeTxQueuedEvent(ch chan<- core.TxQueuedEvent) event.Subscription {
+	return b.queueFeed.Subscribe(ch)
+}
+
+func (b *testBackend) SubscribeTxDropEvent(ch chan<- core.TxDropEvent) event.Subscription {
+	return b.dropFeed.Subscribe(ch)
+}
+
+// Tests that the transaction pool content stream sends a snapshot followed by
+// the pending, queued and dropped transactions, skipping the events already
+// reflected in the snapshot.
+func TestTxPoolContentStream(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+
+	txs := make([]*types.Transaction, 5)
+	for i := range txs {
+		txs[i], _ = types.SignTx(types.NewTransaction(uint64(i), common.Address{}, new(big.Int), 21000, big.NewInt(1), nil), types.HomesteadSigner{}, key)
+	}
+	backend := &testBackend{
+		pending: map[common.Address]types.Transactions{addr: {txs[0], txs[1]}},
+		queued:  map[common.Address]types.Transactions{addr: {txs[2]}},
+	}
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("txpool", NewPublicTxPoolAPI(backend)); err != nil {
+		t.Fatalf("failed to register API: %v", err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	updates := make(chan *TxPoolUpdate, 16)
+	sub, err := client.Subscribe(context.Background(), "txpool", updates, "contentStream")
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	// Feed an event already in the snapshot, a promotion, a new queued transaction
+	// and a drop. Every transaction gets a single event as the events of different
+	// types are not ordered relative to each other.
+	backend.pendFeed.Send(core.TxPreEvent{Tx: txs[0]})
+	backend.pendFeed.Send(core.TxPreEvent{Tx: txs[2]})
+	backend.queueFeed.Send(core.TxQueuedEvent{Tx: txs[3]})
+	backend.dropFeed.Send(core.TxDropEvent{Tx: txs[1], Reason: core.TxDropReplaced, Replacement: txs[4].Hash()})
+
+	snapshot := <-updates
+	if snapshot.Type != "snapshot" || len(snapshot.Content["pending"][addr.Hex()]) != 2 || len(snapshot.Content["queued"][addr.Hex()]) != 1 {
+		t.Fatalf("snapshot mismatch: have %+v", snapshot)
+	}
+	seen := make(map[string]*TxPoolUpdate)
+	for i := 0; i < 3; i++ {
+		select {
+		case update := <-updates:
+			seen[update.Type] = update
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		case <-time.After(time.Second):
+			t.Fatalf("update %d: timeout", i)
+		}
+	}
+	if update := seen["pending"]; update == nil || update.Transaction.Hash != txs[2].Hash() {
+		t.Errorf("promotion mismatch: have %+v, want %x", update, txs[2].Hash())
+	}
+	if update := seen["queued"]; update == nil || update.Transaction.Hash != txs[3].Hash() {
+		t.Errorf("queued mismatch: have %+v, want %x", update, txs[3].Hash())
+	}
+	if update := seen["dropped"]; update == nil || *update.Hash != txs[1].Hash() || update.Reason != "replaced" || *update.Replacement != txs[4].Hash() {
+		t.Errorf("drop mismatch: have %+v", update)
+	}
+	select {
+	case update := <-updates:
+		t.Errorf("unexpected update: %+v", update)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// Tests that the calls of a bundle see the real balances left by the previous
+// ones, instead of the unlimited funds used to pay for the gas.
+func TestCallManyBalances(t *testing.T) {
+	var (
+		alice = common.Address{0xaa}
+		bob   = common.Address{0xbb}
+		carol = common.Address{0xcc}
+		ether = big.NewInt(params.Ether)
+	)
+	backend := &testBackend{
+		state:  newTestState(),
+		header: &types.Header{Number: big.NewInt(1), Time: new(big.Int), Difficulty: new(big.Int), GasLimit: 8000000},
+	}
+	backend.state.SetBalance(alice, ether)
+	api := NewPublicBlockChainAPI(backend)
+
+	transfer := func(from, to common.Address, value *big.Int) CallArgs {
+		return CallArgs{From: from, To: &to, Gas: 21000, GasPrice: hexutil.Big(*big.NewInt(params.Shannon)), Value: hexutil.Big(*value)}
+	}
+	calls := []CallArgs{
+		transfer(alice, bob, new(big.Int).Div(ether, big.NewInt(2))),  // Bob receives 0.5 ether
+		transfer(bob, carol, new(big.Int).Div(ether, big.NewInt(10))), // Bob forwards 0.1 ether
+		transfer(bob, carol, ether),                                   // Bob can't afford 1 ether
+	}
+	results, err := api.CallMany(context.Background(), calls, rpc.LatestBlockNumber, nil)
+	if err != nil {
+		t.Fatalf("failed to execute bundle: %v", err)
+	}
+	for i, want := range []string{"", "", vm.ErrInsufficientBalance.Error()} {
+		if results[i].Error != want {
+			t.Errorf("call %d: error mismatch: have %q, want %q", i, results[i].Error, want)
+		}
+	}
+	balances := map[common.Address]*big.Int{
+		alice: new(big.Int).Div(ether, big.NewInt(2)),
+		bob:   new(big.Int).Div(new(big.Int).Mul(ether, big.NewInt(4)), big.NewInt(10)),
+		carol: new(big.Int).Div(ether, big.NewInt(10)),
+	}
+	for addr, want := range balances {
+		if have := backend.state.GetBalance(addr); have.Cmp(want) != 0 {
+			t.Errorf("balance mismatch for %x: have %v, want %v", addr, have, want)
+		}
+	}
+	// Ensure oversized bundles are rejected
+	if _, err := api.CallMany(context.Background(), make([]CallArgs, maxBundleCalls+1), rpc.LatestBlockNumber, nil); err == nil {
+		t.Errorf("too many calls accepted")
+	}
+	calls = []CallArgs{{From: alice, Gas: maxBundleGas / 2}, {From: alice, Gas: maxBundleGas/2 + 1}}
+	if _, err := api.CallMany(context.Background(), calls, rpc.LatestBlockNumber, nil); err == nil {
+		t.Errorf("too much gas accepted")
+	}
+}
+
+// Tests that eth_call applies the state overrides. The sender keeps the unlimited
+// funds paying for the call, unless its balance is explicitly overridden.
+func TestCallStateOverride(t *testing.T) {
+	var (
+		sender   = common.Address{0xaa}
+		contract = common.Address{0xc0}
+	)
+	// Contract returning the balance of its caller, followed by its storage slot 1
+	code := hexutil.Encode([]byte{
+		byte(vm.CALLER), byte(vm.BALANCE), byte(vm.PUSH1), 0, byte(vm.MSTORE),
+		byte(vm.PUSH1), 1, byte(vm.SLOAD), byte(vm.PUSH1), 32, byte(vm.MSTORE),
+		byte(vm.PUSH1), 64, byte(vm.PUSH1), 0, byte(vm.RETURN),
+	})
+	tests := []struct {
+		overrides map[string]interface{}
+		balance   *big.Int
+		slot      common.Hash
+		fail      bool
+	}{
+		{
+			overrides: map[string]interface{}{contract.Hex(): map[string]interface{}{"code": code}},
+			balance:   new(big.Int).Sub(math.MaxBig256, big.NewInt(50000000)), // Default gas allowance prepaid
+		},
+		{
+			overrides: map[string]interface{}{
+				sender.Hex():   map[string]interface{}{"balance": "0x3e8"},
+				contract.Hex(): map[string]interface{}{"code": code, "stateDiff": map[string]interface{}{common.Hash{31: 1}.Hex(): common.Hash{2}.Hex()}},
+			},
+			balance: big.NewInt(1000),
+			slot:    common.Hash{2},
+		},
+		{
+			overrides: map[string]interface{}{contract.Hex(): map[string]interface{}{"code": code, "state": map[string]interface{}{}, "stateDiff": map[string]interface{}{}}},
+			fail:      true,
+		},
+	}
+	for i, tt := range tests {
+		backend := &testBackend{
+			state:  newTestState(),
+			header: &types.Header{Number: big.NewInt(1), Time: new(big.Int), Difficulty: new(big.Int), GasLimit: 8000000},
+		}
+		server := rpc.NewServer()
+		if err := server.RegisterName("eth", NewPublicBlockChainAPI(backend)); err != nil {
+			t.Fatalf("failed to register API: %v", err)
+		}
+		client := rpc.DialInProc(server)
+
+		args := map[string]interface{}{"from": sender, "to": contract, "gasPrice": "0x1"}
+		var result hexutil.Bytes
+		err := client.Call(&result, "eth_call", args, "latest", tt.overrides)
+		client.Close()
+		server.Stop()
+
+		switch {
+		case tt.fail && err == nil:
+			t.Errorf("test %d: conflicting overrides accepted", i)
+		case !tt.fail && err != nil:
+			t.Errorf("test %d: call failed: %v", i, err)
+		case !tt.fail:
+			if have := new(big.Int).SetBytes(result[:32]); have.Cmp(tt.balance) != 0 {
+				t.Errorf("test %d: sender balance mismatch: have %v, want %v", i, have, tt.balance)
+			}
+			if have := common.BytesToHash(result[32:]); have != tt.slot {
+				t.Errorf("test %d: storage mismatch: have %x, want %x", i, have, tt.slot)
+			}
+		}
+	}
+}
@@ -66,6 +66,8 @@ type Backend interface {
 	Stats() (pending int, queued int)
 	TxPoolContent() (map[common.Address]types.Transactions, map[common.Address]types.Transactions)
 	SubscribeTxPreEvent(chan<- core.TxPreEvent) event.Subscription
+	SubscribeTxDropEvent(chan<- core.TxDropEvent) event.Subscription
+	SubscribeTxQueuedEvent(chan<- core.TxQueuedEvent) event.Subscription
 
 	ChainConfig() *params.ChainConfig
 	CurrentBlock() *types.Block
@@ -73,6 +75,7 @@ type Backend interface {
 
 func GetAPIs(apiBackend Backend) []rpc.API {
 	nonceLock := new(AddrLocker)
+	abiRegistry := newABIRegistry(apiBackend.ChainDb())
 	return []rpc.API{
 		{
 			Namespace: "eth",
@@ -113,6 +116,16 @@ func GetAPIs(apiBackend Backend) []rpc.API {
 			Version:   "1.0",
 			Service:   NewPrivateAccountAPI(apiBackend, nonceLock),
 			Public:    false,
+		}, {
+			Namespace: "abi",
+			Version:   "1.0",
+			Service:   newPublicABIAPI(apiBackend, abiRegistry),
+			Public:    true,
+		}, {
+			Namespace: "abiadmin",
+			Version:   "1.0",
+			Service:   newPrivateABIAPI(abiRegistry),
+			Public:    false,
 		},
 	}
 }
@@ -18,6 +18,8 @@
 package web3ext
 
 var Modules = map[string]string{
+	"abi":        ABI_JS,
+	"abiadmin":   ABIAdmin_JS,
 	"admin":      Admin_JS,
 	"chequebook": Chequebook_JS,
 	"clique":     Clique_JS,
@@ -342,6 +344,12 @@ web3._extend({
 			params: 2,
 			inputFormatter: [null, null]
 		}),
+		new web3._extend.Method({
+			name: 'traceCall',
+			call: 'debug_traceCall',
+			params: 4,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter, null, null]
+		}),
 		new web3._extend.Method({
 			name: 'preimage',
 			call: 'debug_preimage',
@@ -397,6 +405,12 @@ web3._extend({
 			params: 1,
 			inputFormatter: [web3._extend.formatters.inputTransactionFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'signTypedData',
+			call: 'eth_signTypedData',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null]
+		}),
 		new web3._extend.Method({
 			name: 'submitTransaction',
 			call: 'eth_submitTransaction',
@@ -416,6 +430,24 @@ web3._extend({
 			params: 2,
 			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, web3._extend.utils.toHex]
 		}),
+		new web3._extend.Method({
+			name: 'callMany',
+			call: 'eth_callMany',
+			params: 3,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'gasPriceHistogram',
+			call: 'eth_gasPriceHistogram',
+			params: 1,
+			inputFormatter: [web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'feeEstimate',
+			call: 'eth_feeEstimate',
+			params: 1,
+			inputFormatter: [web3._extend.utils.fromDecimal]
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -616,3 +648,53 @@ web3._extend({
 	]
 });
 `
+
+const ABIAdmin_JS = `
+web3._extend({
+	property: 'abiadmin',
+	methods: [
+		new web3._extend.Method({
+			name: 'registerABI',
+			call: 'abiadmin_registerABI',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'unregisterABI',
+			call: 'abiadmin_unregisterABI',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter]
+		}),
+	]
+});
+`
+
+const ABI_JS = `
+web3._extend({
+	property: 'abi',
+	methods: [
+		new web3._extend.Method({
+			name: 'getABI',
+			call: 'abi_getABI',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'decodeInput',
+			call: 'abi_decodeInput',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'decodeTransaction',
+			call: 'abi_decodeTransaction',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'decodeLogs',
+			call: 'abi_decodeLogs',
+			params: 1
+		}),
+	]
+});
+`
@@ -192,6 +192,11 @@ func (s *LightEthereum) APIs() []rpc.API {
 			Version:   "1.0",
 			Service:   filters.NewPublicFilterAPI(s.ApiBackend, true),
 			Public:    true,
+		}, {
+			Namespace: "eth",
+			Version:   "1.0",
+			Service:   gasprice.NewPublicGasPriceAPI(s.ApiBackend.gpo),
+			Public:    true,
 		}, {
 			Namespace: "net",
 			Version:   "1.0",
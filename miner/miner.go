@@ -57,12 +57,17 @@ type Miner struct {
 	shouldStart int32 // should start indicates whether we should start after sync
 }
 
-func New(eth Backend, config *params.ChainConfig, mux *event.TypeMux, engine consensus.Engine) *Miner {
+// New creates a miner including the pending transactions into its blocks in the
+// order defined by the given strategy, defaulting to the highest gas price first.
+func New(eth Backend, config *params.ChainConfig, mux *event.TypeMux, engine consensus.Engine, ordering OrderingStrategy) *Miner {
+	if ordering == nil {
+		ordering = PriceOrdering{}
+	}
 	miner := &Miner{
 		eth:      eth,
 		mux:      mux,
 		engine:   engine,
-		worker:   newWorker(config, engine, common.Address{}, eth, mux),
+		worker:   newWorker(config, engine, ordering, common.Address{}, eth, mux),
 		canStart: 1,
 	}
 	miner.Register(NewCpuAgent(eth.BlockChain(), engine))
@@ -0,0 +1,224 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"container/heap"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Names of the built-in transaction ordering strategies.
+const (
+	OrderByPrice    = "price"    // Highest gas price first (default)
+	OrderByArrival  = "fifo"     // Earliest arrival first
+	OrderByFairness = "fair"     // Round robin between senders, highest gas price first within a round
+	OrderByPriority = "priority" // Priority senders first, highest gas price first otherwise
+)
+
+// TransactionSet is a set of pending transactions retrieved one by one in the
+// order they are to be included into a block, honouring the account nonces.
+type TransactionSet interface {
+	// Peek returns the next transaction to include, or nil if the set is exhausted.
+	Peek() *types.Transaction
+
+	// Shift replaces the next transaction with the following one from the same
+	// account.
+	Shift()
+
+	// Pop removes the next transaction, *not* replacing it with the following
+	// one from the same account. This should be used when a transaction cannot
+	// be executed and hence all subsequent ones should be discarded.
+	Pop()
+}
+
+// OrderingStrategy decides the order in which pending transactions are included
+// into the blocks created by the miner.
+type OrderingStrategy interface {
+	// Order creates the ordered set of the given pending transactions, which are
+	// nonce sorted per account. The input map is reowned by the returned set.
+	Order(pending map[common.Address]types.Transactions) TransactionSet
+}
+
+// NewOrderingStrategy creates one of the built-in ordering strategies by name.
+// The priority senders are only used by the priority strategy. An empty name
+// selects the default price ordering.
+func NewOrderingStrategy(name string, priority []common.Address) (OrderingStrategy, error) {
+	switch name {
+	case "", OrderByPrice:
+		return PriceOrdering{}, nil
+	case OrderByArrival:
+		return ArrivalOrdering{}, nil
+	case OrderByFairness:
+		return FairOrdering{}, nil
+	case OrderByPriority:
+		return NewPriorityOrdering(priority), nil
+	default:
+		return nil, fmt.Errorf("unknown transaction ordering %q", name)
+	}
+}
+
+// PriceOrdering includes the transactions paying the highest gas price first,
+// maximizing the profit of the miner.
+type PriceOrdering struct{}
+
+// Order implements OrderingStrategy.
+func (PriceOrdering) Order(pending map[common.Address]types.Transactions) TransactionSet {
+	return newOrderedTransactions(pending, func(a, b *txHead) bool {
+		return a.tx.GasPrice().Cmp(b.tx.GasPrice()) > 0
+	})
+}
+
+// ArrivalOrdering includes the transactions in the order they were accepted by
+// the local transaction pool, falling back to the gas price for transactions
+// accepted at the same time.
+type ArrivalOrdering struct{}
+
+// Order implements OrderingStrategy.
+func (ArrivalOrdering) Order(pending map[common.Address]types.Transactions) TransactionSet {
+	return newOrderedTransactions(pending, func(a, b *txHead) bool {
+		if at, bt := a.tx.Time(), b.tx.Time(); !at.Equal(bt) {
+			return at.Before(bt)
+		}
+		return a.tx.GasPrice().Cmp(b.tx.GasPrice()) > 0
+	})
+}
+
+// FairOrdering includes the transactions in rounds, each sender getting one of
+// its transactions included per round, so a single sender cannot crowd out the
+// others. Within a round, transactions are ordered by gas price.
+type FairOrdering struct{}
+
+// Order implements OrderingStrategy.
+func (FairOrdering) Order(pending map[common.Address]types.Transactions) TransactionSet {
+	return newOrderedTransactions(pending, func(a, b *txHead) bool {
+		if a.taken != b.taken {
+			return a.taken < b.taken
+		}
+		return a.tx.GasPrice().Cmp(b.tx.GasPrice()) > 0
+	})
+}
+
+// PriorityOrdering includes the transactions of a set of priority senders ahead
+// of all others, ordering both groups by gas price.
+type PriorityOrdering struct {
+	senders map[common.Address]struct{}
+}
+
+// NewPriorityOrdering creates an ordering strategy prioritizing the given senders.
+func NewPriorityOrdering(senders []common.Address) *PriorityOrdering {
+	ordering := &PriorityOrdering{senders: make(map[common.Address]struct{})}
+	for _, sender := range senders {
+		ordering.senders[sender] = struct{}{}
+	}
+	return ordering
+}
+
+// Order implements OrderingStrategy.
+func (o *PriorityOrdering) Order(pending map[common.Address]types.Transactions) TransactionSet {
+	return newOrderedTransactions(pending, func(a, b *txHead) bool {
+		_, ap := o.senders[a.from]
+		_, bp := o.senders[b.from]
+		if ap != bp {
+			return ap
+		}
+		return a.tx.GasPrice().Cmp(b.tx.GasPrice()) > 0
+	})
+}
+
+// txHead is the next transaction of an account in an ordered set.
+type txHead struct {
+	tx    *types.Transaction
+	from  common.Address
+	taken int // Number of transactions already taken from the account
+}
+
+// txHeads is a heap of account heads sorted by an ordering function.
+type txHeads struct {
+	heads []*txHead
+	less  func(a, b *txHead) bool
+}
+
+func (h *txHeads) Len() int           { return len(h.heads) }
+func (h *txHeads) Less(i, j int) bool { return h.less(h.heads[i], h.heads[j]) }
+func (h *txHeads) Swap(i, j int)      { h.heads[i], h.heads[j] = h.heads[j], h.heads[i] }
+
+func (h *txHeads) Push(x interface{}) {
+	h.heads = append(h.heads, x.(*txHead))
+}
+
+func (h *txHeads) Pop() interface{} {
+	old := h.heads
+	n := len(old)
+	x := old[n-1]
+	h.heads = old[0 : n-1]
+	return x
+}
+
+// orderedTransactions is a TransactionSet retrieving the next transaction of the
+// accounts in the order defined by a comparison function.
+type orderedTransactions struct {
+	txs   map[common.Address]types.Transactions // Per account nonce-sorted list of remaining transactions
+	heads *txHeads                              // Next transaction for each unique account
+}
+
+// newOrderedTransactions creates a transaction set ordering the account heads
+// according to the given function, reowning the input map.
+func newOrderedTransactions(pending map[common.Address]types.Transactions, less func(a, b *txHead) bool) *orderedTransactions {
+	heads := &txHeads{heads: make([]*txHead, 0, len(pending)), less: less}
+	for from, txs := range pending {
+		if len(txs) == 0 {
+			delete(pending, from)
+			continue
+		}
+		heads.heads = append(heads.heads, &txHead{tx: txs[0], from: from})
+		pending[from] = txs[1:]
+	}
+	heap.Init(heads)
+
+	return &orderedTransactions{
+		txs:   pending,
+		heads: heads,
+	}
+}
+
+// Peek implements TransactionSet.
+func (t *orderedTransactions) Peek() *types.Transaction {
+	if t.heads.Len() == 0 {
+		return nil
+	}
+	return t.heads.heads[0].tx
+}
+
+// Shift implements TransactionSet.
+func (t *orderedTransactions) Shift() {
+	head := t.heads.heads[0]
+	if txs := t.txs[head.from]; len(txs) > 0 {
+		head.tx, t.txs[head.from] = txs[0], txs[1:]
+		head.taken++
+		heap.Fix(t.heads, 0)
+	} else {
+		heap.Pop(t.heads)
+	}
+}
+
+// Pop implements TransactionSet.
+func (t *orderedTransactions) Pop() {
+	heap.Pop(t.heads)
+}
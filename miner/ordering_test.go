@@ -0,0 +1,274 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/clique"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	orderingSigner = types.HomesteadSigner{}
+	orderingClock  = time.Now() // Arrival time of the last created transaction
+)
+
+// orderingAccount is a test account sending transactions to be ordered.
+type orderingAccount struct {
+	key  *ecdsa.PrivateKey
+	addr common.Address
+}
+
+func newOrderingAccount() *orderingAccount {
+	key, _ := crypto.GenerateKey()
+	return &orderingAccount{key: key, addr: crypto.PubkeyToAddress(key.PublicKey)}
+}
+
+// transaction creates a signed transaction of the account, arriving a bit later
+// than the previously created one.
+func (a *orderingAccount) transaction(nonce uint64, price int64) *types.Transaction {
+	tx, _ := types.SignTx(types.NewTransaction(nonce, common.Address{}, big.NewInt(0), params.TxGas, big.NewInt(price), nil), orderingSigner, a.key)
+
+	orderingClock = orderingClock.Add(time.Millisecond)
+	tx.SetTime(orderingClock)
+	return tx
+}
+
+// collectTransactions retrieves all the transactions from a set in order.
+func collectTransactions(set TransactionSet) []*types.Transaction {
+	var txs []*types.Transaction
+	for tx := set.Peek(); tx != nil; tx = set.Peek() {
+		txs = append(txs, tx)
+		set.Shift()
+	}
+	return txs
+}
+
+// checkTransactionOrder verifies that the given transactions match the wanted order.
+func checkTransactionOrder(t *testing.T, have []*types.Transaction, want []*types.Transaction) {
+	t.Helper()
+
+	if len(have) != len(want) {
+		t.Fatalf("transaction count mismatch: have %d, want %d", len(have), len(want))
+	}
+	for i := range want {
+		if have[i].Hash() != want[i].Hash() {
+			t.Errorf("transaction %d mismatch: have nonce %d price %v, want nonce %d price %v",
+				i, have[i].Nonce(), have[i].GasPrice(), want[i].Nonce(), want[i].GasPrice())
+		}
+	}
+}
+
+// Tests that the built-in strategies can be looked up by name.
+func TestNewOrderingStrategy(t *testing.T) {
+	tests := []struct {
+		name string
+		want OrderingStrategy
+	}{
+		{"", PriceOrdering{}},
+		{OrderByPrice, PriceOrdering{}},
+		{OrderByArrival, ArrivalOrdering{}},
+		{OrderByFairness, FairOrdering{}},
+	}
+	for _, tt := range tests {
+		ordering, err := NewOrderingStrategy(tt.name, nil)
+		if err != nil {
+			t.Errorf("ordering %q: failed to create: %v", tt.name, err)
+		} else if ordering != tt.want {
+			t.Errorf("ordering %q: strategy mismatch: have %T, want %T", tt.name, ordering, tt.want)
+		}
+	}
+	if ordering, err := NewOrderingStrategy(OrderByPriority, nil); err != nil {
+		t.Errorf("priority ordering: failed to create: %v", err)
+	} else if _, ok := ordering.(*PriorityOrdering); !ok {
+		t.Errorf("priority ordering: strategy mismatch: have %T", ordering)
+	}
+	if _, err := NewOrderingStrategy("random", nil); err == nil {
+		t.Errorf("unknown ordering accepted")
+	}
+}
+
+// Tests that the arrival ordering includes transactions first come first
+// served, regardless of their price, while honouring the nonces.
+func TestArrivalOrdering(t *testing.T) {
+	a, b := newOrderingAccount(), newOrderingAccount()
+
+	a0 := a.transaction(0, 1)
+	b0 := b.transaction(0, 10)
+	b1 := b.transaction(1, 10)
+	a1 := a.transaction(1, 100)
+
+	// Pending transactions are nonce sorted per account, the later arrival of a
+	// lower nonce must delay the higher ones too
+	c := newOrderingAccount()
+	c1 := c.transaction(1, 1000)
+	c0 := c.transaction(0, 1)
+
+	set := ArrivalOrdering{}.Order(map[common.Address]types.Transactions{
+		a.addr: {a0, a1},
+		b.addr: {b0, b1},
+		c.addr: {c0, c1},
+	})
+	checkTransactionOrder(t, collectTransactions(set), []*types.Transaction{a0, b0, b1, a1, c0, c1})
+}
+
+// Tests that the fair ordering includes a transaction of every sender before
+// including the next ones of a sender.
+func TestFairOrdering(t *testing.T) {
+	a, b, c := newOrderingAccount(), newOrderingAccount(), newOrderingAccount()
+
+	a0, a1, a2 := a.transaction(0, 100), a.transaction(1, 100), a.transaction(2, 100)
+	b0, b1 := b.transaction(0, 10), b.transaction(1, 10)
+	c0 := c.transaction(0, 1)
+
+	set := FairOrdering{}.Order(map[common.Address]types.Transactions{
+		a.addr: {a0, a1, a2},
+		b.addr: {b0, b1},
+		c.addr: {c0},
+	})
+	checkTransactionOrder(t, collectTransactions(set), []*types.Transaction{a0, b0, c0, a1, b1, a2})
+}
+
+// Tests that the priority ordering includes the transactions of the priority
+// senders first, ordering the rest by price.
+func TestPriorityOrdering(t *testing.T) {
+	a, b, c := newOrderingAccount(), newOrderingAccount(), newOrderingAccount()
+
+	a0, a1 := a.transaction(0, 1), a.transaction(1, 1)
+	b0 := b.transaction(0, 100)
+	c0 := c.transaction(0, 10)
+
+	set := NewPriorityOrdering([]common.Address{a.addr}).Order(map[common.Address]types.Transactions{
+		a.addr: {a0, a1},
+		b.addr: {b0},
+		c.addr: {c0},
+	})
+	checkTransactionOrder(t, collectTransactions(set), []*types.Transaction{a0, a1, b0, c0})
+}
+
+// Tests that popping a transaction discards the rest of the account.
+func TestOrderedTransactionsPop(t *testing.T) {
+	a, b := newOrderingAccount(), newOrderingAccount()
+
+	a0, a1 := a.transaction(0, 100), a.transaction(1, 100)
+	b0 := b.transaction(0, 1)
+
+	set := FairOrdering{}.Order(map[common.Address]types.Transactions{
+		a.addr: {a0, a1},
+		b.addr: {b0},
+	})
+	if tx := set.Peek(); tx != a0 {
+		t.Fatalf("first transaction mismatch: have %x, want %x", tx.Hash(), a0.Hash())
+	}
+	set.Pop()
+	checkTransactionOrder(t, collectTransactions(set), []*types.Transaction{b0})
+}
+
+// orderingBackend is a miner backend over a clique chain for testing the block
+// assembly of the worker.
+type orderingBackend struct {
+	db     ethdb.Database
+	chain  *core.BlockChain
+	txPool *core.TxPool
+}
+
+func (b *orderingBackend) AccountManager() *accounts.Manager { return nil }
+func (b *orderingBackend) BlockChain() *core.BlockChain     { return b.chain }
+func (b *orderingBackend) TxPool() *core.TxPool             { return b.txPool }
+func (b *orderingBackend) ChainDb() ethdb.Database          { return b.db }
+
+// newOrderingBackend creates a clique chain signed by the given account, funding
+// all the given accounts.
+func newOrderingBackend(t *testing.T, signer common.Address, funded ...*orderingAccount) (*orderingBackend, *params.ChainConfig, *clique.Clique) {
+	config := *params.AllCliqueProtocolChanges
+	config.Clique = &params.CliqueConfig{Period: 1, Epoch: 30000}
+
+	genesis := &core.Genesis{
+		Config:    &config,
+		GasLimit:  params.GenesisGasLimit,
+		ExtraData: make([]byte, 32+common.AddressLength+65),
+		Alloc:     make(core.GenesisAlloc),
+	}
+	copy(genesis.ExtraData[32:], signer[:])
+	for _, account := range funded {
+		genesis.Alloc[account.addr] = core.GenesisAccount{Balance: big.NewInt(params.Ether)}
+	}
+	db, _ := ethdb.NewMemDatabase()
+	genesis.MustCommit(db)
+
+	engine := clique.New(config.Clique, db)
+	chain, err := core.NewBlockChain(db, nil, &config, engine, vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	poolConfig := core.DefaultTxPoolConfig
+	poolConfig.Journal = ""
+
+	return &orderingBackend{
+		db:     db,
+		chain:  chain,
+		txPool: core.NewTxPool(poolConfig, &config, chain),
+	}, &config, engine
+}
+
+// Tests that the worker assembles the pending block of a clique chain in the
+// order of the configured strategy.
+func TestWorkerOrdering(t *testing.T) {
+	a, b := newOrderingAccount(), newOrderingAccount()
+
+	backend, config, engine := newOrderingBackend(t, a.addr, a, b)
+	defer backend.chain.Stop()
+	defer backend.txPool.Stop()
+
+	a0, a1, a2 := a.transaction(0, 100), a.transaction(1, 100), a.transaction(2, 100)
+	b0 := b.transaction(0, 1)
+	for _, err := range backend.txPool.AddRemotes([]*types.Transaction{a0, a1, a2, b0}) {
+		if err != nil {
+			t.Fatalf("failed to add transaction: %v", err)
+		}
+	}
+	// Assemble the pending blocks with both the default and the fair ordering
+	tests := []struct {
+		ordering OrderingStrategy
+		want     []*types.Transaction
+	}{
+		{PriceOrdering{}, []*types.Transaction{a0, a1, a2, b0}},
+		{FairOrdering{}, []*types.Transaction{a0, b0, a1, a2}},
+	}
+	for i, tt := range tests {
+		w := newWorker(config, engine, tt.ordering, a.addr, backend, new(event.TypeMux))
+		w.txSub.Unsubscribe()
+
+		block, _ := w.pending()
+		checkTransactionOrder(t, block.Transactions(), tt.want)
+		if t.Failed() {
+			t.Fatalf("test %d: pending block order mismatch", i)
+		}
+	}
+}
@@ -18,7 +18,9 @@ package node
 
 import (
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -26,6 +28,7 @@ import (
 	"strings"
 
 	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/external"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/accounts/usbwallet"
 	"github.com/ethereum/go-ethereum/common"
@@ -38,6 +41,7 @@ import (
 const (
 	datadirPrivateKey      = "nodekey"            // Path within the datadir to the node's private key
 	datadirDefaultKeyStore = "keystore"           // Path within the datadir to the keystore
+	datadirKeyStoreDB      = "keystore.db"        // Path within the datadir to the keystore secret database
 	datadirStaticNodes     = "static-nodes.json"  // Path within the datadir to the static node list
 	datadirTrustedNodes    = "trusted-nodes.json" // Path within the datadir to the trusted node list
 	datadirNodeDatabase    = "nodes"              // Path within the datadir to store the node infos
@@ -82,9 +86,35 @@ type Config struct {
 	// scrypt KDF at the expense of security.
 	UseLightweightKDF bool `toml:",omitempty"`
 
+	// KeyStoreSecrets is the secret store the keys are persisted into instead of
+	// KeyStoreDir, e.g. an encrypted database or a Vault server. If nil, the store
+	// configured by KeyStoreBackend is used.
+	KeyStoreSecrets keystore.SecretStore `toml:"-"`
+
+	// KeyStoreBackend selects where the keys are persisted if KeyStoreSecrets is
+	// nil: "file" (or empty) for key files in KeyStoreDir, "db" for an encrypted
+	// database, or "vault" for a Vault server. HD wallets are only supported by
+	// the file backend, as their seeds are always kept in KeyStoreDir.
+	KeyStoreBackend string `toml:",omitempty"`
+
+	// KeyStoreURL is the database path of the "db" backend, resolved relative to
+	// DataDir and defaulting to "keystore.db" within it, or the secrets path
+	// endpoint of the "vault" backend.
+	KeyStoreURL string `toml:",omitempty"`
+
+	// KeyStoreToken is the database passphrase of the "db" backend, or the access
+	// token of the "vault" backend. It is never saved into config files, but is
+	// loaded from the file given by --keystore.tokenfile instead.
+	KeyStoreToken string `toml:"-"`
+
 	// NoUSB disables hardware wallet monitoring and connectivity.
 	NoUSB bool `toml:",omitempty"`
 
+	// ExternalSigner is the IPC path or HTTP URL of an external signer to delegate
+	// transaction signing to, keeping the keys out of the node. If empty, no
+	// external signer is used.
+	ExternalSigner string `toml:",omitempty"`
+
 	// IPCPath is the requested location to place the IPC endpoint. If the path is
 	// a simple file name, it is placed inside the data directory (or on the root
 	// pipe path on Windows), whereas if it's a resolvable path name (absolute or
@@ -391,7 +421,47 @@ func (c *Config) AccountConfig() (int, int, string, error) {
 	return scryptN, scryptP, keydir, err
 }
 
-func makeAccountManager(conf *Config) (*accounts.Manager, string, error) {
+// SecretStore returns the secret store the keys are persisted into, opening the
+// one configured by KeyStoreBackend unless KeyStoreSecrets is set. Nil is returned
+// if the keys are stored as files.
+func (c *Config) SecretStore() (keystore.SecretStore, error) {
+	if c.KeyStoreSecrets != nil {
+		return c.KeyStoreSecrets, nil
+	}
+	switch c.KeyStoreBackend {
+	case "", "file":
+		return nil, nil
+
+	case "db":
+		path := c.KeyStoreURL
+		switch {
+		case path == "" && c.DataDir == "":
+			return nil, errors.New("db key store requires a path without a data directory")
+		case path == "":
+			path = filepath.Join(c.DataDir, datadirKeyStoreDB)
+		case !filepath.IsAbs(path) && c.DataDir != "":
+			path = filepath.Join(c.DataDir, path)
+		}
+		scryptN, scryptP, _, err := c.AccountConfig()
+		if err != nil {
+			return nil, err
+		}
+		return keystore.NewDBSecretStore(path, c.KeyStoreToken, scryptN, scryptP)
+
+	case "vault":
+		if c.KeyStoreURL == "" {
+			return nil, errors.New("vault key store requires an endpoint")
+		}
+		return keystore.NewVaultSecretStore(c.KeyStoreURL, c.KeyStoreToken), nil
+
+	default:
+		return nil, fmt.Errorf("unknown key store backend %q", c.KeyStoreBackend)
+	}
+}
+
+// makeAccountManager assembles the account manager of the node, returning the
+// ephemeral key directory to remove and the secret store to close on shutdown.
+func makeAccountManager(conf *Config) (*accounts.Manager, string, io.Closer, error) {
 	scryptN, scryptP, keydir, err := conf.AccountConfig()
 	var ephemeral string
 	if keydir == "" {
@@ -401,14 +471,33 @@ func makeAccountManager(conf *Config) (*accounts.Manager, string, error) {
 	}
 
 	if err != nil {
-		return nil, "", err
+		return nil, "", nil, err
 	}
 	if err := os.MkdirAll(keydir, 0700); err != nil {
-		return nil, "", err
+		return nil, "", nil, err
+	}
+	store, err := conf.SecretStore()
+	if err != nil {
+		return nil, "", nil, err
+	}
+	// Only close the stores opened from the config, not the user provided ones
+	var closer io.Closer
+	if c, ok := store.(io.Closer); ok && conf.KeyStoreSecrets == nil {
+		closer = c
 	}
 	// Assemble the account manager and supported backends
-	backends := []accounts.Backend{
-		keystore.NewKeyStore(keydir, scryptN, scryptP),
+	var ks *keystore.KeyStore
+	if store != nil {
+		ks = keystore.NewSecretKeyStore(store, scryptN, scryptP)
+	} else {
+		ks = keystore.NewKeyStore(keydir, scryptN, scryptP)
+	}
+	backends := []accounts.Backend{ks}
+
+	// HD wallet seeds are persisted as key files, so only offer them if the keys
+	// themselves are, otherwise they would silently bypass the secret store.
+	if store == nil {
+		backends = append(backends, keystore.NewHDKeyStore(keydir, scryptN, scryptP))
 	}
 	if !conf.NoUSB {
 		// Start a USB hub for Ledger hardware wallets
@@ -424,5 +513,15 @@ func makeAccountManager(conf *Config) (*accounts.Manager, string, error) {
 			backends = append(backends, trezorhub)
 		}
 	}
-	return accounts.NewManager(backends...), ephemeral, nil
+	if conf.ExternalSigner != "" {
+		extapi, err := external.NewExternalBackend(conf.ExternalSigner)
+		if err != nil {
+			if closer != nil {
+				closer.Close()
+			}
+			return nil, "", nil, err
+		}
+		backends = append(backends, extapi)
+	}
+	return accounts.NewManager(backends...), ephemeral, closer, nil
 }
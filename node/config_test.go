@@ -18,12 +18,14 @@ package node
 
 import (
 	"bytes"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"runtime"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/p2p"
 )
@@ -148,3 +150,69 @@ func TestNodeKeyPersistency(t *testing.T) {
 		t.Fatalf("ephemeral node key persisted to disk")
 	}
 }
+
+// Tests that the key secret store is opened from the configured backend.
+func TestSecretStoreBackend(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary data directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// File backed keys don't need a secret store
+	if store, err := (&Config{DataDir: dir}).SecretStore(); store != nil || err != nil {
+		t.Fatalf("file backend mismatch: have %v, %v, want nil", store, err)
+	}
+	// Database backed keys should default to a database within the datadir
+	config := &Config{DataDir: dir, KeyStoreBackend: "db", KeyStoreToken: "secret", UseLightweightKDF: true}
+	store, err := config.SecretStore()
+	if err != nil {
+		t.Fatalf("failed to open database secret store: %v", err)
+	}
+	store.(io.Closer).Close()
+	if _, err := os.Stat(filepath.Join(dir, datadirKeyStoreDB)); err != nil {
+		t.Fatalf("secret database not created in datadir: %v", err)
+	}
+	// Invalid configurations should be rejected
+	for i, config := range []*Config{
+		{KeyStoreBackend: "db"},
+		{KeyStoreBackend: "vault"},
+		{KeyStoreBackend: "ldap"},
+	} {
+		if _, err := config.SecretStore(); err == nil {
+			t.Errorf("test %d: invalid config %q accepted", i, config.KeyStoreBackend)
+		}
+	}
+}
+
+// Tests that HD wallets are only offered by file backed key stores, as their
+// seeds would otherwise bypass the configured secret store.
+func TestSecretStoreHDWallets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary data directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, tt := range []struct {
+		backend string
+		want    int
+	}{
+		{"", 1},
+		{"file", 1},
+		{"db", 0},
+	} {
+		config := &Config{DataDir: dir, KeyStoreBackend: tt.backend, KeyStoreToken: "secret", UseLightweightKDF: true, NoUSB: true}
+		am, _, closer, err := makeAccountManager(config)
+		if err != nil {
+			t.Fatalf("backend %q: failed to create account manager: %v", tt.backend, err)
+		}
+		if have := len(am.Backends(keystore.HDKeyStoreType)); have != tt.want {
+			t.Errorf("backend %q: HD keystore count mismatch: have %d, want %d", tt.backend, have, tt.want)
+		}
+		am.Close()
+		if closer != nil {
+			closer.Close()
+		}
+	}
+}
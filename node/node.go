@@ -19,6 +19,7 @@ package node
 import (
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"path/filepath"
@@ -43,6 +44,7 @@ type Node struct {
 	accman   *accounts.Manager
 
 	ephemeralKeystore string         // if non-empty, the key directory that will be removed by Stop
+	keystoreSecrets   io.Closer      // if non-nil, the key secret store that will be closed by Stop
 	instanceDirLock   flock.Releaser // prevents concurrent use of instance directory
 
 	serverConfig p2p.Config
@@ -99,7 +101,7 @@ func New(conf *Config) (*Node, error) {
 	}
 	// Ensure that the AccountManager method works before the node has started.
 	// We rely on this in cmd/geth.
-	am, ephemeralKeystore, err := makeAccountManager(conf)
+	am, ephemeralKeystore, keystoreSecrets, err := makeAccountManager(conf)
 	if err != nil {
 		return nil, err
 	}
@@ -111,6 +113,7 @@ func New(conf *Config) (*Node, error) {
 	return &Node{
 		accman:            am,
 		ephemeralKeystore: ephemeralKeystore,
+		keystoreSecrets:   keystoreSecrets,
 		config:            conf,
 		serviceFuncs:      []ServiceConstructor{},
 		ipcEndpoint:       conf.IPCEndpoint(),
@@ -516,6 +519,12 @@ func (n *Node) Stop() error {
 	if n.ephemeralKeystore != "" {
 		keystoreErr = os.RemoveAll(n.ephemeralKeystore)
 	}
+	// Close the key secret store if it was opened from the config.
+	if n.keystoreSecrets != nil {
+		if err := n.keystoreSecrets.Close(); err != nil && keystoreErr == nil {
+			keystoreErr = err
+		}
+	}
 
 	if len(failure.Services) > 0 {
 		return failure
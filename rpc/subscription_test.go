@@ -105,6 +105,22 @@ func (s *NotificationTestService) HangSubscription(ctx context.Context, val int)
 	return subscription, nil
 }
 
+// EagerSubscription sends all its notifications before returning, so before the
+// subscription is activated.
+func (s *NotificationTestService) EagerSubscription(ctx context.Context, n, val int) (*Subscription, error) {
+	notifier, supported := NotifierFromContext(ctx)
+	if !supported {
+		return nil, ErrNotificationsUnsupported
+	}
+	subscription := notifier.CreateSubscription()
+	for i := 0; i < n; i++ {
+		if err := notifier.Notify(subscription.ID, val+i); err != nil {
+			return nil, err
+		}
+	}
+	return subscription, nil
+}
+
 func TestNotifications(t *testing.T) {
 	server := NewServer()
 	service := &NotificationTestService{}
@@ -328,3 +344,36 @@ func TestSubscriptionMultipleNamespaces(t *testing.T) {
 		}
 	}
 }
+
+// Tests that notifications sent before the subscription is activated are delivered
+// in order once the subscription ID reached the client.
+func TestNotificationsBeforeActivation(t *testing.T) {
+	server := NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("eth", new(NotificationTestService)); err != nil {
+		t.Fatalf("unable to register test service %v", err)
+	}
+	client := DialInProc(server)
+	defer client.Close()
+
+	n, val := 5, 12345
+	ch := make(chan int, n)
+	sub, err := client.Subscribe(context.Background(), "eth", ch, "eagerSubscription", n, val)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	for i := 0; i < n; i++ {
+		select {
+		case have := <-ch:
+			if have != val+i {
+				t.Fatalf("notification %d: have %d, want %d", i, have, val+i)
+			}
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		case <-time.After(time.Second):
+			t.Fatalf("notification %d: timeout", i)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package core implements an external signer, holding the accounts outside of
+// the node and signing requests only after they were approved through a UI.
+package core
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// ExternalAPIVersion is the version of the external API, following semantic
+// versioning: the major version is bumped on backwards incompatible changes.
+const ExternalAPIVersion = "1.0.0"
+
+// ErrRequestDenied is returned if the user denied a request through the UI.
+var ErrRequestDenied = errors.New("request denied")
+
+// ExternalAPI defines the external API through which signing requests are made.
+type ExternalAPI interface {
+	// List available accounts
+	List(ctx context.Context) ([]common.Address, error)
+	// SignTransaction request to sign the specified transaction
+	SignTransaction(ctx context.Context, args SendTxArgs) (*ethapi.SignTransactionResult, error)
+	// Sign request to sign the specified data
+	Sign(ctx context.Context, addr common.Address, data hexutil.Bytes) (hexutil.Bytes, error)
+	// Version info about the APIs
+	Version(ctx context.Context) (string, error)
+}
+
+// SignerUI specifies what method a UI needs to implement to be able to be used
+// as a UI for the signer. The UI may be an interactive user or a set of rules
+// deciding on behalf of one.
+type SignerUI interface {
+	// ApproveTx prompt the user for confirmation to request to sign Transaction
+	ApproveTx(request *SignTxRequest) (SignTxResponse, error)
+	// ApproveSignData prompt the user for confirmation to request to sign data
+	ApproveSignData(request *SignDataRequest) (SignDataResponse, error)
+	// ApproveListing prompt the user for confirmation to list accounts
+	// the list of accounts to list can be modified by the UI
+	ApproveListing(request *ListRequest) (ListResponse, error)
+	// ShowError displays error message to user
+	ShowError(message string)
+	// ShowInfo displays info message to user
+	ShowInfo(message string)
+	// OnApprovedTx notifies the UI about a transaction having been successfully signed.
+	// This method can be used by a UI to keep track of e.g. how much has been sent to a particular recipient.
+	OnApprovedTx(tx ethapi.SignTransactionResult)
+	// OnSignerStartup is invoked when the signer boots, and tells the UI info about external API location and version
+	// information
+	OnSignerStartup(info StartupInfo)
+}
+
+// SignFailureUI is an optional interface for SignerUIs keeping track of the
+// approved transactions, e.g. to enforce spending limits.
+type SignFailureUI interface {
+	// OnSignFailed notifies the UI about an approved transaction which could not
+	// be signed after all, e.g. due to a wrong password.
+	OnSignFailed(tx SendTxArgs)
+}
+
+type (
+	// SignTxRequest contains info about a Transaction to sign
+	SignTxRequest struct {
+		Transaction SendTxArgs `json:"transaction"`
+	}
+	// SignTxResponse result from SignTxRequest. The transaction may have been
+	// modified by the UI before approval.
+	SignTxResponse struct {
+		Transaction SendTxArgs `json:"transaction"`
+		Approved    bool       `json:"approved"`
+		Password    string     `json:"password"`
+	}
+	// SignDataRequest contains info about some data to sign
+	SignDataRequest struct {
+		Address common.Address `json:"address"`
+		Rawdata hexutil.Bytes  `json:"raw_data"`
+		Message string         `json:"message"`
+		Hash    hexutil.Bytes  `json:"hash"`
+	}
+	// SignDataResponse result from SignDataRequest
+	SignDataResponse struct {
+		Approved bool   `json:"approved"`
+		Password string `json:"password"`
+	}
+	// ListRequest contains the accounts a listing is requested for
+	ListRequest struct {
+		Accounts []accounts.Account `json:"accounts"`
+	}
+	// ListResponse is the subset of the accounts the UI approved to be listed
+	ListResponse struct {
+		Accounts []accounts.Account `json:"accounts"`
+	}
+	// StartupInfo describes the endpoints the signer is serving on
+	StartupInfo struct {
+		Info map[string]interface{} `json:"info"`
+	}
+)
+
+// SignerAPI defines the actual implementation of ExternalAPI
+type SignerAPI struct {
+	chainID *big.Int
+	am      *accounts.Manager
+	UI      SignerUI
+}
+
+// NewSignerAPI creates a new API that can be used for account management. The
+// transactions are signed for the given chain, using the wallets of the account
+// manager after approval through the UI.
+func NewSignerAPI(am *accounts.Manager, chainID *big.Int, ui SignerUI) *SignerAPI {
+	return &SignerAPI{
+		chainID: new(big.Int).Set(chainID),
+		am:      am,
+		UI:      ui,
+	}
+}
+
+// List returns the set of wallet this signer manages. Each wallet can contain
+// multiple accounts.
+func (api *SignerAPI) List(ctx context.Context) ([]common.Address, error) {
+	accs := make([]accounts.Account, 0) // Non-nil, as nil denotes a denied listing
+	for _, wallet := range api.am.Wallets() {
+		accs = append(accs, wallet.Accounts()...)
+	}
+	result, err := api.UI.ApproveListing(&ListRequest{Accounts: accs})
+	if err != nil {
+		return nil, err
+	}
+	if result.Accounts == nil {
+		return nil, ErrRequestDenied
+	}
+	addresses := make([]common.Address, 0, len(result.Accounts))
+	for _, acc := range result.Accounts {
+		addresses = append(addresses, acc.Address)
+	}
+	return addresses, nil
+}
+
+// SignTransaction signs the given transaction and returns it both as json and
+// rlp-encoded form, if the UI approves it.
+func (api *SignerAPI) SignTransaction(ctx context.Context, args SendTxArgs) (_ *ethapi.SignTransactionResult, err error) {
+	if err := args.validate(); err != nil {
+		return nil, err
+	}
+	result, err := api.UI.ApproveTx(&SignTxRequest{Transaction: args})
+	if err != nil {
+		return nil, err
+	}
+	if !result.Approved {
+		return nil, ErrRequestDenied
+	}
+	// Let the UI know if the approved transaction ends up not being signed
+	defer func() {
+		if ui, ok := api.UI.(SignFailureUI); ok && err != nil {
+			ui.OnSignFailed(result.Transaction)
+		}
+	}()
+	// The UI might have modified the transaction, validate it again
+	if err := result.Transaction.validate(); err != nil {
+		return nil, err
+	}
+	account := accounts.Account{Address: result.Transaction.From}
+	wallet, err := api.am.Find(account)
+	if err != nil {
+		return nil, err
+	}
+	signed, err := wallet.SignTxWithPassphrase(account, result.Password, result.Transaction.toTransaction(), api.chainID)
+	if err != nil {
+		api.UI.ShowError(err.Error())
+		return nil, err
+	}
+	raw, err := rlp.EncodeToBytes(signed)
+	if err != nil {
+		return nil, err
+	}
+	response := ethapi.SignTransactionResult{Raw: raw, Tx: signed}
+
+	log.Info("Signed transaction", "from", account.Address, "hash", signed.Hash())
+	api.UI.OnApprovedTx(response)
+	return &response, nil
+}
+
+// Sign calculates an Ethereum ECDSA signature for:
+// keccak256("\x19Ethereum Signed Message:\n" + len(message) + message)
+//
+// The signature conforms to the secp256k1 curve R, S and V values, where the
+// V value is 27 or 28 for legacy reasons, if the UI approves it.
+func (api *SignerAPI) Sign(ctx context.Context, addr common.Address, data hexutil.Bytes) (hexutil.Bytes, error) {
+	sighash, msg := SignHash(data)
+
+	result, err := api.UI.ApproveSignData(&SignDataRequest{Address: addr, Rawdata: data, Message: msg, Hash: sighash})
+	if err != nil {
+		return nil, err
+	}
+	if !result.Approved {
+		return nil, ErrRequestDenied
+	}
+	account := accounts.Account{Address: addr}
+	wallet, err := api.am.Find(account)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := wallet.SignHashWithPassphrase(account, result.Password, sighash)
+	if err != nil {
+		api.UI.ShowError(err.Error())
+		return nil, err
+	}
+	signature[64] += 27 // Transform V from 0/1 to 27/28 according to the yellow paper
+
+	log.Info("Signed data", "address", addr)
+	return signature, nil
+}
+
+// Version returns the version of the external API.
+func (api *SignerAPI) Version(ctx context.Context) (string, error) {
+	return ExternalAPIVersion, nil
+}
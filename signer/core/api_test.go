@@ -0,0 +1,175 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"context"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+)
+
+// scriptedUI is a SignerUI answering the requests with preset responses.
+type scriptedUI struct {
+	approve  bool
+	password string
+	signed   []ethapi.SignTransactionResult
+	failed   []SendTxArgs
+}
+
+func (ui *scriptedUI) ApproveTx(request *SignTxRequest) (SignTxResponse, error) {
+	return SignTxResponse{Transaction: request.Transaction, Approved: ui.approve, Password: ui.password}, nil
+}
+
+func (ui *scriptedUI) ApproveSignData(request *SignDataRequest) (SignDataResponse, error) {
+	return SignDataResponse{Approved: ui.approve, Password: ui.password}, nil
+}
+
+func (ui *scriptedUI) ApproveListing(request *ListRequest) (ListResponse, error) {
+	if !ui.approve {
+		return ListResponse{}, nil
+	}
+	return ListResponse{Accounts: request.Accounts}, nil
+}
+
+func (ui *scriptedUI) ShowError(message string)         {}
+func (ui *scriptedUI) ShowInfo(message string)          {}
+func (ui *scriptedUI) OnSignerStartup(info StartupInfo) {}
+func (ui *scriptedUI) OnApprovedTx(tx ethapi.SignTransactionResult) {
+	ui.signed = append(ui.signed, tx)
+}
+func (ui *scriptedUI) OnSignFailed(tx SendTxArgs) {
+	ui.failed = append(ui.failed, tx)
+}
+
+// newTestSigner creates a signer with a single account in a temporary keystore.
+func newTestSigner(t *testing.T) (*SignerAPI, *scriptedUI, common.Address, func()) {
+	dir, err := ioutil.TempDir("", "signer-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	ks := keystore.NewKeyStore(dir, keystore.LightScryptN, keystore.LightScryptP)
+	account, err := ks.NewAccount("secret")
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("failed to create account: %v", err)
+	}
+	ui := &scriptedUI{approve: true, password: "secret"}
+	api := NewSignerAPI(accounts.NewManager(ks), big.NewInt(1337), ui)
+
+	return api, ui, account.Address, func() { os.RemoveAll(dir) }
+}
+
+func TestSignerList(t *testing.T) {
+	api, ui, addr, cleanup := newTestSigner(t)
+	defer cleanup()
+
+	list, err := api.List(context.Background())
+	if err != nil {
+		t.Fatalf("failed to list accounts: %v", err)
+	}
+	if len(list) != 1 || list[0] != addr {
+		t.Fatalf("account list mismatch: have %v, want [%x]", list, addr)
+	}
+	ui.approve = false
+	if _, err := api.List(context.Background()); err != ErrRequestDenied {
+		t.Fatalf("error mismatch for denied listing: have %v, want %v", err, ErrRequestDenied)
+	}
+}
+
+func TestSignerSignTransaction(t *testing.T) {
+	api, ui, addr, cleanup := newTestSigner(t)
+	defer cleanup()
+
+	to := common.HexToAddress("0x01")
+	args := SendTxArgs{
+		From:     addr,
+		To:       &to,
+		Gas:      21000,
+		GasPrice: hexutil.Big(*big.NewInt(1)),
+		Value:    hexutil.Big(*big.NewInt(100)),
+		Nonce:    3,
+	}
+	result, err := api.SignTransaction(context.Background(), args)
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	sender, err := types.Sender(types.NewEIP155Signer(big.NewInt(1337)), result.Tx)
+	if err != nil {
+		t.Fatalf("failed to recover sender: %v", err)
+	}
+	if sender != addr {
+		t.Errorf("sender mismatch: have %x, want %x", sender, addr)
+	}
+	if result.Tx.Nonce() != 3 || result.Tx.Value().Cmp(big.NewInt(100)) != 0 {
+		t.Errorf("transaction mismatch: nonce %d, value %v", result.Tx.Nonce(), result.Tx.Value())
+	}
+	if len(ui.signed) != 1 {
+		t.Errorf("approved transactions mismatch: have %d, want 1", len(ui.signed))
+	}
+	// Ensure wrong passwords and denials are both rejected, only the former being
+	// reported as a signing failure
+	ui.password = "wrong"
+	if _, err := api.SignTransaction(context.Background(), args); err == nil {
+		t.Errorf("transaction signed with wrong password")
+	}
+	ui.approve = false
+	if _, err := api.SignTransaction(context.Background(), args); err != ErrRequestDenied {
+		t.Errorf("error mismatch for denied transaction: have %v, want %v", err, ErrRequestDenied)
+	}
+	if len(ui.failed) != 1 || ui.failed[0].Nonce != args.Nonce {
+		t.Errorf("signing failures mismatch: have %v, want 1", ui.failed)
+	}
+	// Ensure invalid transactions never reach the UI
+	args.Gas = 0
+	if _, err := api.SignTransaction(context.Background(), args); err == nil || err == ErrRequestDenied {
+		t.Errorf("error mismatch for invalid transaction: have %v", err)
+	}
+}
+
+func TestSignerSign(t *testing.T) {
+	api, _, addr, cleanup := newTestSigner(t)
+	defer cleanup()
+
+	data := hexutil.Bytes("hello world")
+	signature, err := api.Sign(context.Background(), addr, data)
+	if err != nil {
+		t.Fatalf("failed to sign data: %v", err)
+	}
+	if signature[64] != 27 && signature[64] != 28 {
+		t.Fatalf("invalid V value: %d", signature[64])
+	}
+	signature[64] -= 27
+
+	hash, _ := SignHash(data)
+	pubkey, err := crypto.SigToPub(hash, signature)
+	if err != nil {
+		t.Fatalf("failed to recover public key: %v", err)
+	}
+	if recovered := crypto.PubkeyToAddress(*pubkey); recovered != addr {
+		t.Fatalf("signer mismatch: have %x, want %x", recovered, addr)
+	}
+}
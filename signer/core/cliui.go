@@ -0,0 +1,149 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/console"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+)
+
+// CommandlineUI is a SignerUI prompting the user on the terminal the signer
+// was started from.
+type CommandlineUI struct {
+	mu sync.Mutex // Serializes the prompts of concurrent requests
+}
+
+// NewCommandlineUI creates a UI prompting the user on the terminal.
+func NewCommandlineUI() *CommandlineUI {
+	return &CommandlineUI{}
+}
+
+// confirm asks the user to approve a request, returning whether the answer
+// was affirmative.
+func (ui *CommandlineUI) confirm() bool {
+	answer, err := console.Stdin.PromptInput("Approve? [y/N]: ")
+	if err != nil {
+		return false
+	}
+	return strings.ToLower(strings.TrimSpace(answer)) == "y"
+}
+
+// password asks the user for the password of the account to sign with.
+func (ui *CommandlineUI) password() string {
+	password, err := console.Stdin.PromptPassword("Account password: ")
+	if err != nil {
+		return ""
+	}
+	return password
+}
+
+// ApproveTx prompt the user for confirmation to request to sign Transaction
+func (ui *CommandlineUI) ApproveTx(request *SignTxRequest) (SignTxResponse, error) {
+	ui.mu.Lock()
+	defer ui.mu.Unlock()
+
+	tx := request.Transaction
+	fmt.Printf("-------- Transaction request -------------\n")
+	if tx.To != nil {
+		fmt.Printf("to:    %v\n", tx.To.Hex())
+	} else {
+		fmt.Printf("to:    <contract creation>\n")
+	}
+	fmt.Printf("from:  %v\n", tx.From.Hex())
+	fmt.Printf("value: %v wei\n", tx.Value.ToInt())
+	fmt.Printf("gas:   %v (%v)\n", tx.Gas, uint64(tx.Gas))
+	fmt.Printf("gasprice: %v wei\n", tx.GasPrice.ToInt())
+	fmt.Printf("nonce: %v (%v)\n", tx.Nonce, uint64(tx.Nonce))
+	if data := tx.data(); len(data) > 0 {
+		fmt.Printf("data:  %#x\n", data)
+	}
+	fmt.Printf("-------------------------------------------\n")
+
+	if !ui.confirm() {
+		return SignTxResponse{Transaction: tx, Approved: false}, nil
+	}
+	return SignTxResponse{Transaction: tx, Approved: true, Password: ui.password()}, nil
+}
+
+// ApproveSignData prompt the user for confirmation to request to sign data
+func (ui *CommandlineUI) ApproveSignData(request *SignDataRequest) (SignDataResponse, error) {
+	ui.mu.Lock()
+	defer ui.mu.Unlock()
+
+	fmt.Printf("-------- Sign data request --------------\n")
+	fmt.Printf("account:  %s\n", request.Address.Hex())
+	fmt.Printf("message:  %q\n", request.Message)
+	fmt.Printf("raw data: %v\n", request.Rawdata)
+	fmt.Printf("hash:     %v\n", request.Hash)
+	fmt.Printf("-------------------------------------------\n")
+
+	if !ui.confirm() {
+		return SignDataResponse{Approved: false}, nil
+	}
+	return SignDataResponse{Approved: true, Password: ui.password()}, nil
+}
+
+// ApproveListing prompt the user for confirmation to list accounts
+// the list of accounts to list can be modified by the UI
+func (ui *CommandlineUI) ApproveListing(request *ListRequest) (ListResponse, error) {
+	ui.mu.Lock()
+	defer ui.mu.Unlock()
+
+	fmt.Printf("-------- List account request --------------\n")
+	fmt.Printf("A request has been made to list all accounts. \n")
+	fmt.Printf("You can select which accounts the caller can see\n")
+	for _, account := range request.Accounts {
+		fmt.Printf("  [x] %v\n", account.Address.Hex())
+		fmt.Printf("    URL: %v\n", account.URL)
+	}
+	fmt.Printf("-------------------------------------------\n")
+
+	if !ui.confirm() {
+		return ListResponse{}, nil
+	}
+	return ListResponse{Accounts: request.Accounts}, nil
+}
+
+// ShowError displays error message to user
+func (ui *CommandlineUI) ShowError(message string) {
+	fmt.Printf("ERROR: %v\n", message)
+}
+
+// ShowInfo displays info message to user
+func (ui *CommandlineUI) ShowInfo(message string) {
+	fmt.Printf("Info: %v\n", message)
+}
+
+// OnApprovedTx notifies the UI about a transaction having been successfully signed.
+func (ui *CommandlineUI) OnApprovedTx(tx ethapi.SignTransactionResult) {
+	fmt.Printf("Transaction signed:\n ")
+	spewed, _ := tx.Tx.MarshalJSON()
+	fmt.Printf("%s\n", spewed)
+}
+
+// OnSignerStartup is invoked when the signer boots, and tells the UI info about
+// external API location and version information
+func (ui *CommandlineUI) OnSignerStartup(info StartupInfo) {
+	fmt.Printf("------- Signer info -------\n")
+	for k, v := range info.Info {
+		fmt.Printf("* %v : %v\n", k, v)
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package rules implements a policy layer for the external signer, rejecting
+// the requests that violate a set of rules before they reach the user.
+package rules
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/signer/core"
+)
+
+var (
+	// ErrValueLimit is returned if the value of a transaction exceeds the per
+	// transaction limit.
+	ErrValueLimit = errors.New("transaction value exceeds limit")
+
+	// ErrDailyLimit is returned if the value of a transaction would exceed the
+	// total value permitted to be signed within a day.
+	ErrDailyLimit = errors.New("transaction value exceeds daily limit")
+
+	// ErrDestinationNotAllowed is returned if the recipient of a transaction is
+	// not in the destination allowlist.
+	ErrDestinationNotAllowed = errors.New("destination not allowed")
+
+	// ErrContractCreation is returned for contract creations if only transfers to
+	// allowed destinations are permitted.
+	ErrContractCreation = errors.New("contract creation not allowed")
+)
+
+// dailyWindow is the time window the daily value limit is enforced over.
+const dailyWindow = 24 * time.Hour
+
+// Config is the set of rules loaded from a rule file.
+type Config struct {
+	// MaxValue is the maximum value in wei of a single transaction.
+	MaxValue *math.HexOrDecimal256 `json:"maxValue,omitempty"`
+
+	// DailyLimit is the maximum total value in wei of the transactions signed
+	// within the last 24 hours.
+	DailyLimit *math.HexOrDecimal256 `json:"dailyLimit,omitempty"`
+
+	// AllowedDestinations is the list of permitted recipients. If empty, all
+	// recipients are permitted.
+	AllowedDestinations []common.Address `json:"allowedDestinations,omitempty"`
+
+	// AllowContractCreation permits contract creations, even if the allowed
+	// destinations are restricted.
+	AllowContractCreation bool `json:"allowContractCreation,omitempty"`
+}
+
+// LoadConfig reads the rules from a JSON rule file.
+func LoadConfig(path string) (*Config, error) {
+	blob, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := new(Config)
+	if err := json.Unmarshal(blob, config); err != nil {
+		return nil, fmt.Errorf("invalid rule file %s: %v", path, err)
+	}
+	return config, nil
+}
+
+// spending is the value of a signed transaction, tracked for the daily limit.
+type spending struct {
+	time  time.Time
+	value *big.Int
+}
+
+// RulesetUI is a SignerUI enforcing a set of rules on the requests, rejecting
+// the violating ones and forwarding the rest to the next UI for approval.
+type RulesetUI struct {
+	next   core.SignerUI
+	config *Config
+	allow  map[common.Address]struct{}
+
+	lock  sync.Mutex
+	spent []spending       // Values signed within the daily window, oldest first
+	now   func() time.Time // Time source, replaceable for testing
+}
+
+// NewRulesetUI creates a UI enforcing the given rules before asking the next UI.
+func NewRulesetUI(next core.SignerUI, config *Config) *RulesetUI {
+	ui := &RulesetUI{
+		next:   next,
+		config: config,
+		now:    time.Now,
+	}
+	if len(config.AllowedDestinations) > 0 {
+		ui.allow = make(map[common.Address]struct{})
+		for _, addr := range config.AllowedDestinations {
+			ui.allow[addr] = struct{}{}
+		}
+	}
+	return ui
+}
+
+// check verifies that a transaction conforms to the rules, reserving its value
+// against the daily limit in the same critical section if it does. Reservations
+// of transactions not ending up signed must be released.
+func (ui *RulesetUI) check(tx *core.SendTxArgs) error {
+	if ui.allow != nil {
+		if tx.To == nil {
+			if !ui.config.AllowContractCreation {
+				return ErrContractCreation
+			}
+		} else if _, ok := ui.allow[*tx.To]; !ok {
+			return ErrDestinationNotAllowed
+		}
+	}
+	value := tx.Value.ToInt()
+	if ui.config.MaxValue != nil && value.Cmp((*big.Int)(ui.config.MaxValue)) > 0 {
+		return ErrValueLimit
+	}
+	if ui.config.DailyLimit != nil {
+		ui.lock.Lock()
+		defer ui.lock.Unlock()
+
+		total := new(big.Int).Set(value)
+		for _, s := range ui.expire() {
+			total.Add(total, s.value)
+		}
+		if total.Cmp((*big.Int)(ui.config.DailyLimit)) > 0 {
+			return ErrDailyLimit
+		}
+		ui.spent = append(ui.spent, spending{time: ui.now(), value: new(big.Int).Set(value)})
+	}
+	return nil
+}
+
+// release drops the most recent reservation of the value of a transaction which
+// was not signed.
+func (ui *RulesetUI) release(tx *core.SendTxArgs) {
+	if ui.config.DailyLimit == nil {
+		return
+	}
+	ui.lock.Lock()
+	defer ui.lock.Unlock()
+
+	value := tx.Value.ToInt()
+	for i := len(ui.spent) - 1; i >= 0; i-- {
+		if ui.spent[i].value.Cmp(value) == 0 {
+			ui.spent = append(ui.spent[:i], ui.spent[i+1:]...)
+			return
+		}
+	}
+}
+
+// expire drops the spendings older than the daily window, returning the rest.
+// The lock must be held by the caller.
+func (ui *RulesetUI) expire() []spending {
+	cutoff := ui.now().Add(-dailyWindow)
+	for len(ui.spent) > 0 && ui.spent[0].time.Before(cutoff) {
+		ui.spent = ui.spent[1:]
+	}
+	return ui.spent
+}
+
+// ApproveTx rejects the transactions violating the rules, asking the next UI to
+// approve the others. The value of a transaction is reserved against the daily
+// limit while it awaits approval, and released if it's denied. As the next UI
+// may modify the transaction, the approved version is checked too.
+func (ui *RulesetUI) ApproveTx(request *core.SignTxRequest) (core.SignTxResponse, error) {
+	if err := ui.check(&request.Transaction); err != nil {
+		log.Warn("Transaction rejected by rules", "from", request.Transaction.From, "err", err)
+		return core.SignTxResponse{Transaction: request.Transaction, Approved: false}, err
+	}
+	response, err := ui.next.ApproveTx(request)
+	ui.release(&request.Transaction)
+	if err != nil || !response.Approved {
+		return response, err
+	}
+	if err := ui.check(&response.Transaction); err != nil {
+		log.Warn("Modified transaction rejected by rules", "from", response.Transaction.From, "err", err)
+		return core.SignTxResponse{Transaction: response.Transaction, Approved: false}, err
+	}
+	return response, nil
+}
+
+// ApproveSignData forwards the request to the next UI.
+func (ui *RulesetUI) ApproveSignData(request *core.SignDataRequest) (core.SignDataResponse, error) {
+	return ui.next.ApproveSignData(request)
+}
+
+// ApproveListing forwards the request to the next UI.
+func (ui *RulesetUI) ApproveListing(request *core.ListRequest) (core.ListResponse, error) {
+	return ui.next.ApproveListing(request)
+}
+
+// ShowError forwards the message to the next UI.
+func (ui *RulesetUI) ShowError(message string) {
+	ui.next.ShowError(message)
+}
+
+// ShowInfo forwards the message to the next UI.
+func (ui *RulesetUI) ShowInfo(message string) {
+	ui.next.ShowInfo(message)
+}
+
+// OnApprovedTx forwards the signed transaction to the next UI. Its value has
+// already been accounted against the daily limit on approval.
+func (ui *RulesetUI) OnApprovedTx(tx ethapi.SignTransactionResult) {
+	ui.next.OnApprovedTx(tx)
+}
+
+// OnSignFailed releases the value of an approved transaction which could not be
+// signed, notifying the next UI too if it tracks failures.
+func (ui *RulesetUI) OnSignFailed(tx core.SendTxArgs) {
+	ui.release(&tx)
+	if next, ok := ui.next.(core.SignFailureUI); ok {
+		next.OnSignFailed(tx)
+	}
+}
+
+// OnSignerStartup forwards the startup info to the next UI.
+func (ui *RulesetUI) OnSignerStartup(info core.StartupInfo) {
+	ui.next.OnSignerStartup(info)
+}
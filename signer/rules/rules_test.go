@@ -0,0 +1,208 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rules
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/signer/core"
+)
+
+// approvingUI is a SignerUI approving every request, counting the prompts.
+type approvingUI struct {
+	prompts int
+}
+
+func (ui *approvingUI) ApproveTx(request *core.SignTxRequest) (core.SignTxResponse, error) {
+	ui.prompts++
+	return core.SignTxResponse{Transaction: request.Transaction, Approved: true}, nil
+}
+
+func (ui *approvingUI) ApproveSignData(request *core.SignDataRequest) (core.SignDataResponse, error) {
+	ui.prompts++
+	return core.SignDataResponse{Approved: true}, nil
+}
+
+func (ui *approvingUI) ApproveListing(request *core.ListRequest) (core.ListResponse, error) {
+	ui.prompts++
+	return core.ListResponse{Accounts: request.Accounts}, nil
+}
+
+func (ui *approvingUI) ShowError(message string)                     {}
+func (ui *approvingUI) ShowInfo(message string)                      {}
+func (ui *approvingUI) OnApprovedTx(tx ethapi.SignTransactionResult) {}
+func (ui *approvingUI) OnSignerStartup(info core.StartupInfo)        {}
+
+// makeRequest creates a transaction request with the given recipient and value.
+func makeRequest(to *common.Address, value int64) *core.SignTxRequest {
+	data := hexutil.Bytes{0x01}
+	return &core.SignTxRequest{
+		Transaction: core.SendTxArgs{
+			To:    to,
+			Gas:   21000,
+			Value: hexutil.Big(*big.NewInt(value)),
+			Data:  &data,
+		},
+	}
+}
+
+// sign simulates the signing of an approved request, notifying the UI.
+func sign(ui *RulesetUI, request *core.SignTxRequest) {
+	value := request.Transaction.Value.ToInt()
+	tx := types.NewTransaction(0, common.Address{}, value, 21000, new(big.Int), nil)
+	ui.OnApprovedTx(ethapi.SignTransactionResult{Tx: tx})
+}
+
+func TestConfigParsing(t *testing.T) {
+	var config Config
+	blob := `{"maxValue": "0x64", "dailyLimit": "1000", "allowedDestinations": ["0x0000000000000000000000000000000000000001"]}`
+	if err := json.Unmarshal([]byte(blob), &config); err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+	if v := (*big.Int)(config.MaxValue); v.Cmp(big.NewInt(100)) != 0 {
+		t.Errorf("max value mismatch: have %v, want 100", v)
+	}
+	if v := (*big.Int)(config.DailyLimit); v.Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("daily limit mismatch: have %v, want 1000", v)
+	}
+	if len(config.AllowedDestinations) != 1 || config.AllowedDestinations[0] != common.BigToAddress(big.NewInt(1)) {
+		t.Errorf("allowed destinations mismatch: have %v", config.AllowedDestinations)
+	}
+}
+
+func TestDestinationRules(t *testing.T) {
+	allowed, other := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+
+	next := new(approvingUI)
+	ui := NewRulesetUI(next, &Config{AllowedDestinations: []common.Address{allowed}})
+
+	if _, err := ui.ApproveTx(makeRequest(&allowed, 1)); err != nil {
+		t.Errorf("allowed destination rejected: %v", err)
+	}
+	if _, err := ui.ApproveTx(makeRequest(&other, 1)); err != ErrDestinationNotAllowed {
+		t.Errorf("error mismatch for disallowed destination: have %v, want %v", err, ErrDestinationNotAllowed)
+	}
+	if _, err := ui.ApproveTx(makeRequest(nil, 1)); err != ErrContractCreation {
+		t.Errorf("error mismatch for contract creation: have %v, want %v", err, ErrContractCreation)
+	}
+	if next.prompts != 1 {
+		t.Errorf("rejected requests reached the next UI: have %d prompts, want 1", next.prompts)
+	}
+	ui.config.AllowContractCreation = true
+	if _, err := ui.ApproveTx(makeRequest(nil, 1)); err != nil {
+		t.Errorf("allowed contract creation rejected: %v", err)
+	}
+}
+
+func TestValueRules(t *testing.T) {
+	to := common.HexToAddress("0x01")
+	maxValue, dailyLimit := (*math.HexOrDecimal256)(big.NewInt(100)), (*math.HexOrDecimal256)(big.NewInt(250))
+
+	now := time.Unix(1000000, 0)
+	ui := NewRulesetUI(new(approvingUI), &Config{MaxValue: maxValue, DailyLimit: dailyLimit})
+	ui.now = func() time.Time { return now }
+
+	if _, err := ui.ApproveTx(makeRequest(&to, 101)); err != ErrValueLimit {
+		t.Fatalf("error mismatch for value over limit: have %v, want %v", err, ErrValueLimit)
+	}
+	// Sign two transactions, reaching close to the daily limit
+	for i := 0; i < 2; i++ {
+		request := makeRequest(&to, 100)
+		if _, err := ui.ApproveTx(request); err != nil {
+			t.Fatalf("transaction %d rejected: %v", i, err)
+		}
+		sign(ui, request)
+		now = now.Add(time.Hour)
+	}
+	if _, err := ui.ApproveTx(makeRequest(&to, 51)); err != ErrDailyLimit {
+		t.Fatalf("error mismatch for value over daily limit: have %v, want %v", err, ErrDailyLimit)
+	}
+	if _, err := ui.ApproveTx(makeRequest(&to, 50)); err != nil {
+		t.Fatalf("transaction within daily limit rejected: %v", err)
+	}
+	// Move past the daily window of the first transaction, freeing up its value
+	now = now.Add(23 * time.Hour)
+	if _, err := ui.ApproveTx(makeRequest(&to, 100)); err != nil {
+		t.Fatalf("transaction after expiry rejected: %v", err)
+	}
+}
+
+// gatedUI is a SignerUI holding transaction requests until a verdict is given.
+type gatedUI struct {
+	approvingUI
+	entered chan struct{} // Signalled when a request awaits its verdict
+	verdict chan bool     // Approval decision for the awaiting requests
+}
+
+func (ui *gatedUI) ApproveTx(request *core.SignTxRequest) (core.SignTxResponse, error) {
+	ui.entered <- struct{}{}
+	return core.SignTxResponse{Transaction: request.Transaction, Approved: <-ui.verdict}, nil
+}
+
+// Tests that the value of a transaction is reserved against the daily limit while
+// it awaits approval, and released if it's denied or fails to be signed.
+func TestDailyLimitReservation(t *testing.T) {
+	to := common.HexToAddress("0x01")
+	next := &gatedUI{entered: make(chan struct{}, 4), verdict: make(chan bool, 4)}
+	ui := NewRulesetUI(next, &Config{DailyLimit: (*math.HexOrDecimal256)(big.NewInt(250))})
+
+	// Hold a request awaiting approval, concurrent ones must not exceed the limit
+	errc := make(chan error)
+	go func() {
+		_, err := ui.ApproveTx(makeRequest(&to, 200))
+		errc <- err
+	}()
+	<-next.entered
+	if _, err := ui.ApproveTx(makeRequest(&to, 100)); err != ErrDailyLimit {
+		t.Fatalf("error mismatch for concurrent request: have %v, want %v", err, ErrDailyLimit)
+	}
+	// Deny the held request, freeing up its value
+	next.verdict <- false
+	if err := <-errc; err != nil {
+		t.Fatalf("denied request failed: %v", err)
+	}
+	next.verdict <- true
+	if _, err := ui.ApproveTx(makeRequest(&to, 100)); err != nil {
+		t.Fatalf("request after denial rejected: %v", err)
+	}
+	<-next.entered
+
+	// Approve a request reaching the limit, then fail to sign it
+	request := makeRequest(&to, 150)
+	next.verdict <- true
+	if _, err := ui.ApproveTx(request); err != nil {
+		t.Fatalf("request within limit rejected: %v", err)
+	}
+	<-next.entered
+	if _, err := ui.ApproveTx(makeRequest(&to, 1)); err != ErrDailyLimit {
+		t.Fatalf("error mismatch for request over limit: have %v, want %v", err, ErrDailyLimit)
+	}
+	ui.OnSignFailed(request.Transaction)
+
+	next.verdict <- true
+	if _, err := ui.ApproveTx(makeRequest(&to, 150)); err != nil {
+		t.Fatalf("request after signing failure rejected: %v", err)
+	}
+}
@@ -23,8 +23,9 @@ import (
 )
 
 // MissingNodeError is returned by the trie functions (TryGet, TryUpdate, TryDelete)
-// in the case where a trie node is not present in the local database. It contains
-// information necessary for retrieving the missing node.
+// in the case where a trie node is not present in the local database, or is present
+// but cannot be decoded. It contains information necessary for retrieving the
+// missing node.
 type MissingNodeError struct {
 	NodeHash common.Hash // hash of the missing node
 	Path     []byte      // hex-encoded path to the missing node
@@ -200,7 +200,7 @@ func (it *nodeIterator) seek(prefix []byte) error {
 	key = key[:len(key)-1]
 	// Move forward until we're just before the closest match to key.
 	for {
-		state, parentIndex, path, err := it.peek(bytes.HasPrefix(key, it.path))
+		state, parentIndex, path, err := it.peekSeek(key)
 		if err == iteratorEnd {
 			return iteratorEnd
 		} else if err != nil {
@@ -249,6 +249,37 @@ func (it *nodeIterator) peek(descend bool) (*nodeIteratorState, *int, []byte, er
 	return nil, nil, nil, iteratorEnd
 }
 
+// peekSeek is like peek, but it avoids resolving the siblings which are located
+// entirely before the desired seek position. This way seeking never touches any
+// nodes outside of the path leading to the key.
+func (it *nodeIterator) peekSeek(key []byte) (*nodeIteratorState, *int, []byte, error) {
+	if len(it.stack) == 0 {
+		return it.peek(true)
+	}
+	if !bytes.HasPrefix(key, it.path) {
+		// If the current node doesn't lead to the key, pop it first
+		it.pop()
+	}
+	// Continue iteration to the next child leading towards the key
+	for len(it.stack) > 0 {
+		parent := it.stack[len(it.stack)-1]
+		ancestor := parent.hash
+		if (ancestor == common.Hash{}) {
+			ancestor = parent.parent
+		}
+		state, path, ok := it.nextChildAt(parent, ancestor, key)
+		if ok {
+			if err := state.resolve(it.trie, path); err != nil {
+				return parent, &parent.index, path, err
+			}
+			return state, &parent.index, path, nil
+		}
+		// No more child nodes, move back up.
+		it.pop()
+	}
+	return nil, nil, nil, iteratorEnd
+}
+
 func (st *nodeIteratorState) resolve(tr *Trie, path []byte) error {
 	if hash, ok := st.node.(hashNode); ok {
 		resolved, err := tr.resolveHash(hash, path)
@@ -299,6 +330,23 @@ func (it *nodeIterator) nextChild(parent *nodeIteratorState, ancestor common.Has
 	return parent, it.path, false
 }
 
+// nextChildAt is like nextChild, but it skips over the children of full nodes
+// which are located entirely before the given key.
+func (it *nodeIterator) nextChildAt(parent *nodeIteratorState, ancestor common.Hash, key []byte) (*nodeIteratorState, []byte, bool) {
+	if node, ok := parent.node.(*fullNode); ok {
+		for i := parent.index + 1; i < len(node.Children); i++ {
+			if node.Children[i] != nil {
+				path := append(it.path, byte(i))
+				if bytes.Compare(path, key) >= 0 || bytes.HasPrefix(key, path) {
+					break
+				}
+			}
+			parent.index = i
+		}
+	}
+	return it.nextChild(parent, ancestor)
+}
+
 func (it *nodeIterator) push(state *nodeIteratorState, parentIndex *int, path []byte) {
 	it.path = path
 	it.stack = append(it.stack, state)
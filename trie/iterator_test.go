@@ -421,6 +421,32 @@ func testIteratorContinueAfterSeekError(t *testing.T, memonly bool) {
 	}
 }
 
+// Tests that seeking past a missing node doesn't attempt to resolve it.
+func TestIteratorSeekPastMissingNode(t *testing.T) {
+	diskdb, _ := ethdb.NewMemDatabase()
+	triedb := NewDatabase(diskdb)
+
+	ctr, _ := New(common.Hash{}, triedb)
+	for _, val := range testdata1 {
+		ctr.Update([]byte(val.k), []byte(val.v))
+	}
+	root, _ := ctr.Commit(nil)
+	triedb.Commit(root, true)
+
+	// Remove the node containing "bars" and seek beyond it
+	barNodeHash := common.HexToHash("05041990364eb72fcb1127652ce40d8bab765f2bfe53225b1170d276cc101c2e")
+	diskdb.Delete(barNodeHash[:])
+
+	tr, _ := New(root, NewDatabase(diskdb))
+	it := tr.NodeIterator([]byte("fab"))
+	if err := it.Error(); err != nil {
+		t.Fatalf("unexpected seek error: %v", err)
+	}
+	if err := checkIteratorOrder(testdata1[4:], NewIterator(it)); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func checkIteratorNoDups(t *testing.T, it NodeIterator, seen map[string]bool) int {
 	if seen == nil {
 		seen = make(map[string]bool)
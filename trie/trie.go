@@ -438,7 +438,13 @@ func (t *Trie) resolveHash(n hashNode, prefix []byte) (node, error) {
 	if err != nil || enc == nil {
 		return nil, &MissingNodeError{NodeHash: hash, Path: prefix}
 	}
-	return mustDecodeNode(n, enc, t.cachegen), nil
+	// Corrupt database entries are unusable, treat them as missing so they
+	// can be detected and retrieved again instead of crashing.
+	node, err := decodeNode(n, enc, t.cachegen)
+	if err != nil {
+		return nil, &MissingNodeError{NodeHash: hash, Path: prefix}
+	}
+	return node, nil
 }
 
 // Root returns the root hash of the trie.